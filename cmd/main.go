@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
@@ -11,6 +12,7 @@ import (
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"syscall"
 	"time"
 )
@@ -25,8 +27,13 @@ Usage:
   jdextract generate --local <file>
   jdextract generate --batch <url> [<url>...]
   jdextract generate          (reads from stdin)
-  jdextract list
+  jdextract list [--missing <requirement>]
   jdextract status <prefix> <status>
+  jdextract revise <prefix> --target resume|cover --instructions <text>
+  jdextract prep <prefix>
+  jdextract check <prefix>
+  jdextract export <prefix> [--format docx|pdf] [--layout <preset>]
+  jdextract templates import <file> [--cover] [--name <variant>] [--yes]
   jdextract contacts <subcommand> [args]
   jdextract serve [--port <port>] [--open]
 
@@ -35,10 +42,44 @@ Subcommands:
   generate  Generate tailored resume and cover letter from a job description.
             Pass a URL (fetched via jina.ai), a local file path (--local),
             multiple URLs for concurrent batch processing (--batch),
-            or pipe raw text via stdin.
-  list      Print a table of processed job applications.
+            or pipe raw text via stdin. --pipeline runs the multi-pass
+            analyze, tailor, critique, revise flow. --template <name>
+            picks a base resume from config/templates/resumes/; "auto"
+            picks the one with the most keywords in common with the job.
+            With config/profile.json present it is used by default;
+            "default" forces resume.txt. --prompt-profile <name> uses
+            config/prompts/<name>.json instead of prompt.json; without it
+            the experiment mode in config.json may assign one. --language
+            overrides the detected posting language; the "languages" block
+            in config.json decides whether to translate or keep English.
+            --tone sets the cover letter tone (formal, startup-casual,
+            concise). Paragraphs from config/templates/cover_blocks/ whose
+            tags match the posting's sections are built into the letter.
+  list      Print a table of processed job applications. --missing keeps
+            jobs whose fit analysis lists that requirement as missing.
   status    Update the status of a job by directory prefix.
             Valid statuses: draft, applied, interviewing, offer, rejected
+  revise    Revise a job's resume or cover letter following instructions,
+            e.g. "shorten to one page". The previous version is kept in
+            the job's revisions/ folder.
+  prep      Write an interview prep packet (prep.md) for a job: likely
+            questions mapped to its requirements, STAR outlines, questions
+            to ask, and red flags to probe.
+  check     Check how an applicant tracking system will read a job's
+            resume, without an LLM: keyword coverage of the posting's
+            required and preferred terms, missing standard sections,
+            characters and tables that parsers mangle, contact details
+            outside the header, and mixed date formats. Saves ats.json
+            and the coverage percentage in meta.json.
+  export    Convert a job's resume and cover letter to Word or PDF
+            (resume.docx, cover.pdf, ...). Word styles come from
+            config/templates/reference.docx if it exists. --layout picks a
+            PDF preset: a4, classic, compact, or modern.
+  templates Import a .docx, Markdown, or text resume (or, with --cover,
+            cover letter) as the base template. Bullets and section headings
+            are normalized and the result is shown before anything is
+            replaced; --yes skips the confirmation. --name writes the named
+            variant instead of resume.txt/cover.txt.
   contacts  Manage networking contacts (see: jdextract contacts help).
   serve     Start the web UI. Defaults to port 8080; --open launches a browser.
 `
@@ -97,9 +138,19 @@ func main() {
 	case "generate":
 		cmdGenerate(os.Args[2:])
 	case "list":
-		cmdList()
+		cmdList(os.Args[2:])
 	case "status":
 		cmdStatus(os.Args[2:])
+	case "revise":
+		cmdRevise(os.Args[2:])
+	case "prep":
+		cmdPrep(os.Args[2:])
+	case "check":
+		cmdCheck(os.Args[2:])
+	case "export":
+		cmdExport(os.Args[2:])
+	case "templates":
+		cmdTemplates(os.Args[2:])
 	case "contacts":
 		cmdContacts(os.Args[2:])
 	case "serve":
@@ -123,11 +174,27 @@ func initApp() *jdextract.App {
 }
 
 // initAppWithConfig loads config and HTTP client, required for API-calling commands.
+// In replay mode (llm_mode or JDEXTRACT_LLM_MODE) no API key or network is
+// required, so the key and connectivity checks are skipped. The config is still
+// required: recordings are keyed by the request body, which includes the model
+// and parameters it sets.
 func initAppWithConfig() *jdextract.App {
 	app := initApp()
 	configPath := filepath.Join(app.Paths.Config, "config.json")
 	promptConfigPath := filepath.Join(app.Paths.Config, "prompt.json")
 	config, err := jdextract.LoadJSON[jdextract.Config](configPath)
+	if config != nil {
+		app.Config = *config
+	}
+	mode, modeErr := app.LLMMode()
+	if modeErr != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", modeErr)
+		os.Exit(1)
+	}
+	if err != nil && mode == jdextract.LLMModeReplay {
+		fmt.Fprintf(os.Stderr, "error: replay mode needs %s: recordings are keyed by the model and parameters it sets\n", configPath)
+		os.Exit(1)
+	}
 	if err != nil {
 		err = jdextract.CreateEmptyConfig(configPath)
 		err = jdextract.CreateEmptyPromptConfig(promptConfigPath)
@@ -138,12 +205,16 @@ func initAppWithConfig() *jdextract.App {
 		fmt.Printf("Created config at %s — fill in your API key and re-run.\n", configPath)
 		os.Exit(0)
 	}
-	app.Config = *config
 
 	if promptConfig, err := jdextract.LoadJSON[jdextract.PromptConfig](promptConfigPath); err == nil {
 		app.PromptConfig = *promptConfig
 	}
 
+	if mode == jdextract.LLMModeReplay {
+		fmt.Fprintf(os.Stderr, "Replaying LLM responses from %s\n", app.RecordingsDir())
+		return app
+	}
+
 	if app.Config.DeepSeekApiKey == "" || app.Config.DeepSeekApiKey == "example_key" {
 		fmt.Fprintf(os.Stderr, "error: set deepseek_api_key in %s\n", configPath)
 		os.Exit(1)
@@ -170,9 +241,24 @@ func cmdGenerate(args []string) {
 	fs := flag.NewFlagSet("generate", flag.ExitOnError)
 	local := fs.Bool("local", false, "Read job description from a local file instead of fetching via URL.")
 	batch := fs.Bool("batch", false, "Process multiple URLs concurrently (pass URLs as arguments).")
+	pipeline := fs.Bool("pipeline", false, "Use the multi-pass analyze/tailor/critique/revise pipeline.")
+	template := fs.String("template", "", `Base template: a name from config/templates/resumes/, "default", "profile", or "auto".`)
+	promptProfile := fs.String("prompt-profile", "", `Prompt profile: a name from config/prompts/ or "default". Overrides the experiment mode.`)
+	language := fs.String("language", "", "Posting language code (en, de, fr, es, it, nl, pt); detected when empty.")
+	tone := fs.String("tone", "", "Cover letter tone preset: "+strings.Join(jdextract.CoverTones(), ", ")+". Overrides cover_tone in config.json.")
 	fs.Parse(args)
 
 	app := initAppWithConfig()
+	opts := app.DefaultProcessOptions()
+	if *pipeline {
+		opts.Pipeline = true
+	}
+	if *template != "" {
+		opts.Template = *template
+	}
+	opts.PromptProfile = *promptProfile
+	opts.Language = *language
+	opts.Tone = *tone
 
 	if *batch {
 		if *local {
@@ -188,7 +274,7 @@ func cmdGenerate(args []string) {
 		total := len(urls)
 		done := 0
 		failed := 0
-		for r := range app.ProcessBatch(ctx, urls) {
+		for r := range app.ProcessBatch(ctx, urls, opts) {
 			done++
 			if r.Err != nil {
 				fmt.Fprintf(os.Stderr, "[%d/%d] error %s: %s\n", done, total, r.URL, r.Err)
@@ -245,7 +331,7 @@ func cmdGenerate(args []string) {
 		raw = string(data)
 	}
 
-	dir, err := app.ProcessWithProgress(context.Background(), raw, progress)
+	dir, err := app.ProcessWithOptions(context.Background(), raw, opts, progress)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "process error: %s\n", err)
 		os.Exit(1)
@@ -253,13 +339,20 @@ func cmdGenerate(args []string) {
 	fmt.Printf("Done. Output written to: %s\n", dir)
 }
 
-func cmdList() {
+func cmdList(args []string) {
+	fs := flag.NewFlagSet("list", flag.ExitOnError)
+	missing := fs.String("missing", "", "Only jobs whose fit analysis lists this requirement as missing.")
+	fs.Parse(args)
+
 	app := initApp()
 	jobs, err := jdextract.ListJobs(app)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "list error: %s\n", err)
 		os.Exit(1)
 	}
+	if *missing != "" {
+		jobs = jdextract.FilterJobsByRequirement(app, jobs, jdextract.RequirementMissing, *missing)
+	}
 	if len(jobs) == 0 {
 		fmt.Println("No jobs found.")
 		return
@@ -279,6 +372,217 @@ func cmdStatus(args []string) {
 	}
 }
 
+func cmdRevise(args []string) {
+	fs := flag.NewFlagSet("revise", flag.ExitOnError)
+	target := fs.String("target", "resume", "Document to revise: resume or cover.")
+	instructions := fs.String("instructions", "", "What to change, e.g. \"emphasize the Kafka migration\".")
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 1 || *instructions == "" {
+		fmt.Fprintln(os.Stderr, "usage: jdextract revise <prefix> --target resume|cover --instructions <text>")
+		os.Exit(1)
+	}
+
+	app := initAppWithConfig()
+	dir, err := jdextract.FindJobByPrefix(app, pos[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	err = app.ReviseJob(context.Background(), dir, *target, *instructions, func(e jdextract.ProgressEvent) {
+		switch {
+		case e.Stage == jdextract.StageContent:
+			fmt.Fprint(os.Stderr, e.Delta)
+		case e.Message != "":
+			fmt.Fprintf(os.Stderr, "%s\n", e.Message)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nrevise error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\nRevised %s in %s\n", *target, filepath.Join(app.Paths.Jobs, dir))
+}
+
+func cmdPrep(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: jdextract prep <prefix>")
+		os.Exit(1)
+	}
+
+	app := initAppWithConfig()
+	dir, err := jdextract.FindJobByPrefix(app, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	path, err := app.PrepJob(context.Background(), dir, func(e jdextract.ProgressEvent) {
+		switch {
+		case e.Stage == jdextract.StageContent:
+			fmt.Fprint(os.Stderr, e.Delta)
+		case e.Message != "":
+			fmt.Fprintf(os.Stderr, "%s\n", e.Message)
+		}
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "\nprep error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("\nWrote %s\n", path)
+}
+
+func cmdCheck(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: jdextract check <prefix>")
+		os.Exit(1)
+	}
+
+	app := initApp()
+	dir, err := jdextract.FindJobByPrefix(app, args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	rep, err := app.CheckJobATS(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "check error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Keyword coverage: %d%% (required %d%%, preferred %d%%)\n", rep.Coverage, rep.RequiredCoverage, rep.PreferredCoverage)
+	var missing, missingPreferred []string
+	for _, k := range rep.Keywords {
+		switch {
+		case k.Found:
+		case k.Preferred:
+			missingPreferred = append(missingPreferred, k.Term)
+		default:
+			missing = append(missing, k.Term)
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Printf("Missing required: %s\n", strings.Join(missing, ", "))
+	}
+	if len(missingPreferred) > 0 {
+		fmt.Printf("Missing preferred: %s\n", strings.Join(missingPreferred, ", "))
+	}
+	if len(rep.Issues) == 0 {
+		fmt.Println("No formatting issues.")
+		return
+	}
+	fmt.Printf("%d formatting issue(s):\n", len(rep.Issues))
+	for _, is := range rep.Issues {
+		fmt.Printf("  [%s] %s\n", is.Kind, is.Message)
+		if is.Line != "" {
+			fmt.Printf("      %s\n", is.Line)
+		}
+	}
+}
+
+func cmdExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	format := fs.String("format", jdextract.FormatDOCX, "Output format: docx or pdf.")
+	layout := fs.String("layout", "", "PDF layout preset: "+strings.Join(jdextract.PDFLayouts(), ", ")+".")
+	pos := parseInterspersed(fs, args)
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: jdextract export <prefix> [--format docx|pdf] [--layout <preset>]")
+		os.Exit(1)
+	}
+
+	// Export is offline: config.json is read only for pdf_layout, and a
+	// missing one just means the default layout.
+	app := initApp()
+	if config, err := jdextract.LoadJSON[jdextract.Config](filepath.Join(app.Paths.Config, "config.json")); err == nil {
+		app.Config.PDFLayout = config.PDFLayout
+	}
+	dir, err := jdextract.FindJobByPrefix(app, pos[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+
+	files, err := app.ExportJob(dir, jdextract.ExportOptions{Format: *format, Layout: *layout})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "export error: %s\n", err)
+		os.Exit(1)
+	}
+	for _, f := range files {
+		switch f.Pages {
+		case 0:
+			fmt.Printf("Wrote %s\n", f.Path)
+		case 1:
+			fmt.Printf("Wrote %s (1 page)\n", f.Path)
+		default:
+			fmt.Printf("Wrote %s (%d pages)\n", f.Path, f.Pages)
+		}
+	}
+}
+
+func cmdTemplates(args []string) {
+	if len(args) < 1 || args[0] != "import" {
+		fmt.Fprintln(os.Stderr, "usage: jdextract templates import <file> [--cover] [--name <variant>] [--yes]")
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("templates import", flag.ExitOnError)
+	cover := fs.Bool("cover", false, "Import a cover letter instead of a resume.")
+	name := fs.String("name", "", "Write the named variant instead of the default template.")
+	yes := fs.Bool("yes", false, "Replace an existing template without asking.")
+	pos := parseInterspersed(fs, args[1:])
+	if len(pos) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: jdextract templates import <file> [--cover] [--name <variant>] [--yes]")
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(pos[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	app := initApp()
+	imp, err := jdextract.PreviewTemplateImport(app, pos[0], data, *cover, *name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "import error: %s\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%s\n", imp.Text)
+	if imp.Current == imp.Text {
+		fmt.Printf("config/templates/%s is already up to date\n", imp.Path)
+		return
+	}
+	if imp.Current != "" && !*yes {
+		fmt.Printf("Replace config/templates/%s (%d lines) with the above (%d lines)? [y/N] ",
+			imp.Path, strings.Count(imp.Current, "\n"), strings.Count(imp.Text, "\n"))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Not replaced.")
+			return
+		}
+	}
+	if err := jdextract.SaveTemplate(filepath.Join(app.Paths.Templates, imp.Path), imp.Text); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Wrote config/templates/%s\n", imp.Path)
+}
+
+// parseInterspersed parses fs while allowing flags after positional
+// arguments, as in "revise <prefix> --target cover". It returns the
+// positional arguments in order.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var pos []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return pos
+		}
+		pos = append(pos, args[0])
+		args = args[1:]
+	}
+}
+
 // initAppForServe loads paths and config (if it exists) without failing on a
 // missing or unconfigured API key. The HTTP client is initialised best-effort.
 // Serve() itself calls Setup() to create dirs and an empty config if needed.
@@ -510,7 +814,7 @@ func cmdContactsFollowup(args []string) {
 		os.Exit(1)
 	}
 
-	b := app.Backend()
+	b := app.BackendFor(jdextract.TaskFollowup, nil)
 
 	fmt.Fprintln(os.Stderr, "Generating follow-up message…")
 	result, err := jdextract.GenerateFollowup(
@@ -518,12 +822,15 @@ func cmdContactsFollowup(args []string) {
 		b.Invoker,
 		b.StreamInvoker,
 		b.APIKey,
-		b.Model,
+		b.Params,
 		&app.Client,
 		*contact,
 		app.NetworkingPromptConfig,
 		func(delta string) { fmt.Fprint(os.Stderr, delta) },
 	)
+	if err := b.Transcript.Flush(filepath.Join(app.Paths.Contacts, dir)); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: write transcript: %s\n", err)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "\ngenerate error: %s\n", err)
 		os.Exit(1)
@@ -0,0 +1,172 @@
+package jdextract
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// analysisFile is the per-job fit analysis written by Process.
+const analysisFile = "analysis.json"
+
+// Requirement statuses.
+const (
+	RequirementMet     = "met"
+	RequirementPartial = "partial"
+	RequirementMissing = "missing"
+)
+
+var requirementStatuses = []string{RequirementMet, RequirementPartial, RequirementMissing}
+
+var subscoresTagRe = regexp.MustCompile(`(?s)<subscores>(.*?)</subscores>`)
+
+// SubScores rates fit along individual dimensions, each 1–10. A zero means
+// the dimension was not assessed, e.g. a posting without compensation.
+type SubScores struct {
+	Skills       int `json:"skills"`
+	Experience   int `json:"experience"`
+	Domain       int `json:"domain"`
+	Location     int `json:"location"`
+	Compensation int `json:"compensation"`
+}
+
+// Requirement is one requirement from the job description and how well the
+// base resume covers it.
+type Requirement struct {
+	Text     string `json:"requirement"`
+	Status   string `json:"status"` // met, partial, or missing
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// FitAnalysis is the detailed fit assessment stored as analysis.json.
+type FitAnalysis struct {
+	Score        int           `json:"score"`
+	SubScores    SubScores     `json:"sub_scores"`
+	Requirements []Requirement `json:"requirements"`
+}
+
+// parseSubScores reads "dimension: score" lines. Common synonyms for each
+// dimension are accepted; unknown lines are ignored.
+func parseSubScores(s string) SubScores {
+	var out SubScores
+	for _, line := range strings.Split(s, "\n") {
+		key, val, ok := strings.Cut(strings.TrimLeft(strings.TrimSpace(line), "-* "), ":")
+		if !ok {
+			continue
+		}
+		n, _ := parseScore(val)
+		key = strings.ToLower(strings.TrimSpace(key))
+		switch {
+		case strings.Contains(key, "skill"):
+			out.Skills = n
+		case strings.Contains(key, "experience"), strings.Contains(key, "seniority"):
+			out.Experience = n
+		case strings.Contains(key, "domain"), strings.Contains(key, "industry"):
+			out.Domain = n
+		case strings.Contains(key, "location"), strings.Contains(key, "remote"):
+			out.Location = n
+		case strings.Contains(key, "compensation"), strings.Contains(key, "salary"), strings.Contains(key, "pay"):
+			out.Compensation = n
+		}
+	}
+	return out
+}
+
+// parseRequirements reads "status | requirement | evidence" lines. The status
+// is normalized to met, partial, or missing; lines without a recognizable
+// status are skipped.
+func parseRequirements(s string) []Requirement {
+	var out []Requirement
+	for _, line := range strings.Split(s, "\n") {
+		parts := strings.SplitN(strings.TrimLeft(strings.TrimSpace(line), "-* "), "|", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		status := normalizeRequirementStatus(parts[0])
+		text := strings.TrimSpace(parts[1])
+		if status == "" || text == "" {
+			continue
+		}
+		r := Requirement{Text: text, Status: status}
+		if len(parts) == 3 && status != RequirementMissing {
+			r.Evidence = strings.TrimSpace(parts[2])
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func normalizeRequirementStatus(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	switch {
+	case strings.HasPrefix(s, "partial"):
+		return RequirementPartial
+	case strings.HasPrefix(s, "missing"), strings.HasPrefix(s, "not"), s == "no", s == "none", s == "unmet":
+		return RequirementMissing
+	case strings.HasPrefix(s, "met"), s == "yes", s == "full":
+		return RequirementMet
+	}
+	return ""
+}
+
+// extractAnalysis builds a FitAnalysis from tagged content, or returns nil if
+// the response carried neither sub-scores nor requirements.
+func extractAnalysis(content string, score int) *FitAnalysis {
+	subs := extractTag(subscoresTagRe, content)
+	reqs := extractTag(requirementsTagRe, content)
+	if subs == "" && reqs == "" {
+		return nil
+	}
+	return &FitAnalysis{
+		Score:        score,
+		SubScores:    parseSubScores(subs),
+		Requirements: parseRequirements(reqs),
+	}
+}
+
+// Has reports whether f lists a requirement with status whose text contains
+// term (case-insensitive).
+func (f *FitAnalysis) Has(status, term string) bool {
+	for _, r := range f.Requirements {
+		if r.Status == status && matchesQuery(term, r.Text) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAnalysis reads a job's analysis.json.
+func GetAnalysis(a *App, id string) (*FitAnalysis, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	return LoadJSON[FitAnalysis](filepath.Join(a.Paths.Jobs, id, analysisFile))
+}
+
+// FilterJobsByRequirement keeps jobs whose analysis lists a requirement with
+// status matching term. Jobs without an analysis are dropped.
+func FilterJobsByRequirement(a *App, jobs []ApplicationMeta, status, term string) []ApplicationMeta {
+	var out []ApplicationMeta
+	for _, j := range jobs {
+		f, err := GetAnalysis(a, j.Dir)
+		if err != nil || !f.Has(status, term) {
+			continue
+		}
+		out = append(out, j)
+	}
+	return out
+}
+
+// applyAnalysisFilters filters jobs by requirement coverage. Each of the
+// optional parameters met, partial, and missing names a substring that must
+// appear in a requirement with that status, e.g. ?missing=kubernetes.
+func applyAnalysisFilters(a *App, jobs []ApplicationMeta, r *http.Request) []ApplicationMeta {
+	for _, status := range requirementStatuses {
+		if term := strings.TrimSpace(r.URL.Query().Get(status)); term != "" {
+			jobs = FilterJobsByRequirement(a, jobs, status, term)
+		}
+	}
+	return jobs
+}
@@ -0,0 +1,32 @@
+package jdextract
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSubScores(t *testing.T) {
+	got := parseSubScores("Skills: 8\n- Experience level: 6/10\ndomain: 7\nLocation/remote: 9\nCompensation: n/a\nculture: 5")
+	want := SubScores{Skills: 8, Experience: 6, Domain: 7, Location: 9}
+	if got != want {
+		t.Errorf("parseSubScores = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseRequirements(t *testing.T) {
+	got := parseRequirements(`met | 5+ years Go | Built Go services at Acme since 2018
+- Partially met | Kubernetes | Deployed to EKS via Helm charts
+missing | Rust | none
+Not met | On-call experience
+unclear | something
+just a line of prose`)
+	want := []Requirement{
+		{Text: "5+ years Go", Status: RequirementMet, Evidence: "Built Go services at Acme since 2018"},
+		{Text: "Kubernetes", Status: RequirementPartial, Evidence: "Deployed to EKS via Helm charts"},
+		{Text: "Rust", Status: RequirementMissing},
+		{Text: "On-call experience", Status: RequirementMissing},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseRequirements =\n%+v\nwant\n%+v", got, want)
+	}
+}
@@ -0,0 +1,327 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// TaskAnswers is the Config.Tasks key for application question answers.
+const TaskAnswers = "answers"
+
+// answersFile holds a job's drafted application answers.
+const answersFile = "answers.json"
+
+// answerBankFile is the global bank of approved answers under data/.
+const answerBankFile = "answer_bank.json"
+
+// bankMatchThreshold is the minimum keyword similarity for a bank entry to be
+// offered as a starting point for a new question.
+const bankMatchThreshold = 0.3
+
+const answersPrompt = `You are helping a candidate answer free-text questions on a job application. Ground every answer in the job description and the base resume: use only facts the resume contains, and connect them to what the role asks for. Write in the first person, plainly, without clichés. Keep each answer under 200 words unless the question asks for more.
+
+Some questions come with a previously approved answer to a similar question. Use it as a starting point and adapt it to this company and role rather than copying it.
+
+Respond with one tag per question, numbered as in the input: <answer_1>...</answer_1>, <answer_2>...</answer_2>, and so on.`
+
+// Answer is one drafted application answer.
+type Answer struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Approved bool   `json:"approved"`
+	FromBank string `json:"from_bank,omitempty"` // bank question used as the starting point
+}
+
+// BankEntry is an approved answer kept for reuse across applications.
+type BankEntry struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	Job      string `json:"job"`
+	Company  string `json:"company,omitempty"`
+	Role     string `json:"role,omitempty"`
+	Date     string `json:"date"`
+}
+
+// BankMatch is a bank entry and its similarity to a question, from 0 to 1.
+type BankMatch struct {
+	BankEntry
+	Score float64 `json:"score"`
+}
+
+// LoadAnswerBank reads data/answer_bank.json; a missing bank is empty.
+func LoadAnswerBank(a *App) ([]BankEntry, error) {
+	bank, err := LoadJSON[[]BankEntry](filepath.Join(a.Paths.Data, answerBankFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []BankEntry{}, nil
+		}
+		return nil, fmt.Errorf("read answer bank: %w", err)
+	}
+	return *bank, nil
+}
+
+// SearchAnswerBank returns the entries whose questions are similar to
+// question, best first, keeping those at or above bankMatchThreshold.
+// Similarity is the Jaccard index of the two questions' keywords.
+func SearchAnswerBank(bank []BankEntry, question string) []BankMatch {
+	q := keywords(question)
+	out := []BankMatch{}
+	for _, e := range bank {
+		if s := keywordSimilarity(q, keywords(e.Question)); s >= bankMatchThreshold {
+			out = append(out, BankMatch{BankEntry: e, Score: s})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out
+}
+
+// AnswersInput is the material for drafting application answers.
+type AnswersInput struct {
+	Company, Role  string
+	JobDescription string
+	BaseResume     string
+	Questions      []string
+	StartingPoints []*BankEntry // per question; nil where the bank had no match
+}
+
+// DraftAnswers asks the LLM to answer each question and returns the answers
+// in order. Questions whose tags are missing after repair get one follow-up
+// turn; answers still missing are returned as "".
+func DraftAnswers(
+	ctx context.Context,
+	invoker LLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	in AnswersInput,
+) ([]string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "COMPANY: %s\nROLE: %s\n\nJOB DESCRIPTION:\n%s\n\nBASE RESUME:\n%s\n\nQUESTIONS:\n",
+		in.Company, in.Role, Sanitize(in.JobDescription), Sanitize(in.BaseResume))
+	tags := make([]string, len(in.Questions))
+	for i, q := range in.Questions {
+		tags[i] = fmt.Sprintf("answer_%d", i+1)
+		fmt.Fprintf(&sb, "%d. %s\n", i+1, q)
+		if i < len(in.StartingPoints) && in.StartingPoints[i] != nil {
+			p := in.StartingPoints[i]
+			fmt.Fprintf(&sb, "   Previously approved answer to %q:\n   %s\n", p.Question, Sanitize(p.Answer))
+		}
+	}
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: answersPrompt},
+		{Role: "user", Content: sb.String()},
+	}
+	content, _, _, err := complete(ctx, invoker, nil, apiKey, params, c, messages, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	content, _ = repairTagged(content, tags)
+	found := extractTags(content, tags)
+
+	var missing []string
+	for _, tag := range tags {
+		if found[tag] == "" {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) > 0 {
+		reply, _, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, missing, tags)
+		if err != nil {
+			return nil, err
+		}
+		for tag, v := range extractTags(reply, missing) {
+			found[tag] = v
+		}
+	}
+
+	out := make([]string, len(tags))
+	for i, tag := range tags {
+		out[i] = found[tag]
+	}
+	return out, nil
+}
+
+// GetAnswers reads a job's answers.json; a job without answers has none.
+func GetAnswers(a *App, id string) ([]Answer, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	answers, err := LoadJSON[[]Answer](filepath.Join(a.Paths.Jobs, id, answersFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []Answer{}, nil
+		}
+		return nil, err
+	}
+	return *answers, nil
+}
+
+// AnswerQuestions drafts answers to questions for a job, grounded in its
+// stored JD and base template, and saves them to answers.json. Each question
+// is first looked up in the answer bank and the best match is passed to the
+// LLM as a starting point. A question already in answers.json is replaced by
+// the new, unapproved draft, and an approved one is withdrawn from the bank.
+// It returns the job's full answer list.
+func (a *App) AnswerQuestions(ctx context.Context, id string, questions []string) ([]Answer, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	if len(questions) == 0 {
+		return nil, fmt.Errorf("at least one question is required")
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return nil, fmt.Errorf("read meta: %w", err)
+	}
+	in := AnswersInput{Company: meta.Company, Role: meta.Role, Questions: questions}
+	if jd, err := os.ReadFile(filepath.Join(a.Paths.Jobs, id, jdFile)); err == nil {
+		in.JobDescription = string(jd)
+	}
+	if base, err := LoadTemplates(a, meta.Template); err == nil {
+		in.BaseResume = base.Resume
+	}
+	bank, err := LoadAnswerBank(a)
+	if err != nil {
+		return nil, err
+	}
+	for _, q := range questions {
+		var start *BankEntry
+		if m := SearchAnswerBank(bank, q); len(m) > 0 {
+			start = &m[0].BankEntry
+		}
+		in.StartingPoints = append(in.StartingPoints, start)
+	}
+
+	b := a.BackendFor(TaskAnswers, nil)
+	drafts, err := DraftAnswers(ctx, b.Invoker, b.APIKey, b.Params, &a.Client, in)
+	flushTranscripts(b.Transcript, filepath.Join(a.Paths.Jobs, id))
+	if err != nil {
+		return nil, fmt.Errorf("draft answers: %w", err)
+	}
+
+	a.answersMu.Lock()
+	defer a.answersMu.Unlock()
+	answers, err := GetAnswers(a, id)
+	if err != nil {
+		return nil, err
+	}
+	var withdrawn []string
+	for i, q := range questions {
+		ans := Answer{Question: q, Answer: drafts[i]}
+		if p := in.StartingPoints[i]; p != nil {
+			ans.FromBank = p.Question
+		}
+		if j := findAnswer(answers, q); j >= 0 {
+			if answers[j].Approved {
+				withdrawn = append(withdrawn, answers[j].Question)
+			}
+			answers[j] = ans
+		} else {
+			answers = append(answers, ans)
+		}
+	}
+	if err := SaveJSON(filepath.Join(a.Paths.Jobs, id, answersFile), answers, 0644); err != nil {
+		return nil, fmt.Errorf("write answers: %w", err)
+	}
+	for _, q := range withdrawn {
+		if err := a.setBankEntry(id, q, nil); err != nil {
+			return nil, err
+		}
+	}
+	return answers, nil
+}
+
+var questionSpaceRe = regexp.MustCompile(`\s+`)
+
+func normalizeQuestion(q string) string {
+	return strings.ToLower(questionSpaceRe.ReplaceAllString(strings.TrimSpace(q), " "))
+}
+
+func findAnswer(answers []Answer, question string) int {
+	for i, a := range answers {
+		if normalizeQuestion(a.Question) == normalizeQuestion(question) {
+			return i
+		}
+	}
+	return -1
+}
+
+// UpdateAnswer edits and/or approves answer index of a job. Approving copies
+// the answer into the global bank, replacing this job's earlier entry for the
+// same question; withdrawing approval removes it.
+func (a *App) UpdateAnswer(id string, index int, text *string, approved *bool) (*Answer, error) {
+	a.answersMu.Lock()
+	defer a.answersMu.Unlock()
+	answers, err := GetAnswers(a, id)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(answers) {
+		return nil, fmt.Errorf("answer %d: %w", index, os.ErrNotExist)
+	}
+	ans := &answers[index]
+	if text != nil {
+		ans.Answer = *text
+	}
+	if approved != nil {
+		ans.Approved = *approved
+	}
+	if err := SaveJSON(filepath.Join(a.Paths.Jobs, id, answersFile), answers, 0644); err != nil {
+		return nil, fmt.Errorf("write answers: %w", err)
+	}
+
+	if approved == nil && !ans.Approved {
+		return ans, nil
+	}
+	var entry *BankEntry
+	if ans.Approved {
+		meta, err := a.Jobs.ReadMeta(id)
+		if err != nil {
+			return nil, fmt.Errorf("read meta: %w", err)
+		}
+		entry = &BankEntry{
+			Question: ans.Question,
+			Answer:   ans.Answer,
+			Job:      id,
+			Company:  meta.Company,
+			Role:     meta.Role,
+			Date:     currentDate(),
+		}
+	}
+	if err := a.setBankEntry(id, ans.Question, entry); err != nil {
+		return nil, err
+	}
+	return ans, nil
+}
+
+// setBankEntry replaces job id's bank entry for question with entry, or
+// removes it when entry is nil.
+func (a *App) setBankEntry(id, question string, entry *BankEntry) error {
+	a.answerBankMu.Lock()
+	defer a.answerBankMu.Unlock()
+	bank, err := LoadAnswerBank(a)
+	if err != nil {
+		return err
+	}
+	kept := bank[:0]
+	for _, e := range bank {
+		if e.Job != id || normalizeQuestion(e.Question) != normalizeQuestion(question) {
+			kept = append(kept, e)
+		}
+	}
+	if entry != nil {
+		kept = append(kept, *entry)
+	}
+	if err := SaveJSON(filepath.Join(a.Paths.Data, answerBankFile), kept, 0644); err != nil {
+		return fmt.Errorf("write answer bank: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package jdextract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSearchAnswerBank(t *testing.T) {
+	bank := []BankEntry{
+		{Question: "Describe a difficult bug you fixed.", Answer: "a"},
+		{Question: "Why do you want to work here?", Answer: "b"},
+		{Question: "Why do you want to work at Acme?", Answer: "c"},
+	}
+	got := SearchAnswerBank(bank, "Why do you want to work at Acme Corp?")
+	if len(got) != 2 || got[0].Answer != "c" || got[1].Answer != "b" {
+		t.Errorf("matches = %+v", got)
+	}
+	if got := SearchAnswerBank(bank, "What is your salary expectation?"); len(got) != 0 {
+		t.Errorf("unrelated question matched %+v", got)
+	}
+}
+
+func TestDraftAnswers(t *testing.T) {
+	var calls int
+	in := AnswersInput{
+		Company:        "Acme Corp",
+		Questions:      []string{"Why Acme?", "Describe a hard bug you fixed."},
+		StartingPoints: []*BankEntry{nil, {Question: "Describe a difficult bug.", Answer: "A tracking pixel fired twice."}},
+	}
+	got, err := DraftAnswers(context.Background(), fakeInvoker("<answer_1>Brand work.</answer_1>\n<answer_2>The pixel.</answer_2>", &calls), "", TaskParams{Model: "m"}, nil, in)
+	if err != nil {
+		t.Fatalf("DraftAnswers: %v", err)
+	}
+	if len(got) != 2 || got[0] != "Brand work." || got[1] != "The pixel." || calls != 1 {
+		t.Errorf("answers = %q after %d calls", got, calls)
+	}
+}
+
+func TestUpdateAnswerBank(t *testing.T) {
+	a := newTestApp(t)
+	slug, err := a.Jobs.MkDir("acme-copywriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, slug)
+	if err := SaveJSON(filepath.Join(dir, "meta.json"), ApplicationMeta{Company: "Acme Corp", Role: "Copywriter"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	drafts := []Answer{{Question: "Why Acme?", Answer: "Brand work."}, {Question: "Describe a hard bug.", Answer: "The pixel."}}
+	if err := SaveJSON(filepath.Join(dir, answersFile), drafts, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	edited := "A tracking pixel fired twice."
+	approved := true
+	if _, err := a.UpdateAnswer(slug, 1, &edited, &approved); err != nil {
+		t.Fatalf("UpdateAnswer: %v", err)
+	}
+	bank, err := LoadAnswerBank(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bank) != 1 || bank[0].Answer != edited || bank[0].Company != "Acme Corp" || bank[0].Job != slug {
+		t.Fatalf("bank = %+v", bank)
+	}
+	if m := SearchAnswerBank(bank, "Describe the hardest bug you fixed."); len(m) != 1 {
+		t.Errorf("similar question found %d matches", len(m))
+	}
+
+	// Editing an approved answer updates its bank entry in place.
+	edited = "A tracking pixel fired twice on checkout."
+	if _, err := a.UpdateAnswer(slug, 1, &edited, nil); err != nil {
+		t.Fatal(err)
+	}
+	if bank, _ := LoadAnswerBank(a); len(bank) != 1 || bank[0].Answer != edited {
+		t.Errorf("bank after edit = %+v", bank)
+	}
+
+	approved = false
+	if _, err := a.UpdateAnswer(slug, 1, nil, &approved); err != nil {
+		t.Fatal(err)
+	}
+	if bank, _ := LoadAnswerBank(a); len(bank) != 0 {
+		t.Errorf("unapproved answer left in bank: %+v", bank)
+	}
+	if _, err := a.UpdateAnswer(slug, 5, nil, &approved); err == nil {
+		t.Error("out-of-range index accepted")
+	}
+}
+
+func TestUpdateAnswerConcurrentApprovals(t *testing.T) {
+	a := newTestApp(t)
+	const jobs = 32
+	var slugs []string
+	for i := range jobs {
+		slug, err := a.Jobs.MkDir(fmt.Sprintf("job-%d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		dir := filepath.Join(a.Paths.Jobs, slug)
+		if err := SaveJSON(filepath.Join(dir, "meta.json"), ApplicationMeta{Company: slug}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := SaveJSON(filepath.Join(dir, answersFile), []Answer{{Question: "Why us?", Answer: slug}}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		slugs = append(slugs, slug)
+	}
+
+	var wg sync.WaitGroup
+	approved := true
+	for _, slug := range slugs {
+		wg.Go(func() {
+			if _, err := a.UpdateAnswer(slug, 0, nil, &approved); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	if bank, _ := LoadAnswerBank(a); len(bank) != jobs {
+		t.Errorf("bank has %d entries, want %d", len(bank), jobs)
+	}
+}
+
+func TestUpdateAnswerConcurrentSameJob(t *testing.T) {
+	a := newTestApp(t)
+	slug, err := a.Jobs.MkDir("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, slug)
+	if err := SaveJSON(filepath.Join(dir, "meta.json"), ApplicationMeta{Company: "Acme"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	const n = 32
+	var drafts []Answer
+	for i := range n {
+		drafts = append(drafts, Answer{Question: fmt.Sprintf("Question %d?", i), Answer: "draft"})
+	}
+	if err := SaveJSON(filepath.Join(dir, answersFile), drafts, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	approved := true
+	for i := range n {
+		wg.Go(func() {
+			if _, err := a.UpdateAnswer(slug, i, nil, &approved); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+	answers, err := GetAnswers(a, slug)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ans := range answers {
+		if !ans.Approved {
+			t.Errorf("answer %d lost its approval", i)
+		}
+	}
+	if bank, _ := LoadAnswerBank(a); len(bank) != n {
+		t.Errorf("bank has %d entries, want %d", len(bank), n)
+	}
+}
+
+// completionTransport answers every request with a non-streamed completion.
+type completionTransport string
+
+func (c completionTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	body, err := completionBody(string(c), "")
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestAnswerQuestionsRedraftWithdrawsApproval(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLMMode = LLMModeLive
+	a.Client = http.Client{Transport: completionTransport("<answer_1>A new draft.</answer_1>")}
+	slug, err := a.Jobs.MkDir("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, slug)
+	if err := SaveJSON(filepath.Join(dir, "meta.json"), ApplicationMeta{Company: "Acme"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveJSON(filepath.Join(dir, answersFile), []Answer{{Question: "Why Acme?", Answer: "Brand work."}}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	approved := true
+	if _, err := a.UpdateAnswer(slug, 0, nil, &approved); err != nil {
+		t.Fatal(err)
+	}
+
+	answers, err := a.AnswerQuestions(context.Background(), slug, []string{"why acme?"})
+	if err != nil {
+		t.Fatalf("AnswerQuestions: %v", err)
+	}
+	if len(answers) != 1 || answers[0].Answer != "A new draft." || answers[0].Approved {
+		t.Errorf("answers = %+v", answers)
+	}
+	if bank, _ := LoadAnswerBank(a); len(bank) != 0 {
+		t.Errorf("replaced answer left in bank: %+v", bank)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 // PortablePaths holds all directory paths resolved relative to the executable.
@@ -30,34 +31,103 @@ type App struct {
 	Client                 http.Client
 	Jobs                   Store[ApplicationMeta]
 	Contacts               Store[ContactMeta]
+
+	limiterMu sync.Mutex
+	limiters  map[string]*Limiter // one per backend name, created on first use
+
+	experimentMu sync.Mutex // serializes the round-robin cursor in data/experiment.json
+	answerBankMu sync.Mutex // serializes updates to data/answer_bank.json
+	chatMu       sync.Mutex // serializes updates to jobs' chat.json
+	answersMu    sync.Mutex // serializes updates to jobs' answers.json
 }
 
-// LLMBackend holds the resolved invoker functions and credentials for the
-// configured LLM backend.
+// LLMBackend holds the resolved invoker functions, credentials, and task
+// parameters for one LLM task. Transcript is non-nil when transcript logging
+// is enabled; callers flush it into the job or contact directory once known.
 type LLMBackend struct {
 	Invoker       LLMInvoker
 	StreamInvoker StreamingLLMInvoker
 	APIKey        string
-	Model         string
+	Params        TaskParams // resolved backend, model, and sampling parameters
+	Transcript    *TranscriptRecorder
 }
 
 // Backend returns the LLM invoker functions and credentials for the currently
-// configured backend (deepseek or kimi).
+// configured backend (deepseek or kimi) with no task overrides. Every invoker
+// call is admitted by the backend's shared Limiter, so concurrent CLI batches
+// and web requests respect the same in-flight and per-minute caps.
 func (a *App) Backend() LLMBackend {
-	if a.Config.Backend == "kimi" {
-		return LLMBackend{
-			Invoker:       InvokeKimiApi,
-			StreamInvoker: InvokeKimiApiStream,
-			APIKey:        a.Config.KimiApiKey,
-			Model:         a.Config.KimiModel,
+	return a.BackendFor("", nil)
+}
+
+// BackendFor returns the backend for task, routed and parameterized by its
+// Config.Tasks entry (see TaskParams). Queue position is reported as
+// StageQueued events through onProgress (may be nil) while a call waits for
+// capacity. With an unknown LLM mode every call fails with the mode error.
+func (a *App) BackendFor(task string, onProgress func(ProgressEvent)) LLMBackend {
+	p := a.TaskParams(task)
+	b := LLMBackend{Params: p}
+	var limits RateLimitConfig
+	if p.Backend == "kimi" {
+		b.Invoker = InvokeKimiApi
+		b.StreamInvoker = InvokeKimiApiStream
+		b.APIKey = a.Config.KimiApiKey
+		limits = a.Config.KimiLimits
+	} else {
+		b.Invoker = InvokeDeepseekApi
+		b.StreamInvoker = InvokeDeepseekApiStream
+		b.APIKey = a.Config.DeepSeekApiKey
+		limits = a.Config.DeepSeekLimits
+	}
+	mode, err := a.LLMMode()
+	switch {
+	case err != nil:
+		b.Invoker = failInvoker(err)
+		b.StreamInvoker = failStreamInvoker(err)
+	case mode == LLMModeRecord:
+		dir := a.RecordingsDir()
+		b.Invoker = recordInvoker(dir, b.Invoker)
+		b.StreamInvoker = recordStreamInvoker(dir, b.StreamInvoker)
+	case mode == LLMModeReplay:
+		dir := a.RecordingsDir()
+		b.Invoker = replayInvoker(dir)
+		b.StreamInvoker = replayStreamInvoker(dir)
+	}
+	if a.Config.Transcripts.Enabled {
+		b.Transcript = &TranscriptRecorder{
+			cfg:     a.Config.Transcripts,
+			backend: p.Backend,
+			model:   p.Model,
+			secrets: []string{a.Config.DeepSeekApiKey, a.Config.KimiApiKey},
 		}
+		b.Invoker = b.Transcript.wrap(b.Invoker)
+		b.StreamInvoker = b.Transcript.wrapStream(b.StreamInvoker)
+	}
+	l := a.limiter(p.Backend, limits)
+	b.Invoker = limitInvoker(l, b.Invoker, onProgress)
+	b.StreamInvoker = limitStreamInvoker(l, b.StreamInvoker, onProgress)
+	return b
+}
+
+// limiter returns the shared Limiter for backend, creating it on first use and
+// applying cfg so config edits take effect without a restart.
+func (a *App) limiter(backend string, cfg RateLimitConfig) *Limiter {
+	if backend == "" {
+		backend = "deepseek"
+	}
+	a.limiterMu.Lock()
+	defer a.limiterMu.Unlock()
+	if a.limiters == nil {
+		a.limiters = make(map[string]*Limiter)
 	}
-	return LLMBackend{
-		Invoker:       InvokeDeepseekApi,
-		StreamInvoker: InvokeDeepseekApiStream,
-		APIKey:        a.Config.DeepSeekApiKey,
-		Model:         a.Config.DeepSeekModel,
+	l, ok := a.limiters[backend]
+	if !ok {
+		l = NewLimiter(cfg)
+		a.limiters[backend] = l
+		return l
 	}
+	l.configure(cfg)
+	return l
 }
 
 func getPortablePaths() (PortablePaths, error) {
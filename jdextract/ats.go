@@ -0,0 +1,462 @@
+package jdextract
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// atsFile is the per-job ATS check written by Process and CheckJobATS, and
+// refreshed whenever the resume is revised.
+const atsFile = "ats.json"
+
+// ATS issue kinds.
+const (
+	ATSMissingSection = "missing_section"
+	ATSCharacter      = "character"
+	ATSTable          = "table"
+	ATSContact        = "contact"
+	ATSDateFormat     = "date_format"
+)
+
+// ATSKeyword is one term from the job description's requirements.
+type ATSKeyword struct {
+	Term      string `json:"term"`
+	Preferred bool   `json:"preferred,omitempty"`
+	Found     bool   `json:"found"`
+}
+
+// ATSIssue is one formatting problem that an applicant tracking system is
+// likely to stumble over.
+type ATSIssue struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    string `json:"line,omitempty"` // first resume line it occurs on
+}
+
+// ATSReport is the deterministic ATS check stored as ats.json. Coverage
+// percentages are of distinct terms; they are 0 when the JD has none.
+type ATSReport struct {
+	Coverage          int          `json:"coverage"`
+	RequiredCoverage  int          `json:"required_coverage"`
+	PreferredCoverage int          `json:"preferred_coverage"`
+	Keywords          []ATSKeyword `json:"keywords"`
+	Issues            []ATSIssue   `json:"issues"`
+}
+
+var (
+	// atsRequiredRe and atsPreferredRe classify JD section headings. A
+	// heading matching neither ends the current requirement section.
+	atsRequiredRe  = regexp.MustCompile(`(?i)\b(requirements?|required|qualifications|must.haves?|what we.re looking for|you bring|you have|who you are|about you|skills)\b`)
+	atsPreferredRe = regexp.MustCompile(`(?i)\b(preferred|nice.to.haves?|bonus|a plus|desired|ideally)\b`)
+
+	// atsFillerWords are words common in requirement bullets that no ATS
+	// would match on.
+	atsFillerWords = map[string]bool{
+		"ability": true, "able": true, "across": true, "strong": true, "excellent": true, "good": true,
+		"great": true, "experience": true, "experienced": true, "knowledge": true, "understanding": true,
+		"skills": true, "skill": true, "proven": true, "track": true, "record": true, "plus": true,
+		"preferred": true, "bonus": true, "nice": true, "have": true, "using": true, "including": true,
+		"least": true, "year": true, "demonstrated": true, "familiarity": true, "familiar": true,
+		"working": true, "well": true, "like": true, "e.g": true, "etc": true, "other": true,
+		"related": true, "relevant": true, "similar": true, "within": true, "both": true, "ideally": true,
+		"required": true, "requirements": true, "comfortable": true, "environment": true, "solid": true,
+	}
+
+	atsEmailRe = regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`)
+	atsPhoneRe = regexp.MustCompile(`\+?\d[\d\s().-]{6,}\d`)
+
+	// atsColumnRe matches a gap of three or more spaces between words, the
+	// mark of a two-column layout flattened to text.
+	atsColumnRe     = regexp.MustCompile(`\S(?: {3,}|\t+)\S`)
+	atsTableRuleRe  = regexp.MustCompile(`^\|?\s*:?-{3,}:?\s*(\|\s*:?-{3,}:?\s*)+\|?$`)
+	atsYearRangeRe  = regexp.MustCompile(`(?i)\b(?:19|20)\d{2}\s*(?:-|–|—|to)\s*(?:(?:19|20)\d{2}|present|current)\b`)
+	atsIsoMonthRe   = regexp.MustCompile(`\b(?:19|20)\d{2}-(?:0[1-9]|1[0-2])\b`)
+	atsMonthNameRe  = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sept|sep|oct|nov|dec)([a-z]*)\.?,?\s+(?:19|20)\d{2}\b`)
+	atsBulletMarkRe = regexp.MustCompile(`^(\S)\s`)
+)
+
+// atsSections are the standard resume sections and the headings accepted
+// for each.
+var atsSections = []struct {
+	Name string
+	Re   *regexp.Regexp
+}{
+	{"Experience", regexp.MustCompile(`(?i)\b(experience|employment|work history|career history)\b`)},
+	{"Education", regexp.MustCompile(`(?i)\b(education|academic|degrees?)\b`)},
+	{"Skills", regexp.MustCompile(`(?i)\b(skills|competencies|technologies|expertise)\b`)},
+}
+
+// atsHeaderLines is how many lines from the top count as the resume header
+// for the contact check.
+const atsHeaderLines = 6
+
+// atsTerms returns the distinct keywords of the JD's required and preferred
+// requirement lines. Lines under a heading matching atsPreferredRe, or
+// containing a preferred cue themselves, are preferred; a term listed as
+// both is required. A JD without recognizable requirement sections has all
+// its bullets treated as required.
+func atsTerms(nodes []JobDescriptionNode) (required, preferred []string) {
+	req, pref := map[string]bool{}, map[string]bool{}
+	section := ""
+	var bullets []string
+	for _, n := range nodes {
+		text := strings.TrimSpace(strings.Trim(strings.TrimSpace(n.Content), "#*"))
+		switch n.NodeType {
+		case NodeHeading, NodeSectionHeader, NodeJobTitle, NodeJinaTitle:
+			section = ""
+			switch {
+			case atsPreferredRe.MatchString(text):
+				section = "preferred"
+			case atsRequiredRe.MatchString(text):
+				section = "required"
+			}
+			continue
+		case NodeBullet, NodeYearsExp:
+			bullets = append(bullets, text)
+		case NodeBody:
+			if strings.HasSuffix(text, ":") && len(text) <= joinWidth {
+				section = ""
+				if atsPreferredRe.MatchString(text) {
+					section = "preferred"
+				} else if atsRequiredRe.MatchString(text) {
+					section = "required"
+				}
+				continue
+			}
+		}
+		if section == "" || n.NodeType != NodeBullet && n.NodeType != NodeYearsExp && n.NodeType != NodeBody {
+			continue
+		}
+		dst := req
+		if section == "preferred" || atsPreferredRe.MatchString(text) {
+			dst = pref
+		}
+		for k := range atsKeywords(text) {
+			dst[k] = true
+		}
+	}
+	if len(req) == 0 && len(pref) == 0 {
+		for _, b := range bullets {
+			for k := range atsKeywords(b) {
+				req[k] = true
+			}
+		}
+	}
+	for k := range req {
+		delete(pref, k)
+		required = append(required, k)
+	}
+	for k := range pref {
+		preferred = append(preferred, k)
+	}
+	sort.Strings(required)
+	sort.Strings(preferred)
+	return required, preferred
+}
+
+// atsKeywords is keywords without filler words, numbers, and bullet marks.
+func atsKeywords(s string) map[string]bool {
+	out := keywords(bulletMarkRe.ReplaceAllString(s, ""))
+	for k := range out {
+		if atsFillerWords[k] || strings.IndexFunc(k, unicode.IsLetter) < 0 {
+			delete(out, k)
+		}
+	}
+	return out
+}
+
+// atsHas reports whether the resume keyword set contains term, allowing a
+// plural "s" on either side.
+func atsHas(words map[string]bool, term string) bool {
+	return words[term] || words[term+"s"] || (len(term) > 3 && words[strings.TrimSuffix(term, "s")])
+}
+
+// percentOf returns n/total as a rounded percentage, or 0 for an empty total.
+func percentOf(n, total int) int {
+	if total == 0 {
+		return 0
+	}
+	return (200*n + total) / (2 * total)
+}
+
+// CheckATS runs the ATS readiness check of resume against the parsed JD:
+// keyword coverage of required and preferred terms, missing standard
+// sections, characters and layouts that parsers mangle, contact details
+// outside the header, and inconsistent date formats.
+func CheckATS(nodes []JobDescriptionNode, resume string) *ATSReport {
+	rep := &ATSReport{Keywords: []ATSKeyword{}, Issues: []ATSIssue{}}
+
+	words := keywords(resume)
+	required, preferred := atsTerms(nodes)
+	var reqFound, prefFound int
+	for _, t := range required {
+		found := atsHas(words, t)
+		if found {
+			reqFound++
+		}
+		rep.Keywords = append(rep.Keywords, ATSKeyword{Term: t, Found: found})
+	}
+	for _, t := range preferred {
+		found := atsHas(words, t)
+		if found {
+			prefFound++
+		}
+		rep.Keywords = append(rep.Keywords, ATSKeyword{Term: t, Preferred: true, Found: found})
+	}
+	rep.RequiredCoverage = percentOf(reqFound, len(required))
+	rep.PreferredCoverage = percentOf(prefFound, len(preferred))
+	rep.Coverage = percentOf(reqFound+prefFound, len(required)+len(preferred))
+
+	rep.Issues = append(rep.Issues, atsSectionIssues(resume)...)
+	rep.Issues = append(rep.Issues, atsCharacterIssues(resume)...)
+	rep.Issues = append(rep.Issues, atsTableIssues(resume)...)
+	rep.Issues = append(rep.Issues, atsContactIssues(resume)...)
+	rep.Issues = append(rep.Issues, atsDateIssues(resume)...)
+	return rep
+}
+
+func atsSectionIssues(resume string) []ATSIssue {
+	var headings []string
+	for _, b := range parseDocument(resume) {
+		if b.Kind == blockHeading {
+			headings = append(headings, b.Text)
+		}
+	}
+	var out []ATSIssue
+	for _, s := range atsSections {
+		found := false
+		for _, h := range headings {
+			if s.Re.MatchString(h) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			out = append(out, ATSIssue{Kind: ATSMissingSection, Message: fmt.Sprintf("no %q section heading", s.Name)})
+		}
+	}
+	return out
+}
+
+// atsRiskyRune reports whether r is likely to be dropped or garbled by an
+// ATS parser: symbols and emoji, private-use icon glyphs, invisible format
+// characters, and typographic ligatures.
+func atsRiskyRune(r rune) bool {
+	switch {
+	case r < 0x80, r == '•':
+		return false
+	case r >= 0xFB00 && r <= 0xFB06:
+		return true
+	}
+	return unicode.In(r, unicode.So, unicode.Co, unicode.Cf)
+}
+
+func atsCharacterIssues(resume string) []ATSIssue {
+	var out []ATSIssue
+	seen := map[rune]bool{}
+	for _, line := range strings.Split(resume, "\n") {
+		line = strings.TrimSpace(line)
+		if m := atsBulletMarkRe.FindStringSubmatch(line); m != nil {
+			r := []rune(m[1])[0]
+			if r >= 0x80 && r != '•' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && !seen[r] {
+				seen[r] = true
+				out = append(out, ATSIssue{Kind: ATSCharacter, Message: fmt.Sprintf("non-standard bullet %q (U+%04X); use • or -", r, r), Line: line})
+				continue
+			}
+		}
+		for _, r := range line {
+			if atsRiskyRune(r) && !seen[r] {
+				seen[r] = true
+				out = append(out, ATSIssue{Kind: ATSCharacter, Message: fmt.Sprintf("character %q (U+%04X) may be dropped or garbled", r, r), Line: line})
+			}
+		}
+	}
+	return out
+}
+
+func atsTableIssues(resume string) []ATSIssue {
+	var out []ATSIssue
+	var table, columns, boxes bool
+	for _, line := range strings.Split(resume, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case !table && (atsTableRuleRe.MatchString(trimmed) || strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|") && len(trimmed) > 1):
+			table = true
+			out = append(out, ATSIssue{Kind: ATSTable, Message: "table layout; ATSs often read cells out of order", Line: trimmed})
+		case !boxes && strings.ContainsFunc(trimmed, func(r rune) bool { return r >= 0x2500 && r <= 0x257F }):
+			boxes = true
+			out = append(out, ATSIssue{Kind: ATSTable, Message: "box-drawing characters suggest a table or frame", Line: trimmed})
+		case !columns && atsColumnRe.MatchString(trimmed):
+			columns = true
+			out = append(out, ATSIssue{Kind: ATSTable, Message: "text aligned in columns with tabs or runs of spaces", Line: trimmed})
+		}
+	}
+	return out
+}
+
+func atsContactIssues(resume string) []ATSIssue {
+	var header []string
+	for _, line := range strings.Split(resume, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			header = append(header, line)
+			if len(header) == atsHeaderLines {
+				break
+			}
+		}
+	}
+	top := strings.Join(header, "\n")
+	var out []ATSIssue
+	for _, c := range []struct {
+		name string
+		find func(string) string
+	}{{"email address", atsEmailRe.FindString}, {"phone number", atsPhone}} {
+		if c.find(top) != "" {
+			continue
+		}
+		msg := fmt.Sprintf("no %s in the first %d lines", c.name, atsHeaderLines)
+		if m := c.find(resume); m != "" {
+			msg = fmt.Sprintf("%s %q is not in the header at the top", c.name, m)
+		}
+		out = append(out, ATSIssue{Kind: ATSContact, Message: msg})
+	}
+	return out
+}
+
+// atsPhone returns the first phone number in s, skipping digit runs that
+// are date ranges.
+func atsPhone(s string) string {
+	for _, m := range atsPhoneRe.FindAllString(s, -1) {
+		if !atsYearRangeRe.MatchString(m) && !atsIsoMonthRe.MatchString(m) && !numMonthRe.MatchString(m) {
+			return m
+		}
+	}
+	return ""
+}
+
+// atsDateStyles classifies the dates on line: "Jan 2020", "January 2020",
+// "2020-01", "01/2020", or a bare year range "2019 - 2021".
+func atsDateStyles(line string) []string {
+	var styles []string
+	for _, m := range atsMonthNameRe.FindAllStringSubmatch(line, -1) {
+		switch {
+		case strings.EqualFold(m[1], "may"):
+			// "May" is both the abbreviation and the full name.
+		case m[2] == "":
+			styles = append(styles, "Jan 2020")
+		default:
+			styles = append(styles, "January 2020")
+		}
+	}
+	if atsIsoMonthRe.MatchString(line) {
+		styles = append(styles, "2020-01")
+	}
+	if numMonthRe.MatchString(line) {
+		styles = append(styles, "01/2020")
+	}
+	if len(styles) == 0 && atsYearRangeRe.MatchString(line) {
+		styles = append(styles, "2020")
+	}
+	return styles
+}
+
+func atsDateIssues(resume string) []ATSIssue {
+	first := map[string]string{}
+	var order []string
+	for _, line := range strings.Split(resume, "\n") {
+		line = strings.TrimSpace(line)
+		for _, s := range atsDateStyles(line) {
+			if _, ok := first[s]; !ok {
+				first[s] = line
+				order = append(order, s)
+			}
+		}
+	}
+	if len(order) < 2 {
+		return nil
+	}
+	var out []ATSIssue
+	for _, s := range order[1:] {
+		out = append(out, ATSIssue{
+			Kind:    ATSDateFormat,
+			Message: fmt.Sprintf("date format %q differs from %q used earlier", s, order[0]),
+			Line:    first[s],
+		})
+	}
+	return out
+}
+
+// CheckJobATS runs CheckATS on a job's current resume against its stored
+// JD, saves the report as ats.json, and records the coverage in meta.json.
+func (a *App) CheckJobATS(id string) (*ATSReport, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	resume, err := os.ReadFile(filepath.Join(dir, targetFiles[TargetResume]))
+	if err != nil {
+		return nil, fmt.Errorf("read resume: %w", err)
+	}
+	jd, err := os.ReadFile(filepath.Join(dir, jdFile))
+	if err != nil {
+		return nil, fmt.Errorf("read job description: %w", err)
+	}
+	rep := CheckATS(Parse(string(jd)), string(resume))
+	if err := SaveJSON(filepath.Join(dir, atsFile), rep, 0644); err != nil {
+		return nil, fmt.Errorf("write ats check: %w", err)
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return nil, fmt.Errorf("read meta: %w", err)
+	}
+	meta.ATSCoverage = &rep.Coverage
+	if err := a.Jobs.WriteMeta(id, meta); err != nil {
+		return nil, fmt.Errorf("write meta: %w", err)
+	}
+	return rep, nil
+}
+
+// refreshATS re-runs the ATS check after a job's resume changed, downgrading
+// failures to a stderr warning — ats.json is derived and must never fail the
+// save. A job without a stored JD has its stale ats.json and coverage cleared
+// instead, so it no longer sorts by its old resume.
+func (a *App) refreshATS(id string) {
+	var err error
+	if _, statErr := os.Stat(filepath.Join(a.Paths.Jobs, id, jdFile)); errors.Is(statErr, os.ErrNotExist) {
+		err = a.clearATS(id)
+	} else {
+		_, err = a.CheckJobATS(id)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: ats check for %s: %v\n", id, err)
+	}
+}
+
+// clearATS removes a job's ats.json and the coverage recorded in meta.json.
+func (a *App) clearATS(id string) error {
+	if err := os.Remove(filepath.Join(a.Paths.Jobs, id, atsFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil || meta.ATSCoverage == nil {
+		return err
+	}
+	meta.ATSCoverage = nil
+	return a.Jobs.WriteMeta(id, meta)
+}
+
+// GetATSReport reads a job's ats.json.
+func GetATSReport(a *App, id string) (*ATSReport, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	return LoadJSON[ATSReport](filepath.Join(a.Paths.Jobs, id, atsFile))
+}
@@ -0,0 +1,166 @@
+package jdextract
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const atsJD = `# Senior Backend Engineer
+
+## About us
+We build payments infrastructure.
+
+## Requirements
+- Go and PostgreSQL in production
+- Kubernetes
+
+## Nice to have
+- Kafka
+- Terraform is a plus`
+
+const atsResume = `Jane Doe
+jane@example.com | +1 555 123 4567
+
+EXPERIENCE
+Backend Engineer | Acme | Jan 2020 - Present
+• Built services in Go on PostgreSQL
+• Ran Kafka consumers
+
+EDUCATION
+BSc Computer Science | State University | 2012 - 2016`
+
+func TestATSTerms(t *testing.T) {
+	required, preferred := atsTerms(Parse(atsJD))
+	if !slices.Equal(required, []string{"go", "kubernetes", "postgresql", "production"}) {
+		t.Errorf("required = %v", required)
+	}
+	if !slices.Equal(preferred, []string{"kafka", "terraform"}) {
+		t.Errorf("preferred = %v", preferred)
+	}
+
+	// Without requirement sections, all bullets count as required.
+	required, preferred = atsTerms(Parse("Join us\n- Kotlin services\n- Gradle"))
+	if !slices.Equal(required, []string{"gradle", "kotlin", "services"}) || len(preferred) != 0 {
+		t.Errorf("fallback = %v, %v", required, preferred)
+	}
+}
+
+func TestCheckATS(t *testing.T) {
+	rep := CheckATS(Parse(atsJD), atsResume)
+	if rep.RequiredCoverage != 50 || rep.PreferredCoverage != 50 || rep.Coverage != 50 {
+		t.Errorf("coverage = %d (required %d, preferred %d)", rep.Coverage, rep.RequiredCoverage, rep.PreferredCoverage)
+	}
+	kinds := func(r *ATSReport) []string {
+		var out []string
+		for _, is := range r.Issues {
+			out = append(out, is.Kind+": "+is.Message)
+		}
+		return out
+	}
+	if got := kinds(rep); !slices.Equal(got, []string{
+		`missing_section: no "Skills" section heading`,
+		`date_format: date format "2020" differs from "Jan 2020" used earlier`,
+	}) {
+		t.Errorf("issues = %q", got)
+	}
+
+	messy := "Jane Doe\nPortland, OR\n\nSUMMARY\nBackend engineer.\n\nEXPERIENCE\n➢ Built services\nAcme ★ 03/2019 - 2020-06\nGo        Kafka\n| Skill | Years |\n\nSKILLS\nEducation: none\nContact: jane@example.com"
+	got := kinds(CheckATS(nil, messy))
+	for _, want := range []string{
+		`missing_section: no "Education" section heading`,
+		"character: non-standard bullet '➢' (U+27A2); use • or -",
+		"character: character '★' (U+2605) may be dropped or garbled",
+		"table: text aligned in columns with tabs or runs of spaces",
+		"table: table layout; ATSs often read cells out of order",
+		`contact: email address "jane@example.com" is not in the header at the top`,
+		"contact: no phone number in the first 6 lines",
+		`date_format: date format "01/2020" differs from "2020-01" used earlier`,
+	} {
+		if !slices.Contains(got, want) {
+			t.Errorf("missing issue %q in %q", want, got)
+		}
+	}
+}
+
+func TestCheckJobATS(t *testing.T) {
+	a := newTestApp(t)
+	id, err := a.Jobs.MkDir("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Jobs.WriteMeta(id, &ApplicationMeta{Company: "Acme"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	for name, content := range map[string]string{jdFile: atsJD, "resume.txt": atsResume} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := a.CheckJobATS(id); err != nil {
+		t.Fatalf("CheckJobATS: %v", err)
+	}
+	meta, _ := a.Jobs.ReadMeta(id)
+	if meta.ATSCoverage == nil || *meta.ATSCoverage != 50 {
+		t.Errorf("meta coverage = %v", meta.ATSCoverage)
+	}
+	if rep, err := GetATSReport(a, id); err != nil || rep.Coverage != 50 {
+		t.Errorf("ats.json = %+v, %v", rep, err)
+	}
+}
+
+func TestSaveRevisionRefreshesATS(t *testing.T) {
+	a := newTestApp(t)
+	stale := 100
+	for _, id := range []string{"2026-10-18-acme", "2026-10-18-nojd"} {
+		if err := os.MkdirAll(filepath.Join(a.Paths.Jobs, id), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Jobs.WriteMeta(id, &ApplicationMeta{Company: "Acme", ATSCoverage: &stale}); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(a.Paths.Jobs, id, atsFile), []byte(`{"coverage":100}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(a.Paths.Jobs, "2026-10-18-acme", jdFile), []byte(atsJD), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := SaveRevision(a, "2026-10-18-acme", TargetResume, atsResume); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ := a.Jobs.ReadMeta("2026-10-18-acme")
+	if meta.ATSCoverage == nil || *meta.ATSCoverage != 50 {
+		t.Errorf("meta coverage = %v, want 50", meta.ATSCoverage)
+	}
+	if rep, err := GetATSReport(a, "2026-10-18-acme"); err != nil || rep.Coverage != 50 {
+		t.Errorf("ats.json = %+v, %v", rep, err)
+	}
+
+	// Without a stored JD the check cannot run, so the stale result is dropped.
+	if _, err := SaveRevision(a, "2026-10-18-nojd", TargetResume, atsResume); err != nil {
+		t.Fatal(err)
+	}
+	meta, _ = a.Jobs.ReadMeta("2026-10-18-nojd")
+	if meta.ATSCoverage != nil {
+		t.Errorf("meta coverage = %v, want cleared", *meta.ATSCoverage)
+	}
+	if _, err := GetATSReport(a, "2026-10-18-nojd"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("ats.json err = %v, want not exist", err)
+	}
+
+	// A failed check is derived data and must not fail the save itself.
+	if err := os.Remove(filepath.Join(a.Paths.Jobs, "2026-10-18-acme", "meta.json")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := SaveRevision(a, "2026-10-18-acme", TargetResume, "v3"); err != nil {
+		t.Fatalf("SaveRevision with failing ATS check: %v", err)
+	}
+	if cur, _ := os.ReadFile(filepath.Join(a.Paths.Jobs, "2026-10-18-acme", "resume.txt")); string(cur) != "v3" {
+		t.Errorf("resume.txt = %q, want v3", cur)
+	}
+}
@@ -0,0 +1,235 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaskChat is the Config.Tasks key for the per-job chat.
+const TaskChat = "chat"
+
+// chatFile holds a job's chat thread.
+const chatFile = "chat.json"
+
+const chatPrompt = `You are helping the candidate refine their application for the job below, in an ongoing conversation. Answer questions, explain choices, and suggest changes. Use only facts present in the base resume or the current documents; never invent employers, titles, dates, metrics, or skills.
+
+When you propose a rewritten resume or cover letter, include the complete document (not a fragment or a diff) inside <resume></resume> or <cover></cover> tags so it can be applied as-is. Keep any commentary outside the tags.`
+
+// ChatMessage is one turn of a job's chat thread.
+type ChatMessage struct {
+	Role    string   `json:"role"` // "user" or "assistant"
+	Content string   `json:"content"`
+	Time    string   `json:"time"`              // RFC3339
+	Blocks  []string `json:"blocks,omitempty"`  // targets with a complete document block
+	Applied []string `json:"applied,omitempty"` // targets applied as a revision
+}
+
+// ChatInput is the context and history for the next chat reply.
+type ChatInput struct {
+	JobDescription string // raw JD text; may be empty for jobs predating jd.txt
+	BaseResume     string
+	Resume         string // current tailored resume
+	Cover          string // current cover letter; may be empty
+	History        []ChatMessage
+}
+
+// chatBlocks returns the targets for which content has a non-empty
+// document block, in targetFiles order.
+func chatBlocks(content string) []string {
+	var out []string
+	if extractTag(resumeTagRe, content) != "" {
+		out = append(out, TargetResume)
+	}
+	if extractTag(coverTagRe, content) != "" {
+		out = append(out, TargetCover)
+	}
+	return out
+}
+
+// ChatReply sends the thread in in.History, whose last message is the
+// user's, to the LLM with the job's documents as context and returns the
+// assistant's reply. Streaming and reasoning behave as in GenerateAll.
+func ChatReply(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	systemPrompt string,
+	in ChatInput,
+	onDelta func(string),
+	onReasoning func(string),
+) (string, error) {
+	if len(in.History) == 0 || in.History[len(in.History)-1].Role != "user" {
+		return "", fmt.Errorf("chat history must end with a user message")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(systemPrompt + "\n\n" + chatPrompt))
+	if in.JobDescription != "" {
+		fmt.Fprintf(&sb, "\n\nJOB DESCRIPTION:\n%s", Sanitize(in.JobDescription))
+	}
+	if in.BaseResume != "" {
+		fmt.Fprintf(&sb, "\n\nBASE RESUME:\n%s", Sanitize(in.BaseResume))
+	}
+	fmt.Fprintf(&sb, "\n\nCURRENT RESUME:\n%s", Sanitize(in.Resume))
+	if in.Cover != "" {
+		fmt.Fprintf(&sb, "\n\nCURRENT COVER LETTER:\n%s", Sanitize(in.Cover))
+	}
+
+	messages := []deepseekMessage{{Role: "system", Content: sb.String()}}
+	for _, m := range in.History {
+		messages = append(messages, deepseekMessage{Role: m.Role, Content: m.Content})
+	}
+	content, _, _, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, onReasoning)
+	if err != nil {
+		return "", err
+	}
+	content, _ = repairTagged(content, []string{TargetResume, TargetCover})
+	return strings.TrimSpace(content), nil
+}
+
+// GetChat reads a job's chat.json; a job without a chat has no messages.
+func GetChat(a *App, id string) ([]ChatMessage, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	msgs, err := LoadJSON[[]ChatMessage](filepath.Join(a.Paths.Jobs, id, chatFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []ChatMessage{}, nil
+		}
+		return nil, err
+	}
+	return *msgs, nil
+}
+
+// ClearChat deletes a job's chat thread.
+func ClearChat(a *App, id string) error {
+	if !ValidID(id) {
+		return fmt.Errorf("invalid job id %q", id)
+	}
+	a.chatMu.Lock()
+	defer a.chatMu.Unlock()
+	err := os.Remove(filepath.Join(a.Paths.Jobs, id, chatFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ChatJob adds message to a job's chat thread and streams the assistant's
+// reply to onProgress. The context is re-read on every turn: the stored JD,
+// the job's base template, and the current resume and cover letter, so a
+// reply always sees applied revisions. Both messages are appended to
+// chat.json as re-read after the reply, so changes made while it streamed
+// are kept.
+func (a *App) ChatJob(ctx context.Context, id, message string, onProgress func(ProgressEvent)) (*ChatMessage, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return nil, fmt.Errorf("read meta: %w", err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	resume, err := os.ReadFile(filepath.Join(dir, targetFiles[TargetResume]))
+	if err != nil {
+		return nil, fmt.Errorf("read resume: %w", err)
+	}
+	in := ChatInput{Resume: string(resume)}
+	if cover, err := os.ReadFile(filepath.Join(dir, targetFiles[TargetCover])); err == nil {
+		in.Cover = string(cover)
+	}
+	if jd, err := os.ReadFile(filepath.Join(dir, jdFile)); err == nil {
+		in.JobDescription = string(jd)
+	}
+	if base, err := LoadTemplates(a, meta.Template); err == nil {
+		in.BaseResume = base.Resume
+	}
+	in.History, err = GetChat(a, id)
+	if err != nil {
+		return nil, fmt.Errorf("read chat: %w", err)
+	}
+	in.History = append(in.History, ChatMessage{Role: "user", Content: message, Time: time.Now().UTC().Format(time.RFC3339)})
+
+	data, err := tailorPromptData(Parse(in.JobDescription), in.BaseResume, nil)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := a.PromptConfig.render(data)
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+
+	b := a.BackendFor(TaskChat, onProgress)
+	onProgress(ProgressEvent{Stage: StageGenerating, Message: "Thinking\u2026"})
+	reply, err := ChatReply(ctx, b.Invoker, b.StreamInvoker, b.APIKey, b.Params, &a.Client, prompt.SystemPrompt, in,
+		func(d string) { onProgress(ProgressEvent{Stage: StageContent, Delta: d}) },
+		func(d string) { onProgress(ProgressEvent{Stage: StageReasoning, Delta: d}) },
+	)
+	flushTranscripts(b.Transcript, dir)
+	if err != nil {
+		return nil, fmt.Errorf("chat: %w", err)
+	}
+
+	msg := ChatMessage{Role: "assistant", Content: reply, Time: time.Now().UTC().Format(time.RFC3339), Blocks: chatBlocks(reply)}
+	a.chatMu.Lock()
+	defer a.chatMu.Unlock()
+	thread, err := GetChat(a, id)
+	if err != nil {
+		return nil, fmt.Errorf("read chat: %w", err)
+	}
+	thread = append(thread, in.History[len(in.History)-1], msg)
+	if err := SaveJSON(filepath.Join(dir, chatFile), thread, 0644); err != nil {
+		return nil, fmt.Errorf("write chat: %w", err)
+	}
+	return &msg, nil
+}
+
+// ApplyChatBlock saves the target document block of assistant message index
+// as a new revision of that file and marks it applied in the thread.
+func ApplyChatBlock(a *App, id string, index int, target string) error {
+	re := resumeTagRe
+	switch target {
+	case TargetResume:
+	case TargetCover:
+		re = coverTagRe
+	default:
+		return fmt.Errorf("invalid target %q: must be resume or cover", target)
+	}
+	a.chatMu.Lock()
+	defer a.chatMu.Unlock()
+	msgs, err := GetChat(a, id)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(msgs) || msgs[index].Role != "assistant" {
+		return fmt.Errorf("chat message %d: %w", index, os.ErrNotExist)
+	}
+	block := extractTag(re, msgs[index].Content)
+	if block == "" {
+		return fmt.Errorf("chat message %d has no <%s> block: %w", index, target, os.ErrNotExist)
+	}
+	if _, err := SaveRevision(a, id, target, block); err != nil {
+		return err
+	}
+	if !slices.Contains(msgs[index].Applied, target) {
+		msgs[index].Applied = append(msgs[index].Applied, target)
+	}
+	if err := SaveJSON(filepath.Join(a.Paths.Jobs, id, chatFile), msgs, 0644); err != nil {
+		return fmt.Errorf("write chat: %w", err)
+	}
+	return nil
+}
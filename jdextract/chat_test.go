@@ -0,0 +1,159 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestChatReply(t *testing.T) {
+	var sent deepseekRequest
+	invoker := func(_ context.Context, _ string, _ *http.Client, _ int, body json.RawMessage) (string, error) {
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Fatal(err)
+		}
+		return completionBody("Tightened the summary.\n<Resume>\nJane Doe\nShorter.\n</Resume>", "")
+	}
+	in := ChatInput{
+		JobDescription: "Senior Copywriter at Acme",
+		Resume:         "Jane Doe\nLong.",
+		History: []ChatMessage{
+			{Role: "user", Content: "Why this summary?"},
+			{Role: "assistant", Content: "It leads with B2B."},
+			{Role: "user", Content: "Make it shorter."},
+		},
+	}
+	reply, err := ChatReply(context.Background(), invoker, nil, "", TaskParams{Model: "m"}, nil, "", in, nil, nil)
+	if err != nil {
+		t.Fatalf("ChatReply: %v", err)
+	}
+	if got := chatBlocks(reply); !slices.Equal(got, []string{TargetResume}) {
+		t.Errorf("blocks = %v in %q", got, reply)
+	}
+	if len(sent.Messages) != 4 || sent.Messages[3].Content != "Make it shorter." || sent.Messages[2].Role != "assistant" {
+		t.Fatalf("messages = %+v", sent.Messages)
+	}
+	for _, want := range []string{"Senior Copywriter at Acme", "CURRENT RESUME:\nJane Doe\nLong."} {
+		if !strings.Contains(sent.Messages[0].Content, want) {
+			t.Errorf("system prompt missing %q", want)
+		}
+	}
+
+	in.History = in.History[:2]
+	if _, err := ChatReply(context.Background(), invoker, nil, "", TaskParams{}, nil, "", in, nil, nil); err == nil {
+		t.Error("history ending with assistant: want error")
+	}
+}
+
+func TestApplyChatBlock(t *testing.T) {
+	a := newTestApp(t)
+	id := "2026-10-18-acme"
+	dir := filepath.Join(a.Paths.Jobs, id)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "resume.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	msgs := []ChatMessage{
+		{Role: "user", Content: "Shorter please."},
+		{Role: "assistant", Content: "Here:\n<resume>\nv2\n</resume>", Blocks: []string{TargetResume}},
+	}
+	if err := SaveJSON(filepath.Join(dir, chatFile), msgs, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ApplyChatBlock(a, id, 1, TargetResume); err != nil {
+		t.Fatalf("ApplyChatBlock: %v", err)
+	}
+	if cur, _ := os.ReadFile(filepath.Join(dir, "resume.txt")); string(cur) != "v2" {
+		t.Errorf("resume.txt = %q, want v2", cur)
+	}
+	if revs, _ := ListRevisions(a, id); len(revs) != 1 {
+		t.Errorf("revisions = %v", revs)
+	}
+	got, err := GetChat(a, id)
+	if err != nil || !slices.Equal(got[1].Applied, []string{TargetResume}) {
+		t.Errorf("applied = %+v, %v", got, err)
+	}
+
+	for _, c := range []struct {
+		index  int
+		target string
+	}{{0, TargetResume}, {1, TargetCover}, {5, TargetResume}} {
+		if err := ApplyChatBlock(a, id, c.index, c.target); err == nil {
+			t.Errorf("apply %d/%s: want error", c.index, c.target)
+		}
+	}
+
+	if err := ClearChat(a, id); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := GetChat(a, id); len(got) != 0 {
+		t.Errorf("after clear = %+v", got)
+	}
+}
+
+// sseTransport answers every request with reply as a one-delta stream.
+type sseTransport string
+
+func (s sseTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	delta, _ := json.Marshal(string(s))
+	body := fmt.Sprintf("data: {\"choices\":[{\"delta\":{\"content\":%s}}]}\n\ndata: [DONE]\n\n", delta)
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(body))}, nil
+}
+
+func TestChatJobKeepsConcurrentApply(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.LLMMode = LLMModeLive
+	a.Client = http.Client{Transport: sseTransport("Anything else?")}
+	id, err := a.Jobs.MkDir("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Jobs.WriteMeta(id, &ApplicationMeta{Company: "Acme"}); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	if err := os.WriteFile(filepath.Join(dir, "resume.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	msgs := []ChatMessage{
+		{Role: "user", Content: "Shorter please."},
+		{Role: "assistant", Content: "Here:\n<resume>\nv2\n</resume>", Blocks: []string{TargetResume}},
+	}
+	if err := SaveJSON(filepath.Join(dir, chatFile), msgs, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Apply the earlier block while the next reply is streaming.
+	applied := false
+	_, err = a.ChatJob(context.Background(), id, "Thanks!", func(e ProgressEvent) {
+		if e.Stage == StageContent && !applied {
+			applied = true
+			if err := ApplyChatBlock(a, id, 1, TargetResume); err != nil {
+				t.Errorf("ApplyChatBlock: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		t.Fatalf("ChatJob: %v", err)
+	}
+	got, err := GetChat(a, id)
+	if err != nil || len(got) != 4 {
+		t.Fatalf("thread = %+v, %v", got, err)
+	}
+	if !slices.Equal(got[1].Applied, []string{TargetResume}) {
+		t.Errorf("apply made during the reply was lost: %+v", got[1])
+	}
+	if got[2].Content != "Thanks!" || got[3].Content != "Anything else?" {
+		t.Errorf("new messages = %+v", got[2:])
+	}
+}
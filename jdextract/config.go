@@ -15,17 +15,56 @@ type Config struct {
 	KimiModel      string `json:"kimi_model"`
 	Backend        string `json:"backend"` // "deepseek" (default) or "kimi"
 	Port           int    `json:"port"`
+
+	DeepSeekLimits RateLimitConfig `json:"deepseek_limits"`
+	KimiLimits     RateLimitConfig `json:"kimi_limits"`
+
+	Pipeline  bool   `json:"pipeline,omitempty"`   // multi-pass analyze → tailor → critique → revise generation
+	VerifyLLM bool   `json:"verify_llm,omitempty"` // LLM second opinion on fabrication-check findings
+	PDFLayout string `json:"pdf_layout,omitempty"` // default PDF export preset; see PDFLayouts
+
+	// Constraints limits tailored resume length; violations trigger a
+	// shortening pass after generation. See ResumeConstraints.
+	Constraints ResumeConstraints `json:"constraints"`
+
+	// Experiment assigns prompt profiles to new jobs; see ExperimentConfig.
+	Experiment ExperimentConfig `json:"experiment"`
+
+	// Languages sets the output policy per posting language code, e.g.
+	// "de"; languages without an entry are answered in English.
+	Languages map[string]LanguageConfig `json:"languages,omitempty"`
+
+	// CoverTone is the default cover letter tone preset; see CoverTones.
+	CoverTone string `json:"cover_tone,omitempty"`
+
+	Transcripts   TranscriptConfig `json:"transcripts"`
+	SaveReasoning bool             `json:"save_reasoning,omitempty"` // write reasoning.txt alongside the job for reasoning models
+
+	// Tasks overrides backend, model, and sampling parameters per LLM task,
+	// keyed by task name ("tailor", "followup", "summarize", ...).
+	Tasks map[string]TaskParams `json:"tasks,omitempty"`
+
+	LLMMode       string `json:"llm_mode,omitempty"`       // "" (live), "record", or "replay"; JDEXTRACT_LLM_MODE overrides
+	RecordingsDir string `json:"recordings_dir,omitempty"` // defaults to data/recordings; JDEXTRACT_RECORDINGS overrides
 }
 
+// PromptConfig holds the prompts for resume and cover letter generation. Each
+// field is a text/template rendered with PromptData; see PromptData for the
+// available variables.
 type PromptConfig struct {
 	TaskList     string `json:"task_list"`
 	SystemPrompt string `json:"system_prompt"`
+	User         string `json:"user,omitempty"`    // user message; empty sends the default layout
+	Profile      string `json:"profile,omitempty"` // free-form candidate notes, available as .Profile
 }
 
 // NetworkingPromptConfig holds prompts for AI follow-up message generation.
+// Fields are templates as in PromptConfig.
 type NetworkingPromptConfig struct {
 	SystemPrompt string `json:"system_prompt"`
 	TaskList     string `json:"task_list"`
+	User         string `json:"user,omitempty"`
+	Profile      string `json:"profile,omitempty"`
 }
 
 // LoadJSON reads path and unmarshals into a new T.
@@ -95,5 +134,10 @@ func CreateEmptyConfig(path string) error {
 		KimiModel:      "moonshotai/Kimi-K2.5",
 		Backend:        "deepseek",
 		Port:           8080,
+		DeepSeekLimits: RateLimitConfig{MaxInFlight: defaultMaxInFlight},
+		KimiLimits:     RateLimitConfig{MaxInFlight: defaultMaxInFlight},
+		Tasks: map[string]TaskParams{
+			TaskSummarize: {MaxTokens: 256},
+		},
 	}, 0600)
 }
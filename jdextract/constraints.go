@@ -0,0 +1,349 @@
+package jdextract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// TaskShorten is the Config.Tasks key for the constraint shortening pass.
+const TaskShorten = "shorten"
+
+// constraintsFile is the per-job constraint report written by Process.
+const constraintsFile = "constraints.json"
+
+// maxShortenPasses bounds the shortening passes per generation.
+const maxShortenPasses = 2
+
+// Constraint rules, as reported in Violation.Rule.
+const (
+	RuleMaxLines          = "max_lines"
+	RuleMaxBulletChars    = "max_bullet_chars"
+	RuleMaxBulletsPerRole = "max_bullets_per_role"
+	RuleTargetPages       = "target_pages"
+)
+
+// ResumeConstraints limits the length of tailored resumes. Zero values are
+// not checked.
+type ResumeConstraints struct {
+	MaxLines          int `json:"max_lines,omitempty"`            // non-blank lines
+	MaxBulletChars    int `json:"max_bullet_chars,omitempty"`     // characters per bullet, wrapped lines joined
+	MaxBulletsPerRole int `json:"max_bullets_per_role,omitempty"` // bullets under each "Title | Company | Dates" line
+	TargetPages       int `json:"target_pages,omitempty"`         // pages in the PDF export with the configured layout
+}
+
+// IsZero reports whether no constraint is set.
+func (c ResumeConstraints) IsZero() bool {
+	return c == ResumeConstraints{}
+}
+
+// validate rejects negative limits.
+func (c ResumeConstraints) validate() error {
+	if c.MaxLines < 0 || c.MaxBulletChars < 0 || c.MaxBulletsPerRole < 0 || c.TargetPages < 0 {
+		return fmt.Errorf("values must be zero or positive")
+	}
+	return nil
+}
+
+// Violation is one broken constraint.
+type Violation struct {
+	Rule   string `json:"rule"`
+	Limit  int    `json:"limit"`
+	Actual int    `json:"actual"`
+	Text   string `json:"text,omitempty"` // the bullet or role line, for per-item rules
+}
+
+// String renders v as an instruction-style sentence for the shortening
+// prompt and the UI.
+func (v Violation) String() string {
+	switch v.Rule {
+	case RuleMaxLines:
+		return fmt.Sprintf("The resume has %d non-blank lines; the limit is %d.", v.Actual, v.Limit)
+	case RuleMaxBulletChars:
+		return fmt.Sprintf("This bullet has %d characters; the limit is %d: %q", v.Actual, v.Limit, v.Text)
+	case RuleMaxBulletsPerRole:
+		return fmt.Sprintf("The role %q has %d bullets; the limit is %d.", v.Text, v.Actual, v.Limit)
+	case RuleTargetPages:
+		return fmt.Sprintf("The resume fills %d pages in the PDF export; the target is %d.", v.Actual, v.Limit)
+	}
+	return fmt.Sprintf("%s: %d (limit %d)", v.Rule, v.Actual, v.Limit)
+}
+
+// ConstraintReport is the constraint check stored as constraints.json.
+type ConstraintReport struct {
+	Constraints ResumeConstraints `json:"constraints"`
+	Initial     []Violation       `json:"initial"`           // violations in the generated resume
+	Violations  []Violation       `json:"violations"`        // violations remaining in the saved resume
+	Passes      int               `json:"passes"`            // shortening passes run
+	Dropped     []string          `json:"dropped,omitempty"` // profile entry IDs removed to fit, for profile resumes
+	Tokens      int               `json:"tokens,omitempty"`
+	Lines       int               `json:"lines"`           // non-blank lines in the saved resume
+	Pages       int               `json:"pages,omitempty"` // PDF pages, when TargetPages is set
+	Error       string            `json:"error,omitempty"` // a failed shortening pass; the previous text is kept
+}
+
+// CheckConstraints measures resume against c and returns the violations,
+// with the non-blank line count and, when c.TargetPages is set, the page
+// count in layout. Roles are the "Title | Company | Dates" lines that
+// parseDocument reads as subheadings; bullets before the first one are not
+// counted against any role.
+func CheckConstraints(resume string, c ResumeConstraints, layout PDFLayout) (violations []Violation, lines, pages int) {
+	for _, l := range strings.Split(resume, "\n") {
+		if strings.TrimSpace(l) != "" {
+			lines++
+		}
+	}
+	if c.MaxLines > 0 && lines > c.MaxLines {
+		violations = append(violations, Violation{Rule: RuleMaxLines, Limit: c.MaxLines, Actual: lines})
+	}
+
+	blocks := parseDocument(resume)
+	role, bullets := "", 0
+	endRole := func() {
+		if c.MaxBulletsPerRole > 0 && role != "" && bullets > c.MaxBulletsPerRole {
+			violations = append(violations, Violation{Rule: RuleMaxBulletsPerRole, Limit: c.MaxBulletsPerRole, Actual: bullets, Text: role})
+		}
+		role, bullets = "", 0
+	}
+	for _, b := range blocks {
+		switch b.Kind {
+		case blockSubheading:
+			endRole()
+			role = b.Text
+		case blockHeading:
+			endRole()
+		case blockBullet:
+			bullets++
+			if n := utf8.RuneCountInString(b.Text); c.MaxBulletChars > 0 && n > c.MaxBulletChars {
+				violations = append(violations, Violation{Rule: RuleMaxBulletChars, Limit: c.MaxBulletChars, Actual: n, Text: b.Text})
+			}
+		}
+	}
+	endRole()
+
+	if c.TargetPages > 0 {
+		// The page count only depends on the layout, so the rendered PDF
+		// is discarded.
+		if n, err := writePDF(io.Discard, blocks, layout); err == nil {
+			pages = n
+			if pages > c.TargetPages {
+				violations = append(violations, Violation{Rule: RuleTargetPages, Limit: c.TargetPages, Actual: pages})
+			}
+		}
+	}
+	return violations, lines, pages
+}
+
+const shortenPrompt = `You are shortening a tailored resume to fit length limits. Fix every listed problem and change nothing else: keep the layout, the section order, and every role line as written. Shorten by tightening wording and by removing the least relevant bullets for the job; never merge roles or add new facts, numbers, or skills.
+
+Respond with the complete shortened resume inside <resume></resume> tags and nothing else.`
+
+// ShortenResume asks the LLM to fix violations in resume and returns the
+// shortened resume and the tokens used. A response missing the <resume> tag
+// gets one repair turn.
+func ShortenResume(
+	ctx context.Context,
+	invoker LLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	jobDescription string,
+	resume string,
+	violations []Violation,
+) (string, int, error) {
+	var sb strings.Builder
+	if jobDescription != "" {
+		fmt.Fprintf(&sb, "JOB DESCRIPTION:\n%s\n\n", Sanitize(jobDescription))
+	}
+	fmt.Fprintf(&sb, "RESUME:\n%s\n\nPROBLEMS:\n", Sanitize(resume))
+	for _, v := range violations {
+		fmt.Fprintf(&sb, "- %s\n", v)
+	}
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: shortenPrompt},
+		{Role: "user", Content: sb.String()},
+	}
+	content, _, tokens, err := complete(ctx, invoker, nil, apiKey, params, c, messages, nil, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	tags := []string{"resume"}
+	content, _ = repairTagged(content, tags)
+	out := extractTag(resumeTagRe, content)
+	if out == "" {
+		reply, n, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, tags, tags)
+		if err != nil {
+			return "", tokens, err
+		}
+		tokens += n
+		out = extractTag(resumeTagRe, reply)
+	}
+	if out == "" {
+		return "", tokens, fmt.Errorf("llm response missing <resume>")
+	}
+	return out, tokens, nil
+}
+
+// overshoot is the total amount by which violations exceed their limits.
+func overshoot(violations []Violation) int {
+	n := 0
+	for _, v := range violations {
+		n += v.Actual - v.Limit
+	}
+	return n
+}
+
+// addsViolation reports whether after breaks any rule more often than before.
+func addsViolation(before, after []Violation) bool {
+	count := map[string]int{}
+	for _, v := range before {
+		count[v.Rule]++
+	}
+	for _, v := range after {
+		if count[v.Rule]--; count[v.Rule] < 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// enforceConstraints checks gen.Resume against Config.Constraints and returns
+// nil when no constraint is configured. A resume assembled from a profile
+// (sel non-nil) is shortened by shortenSelection, which edits sel in place.
+// Otherwise up to maxShortenPasses LLM passes run while violations remain; a
+// pass is kept only if it lowers the total overshoot without breaking any
+// rule more often, and the first pass that is not kept ends the loop. The
+// shortening transcripts, if any, are returned for the caller to flush into
+// the job directory.
+func (a *App) enforceConstraints(ctx context.Context, jobDescription string, gen *Generation, profile *Profile, sel *ProfileSelection, onProgress func(ProgressEvent)) (*ConstraintReport, *TranscriptRecorder) {
+	c := a.Config.Constraints
+	if c.IsZero() {
+		return nil, nil
+	}
+	layout, err := a.pdfLayout("")
+	if err != nil {
+		layout = pdfLayouts[DefaultPDFLayout]
+	}
+	r := &ConstraintReport{Constraints: c}
+	r.Violations, r.Lines, r.Pages = CheckConstraints(gen.Resume, c, layout)
+	r.Initial = r.Violations
+	if len(r.Violations) == 0 {
+		return r, nil
+	}
+
+	if sel != nil {
+		onProgress(ProgressEvent{Stage: StageShortening, Message: fmt.Sprintf("Shortening profile selection (%d constraint violations)\u2026", len(r.Violations))})
+		gen.Resume, r.Dropped = shortenSelection(profile, sel, c, layout)
+		r.Violations, r.Lines, r.Pages = CheckConstraints(gen.Resume, c, layout)
+		gen.Repairs = append(gen.Repairs, fmt.Sprintf("profile selection shortened for length constraints: %d entries dropped (%d of %d violations remain)", len(r.Dropped), len(r.Violations), len(r.Initial)))
+		return r, nil
+	}
+
+	b := a.BackendFor(TaskShorten, onProgress)
+	for r.Passes < maxShortenPasses && len(r.Violations) > 0 {
+		r.Passes++
+		onProgress(ProgressEvent{Stage: StageShortening, Message: fmt.Sprintf("Shortening resume (%d constraint violations)\u2026", len(r.Violations))})
+		shorter, tokens, err := ShortenResume(ctx, b.Invoker, b.APIKey, b.Params, &a.Client, jobDescription, gen.Resume, r.Violations)
+		r.Tokens += tokens
+		gen.Tokens += tokens
+		if err != nil {
+			r.Error = err.Error()
+			break
+		}
+		v, lines, pages := CheckConstraints(shorter, c, layout)
+		if overshoot(v) >= overshoot(r.Violations) || addsViolation(r.Violations, v) {
+			break
+		}
+		gen.Resume = shorter
+		r.Violations, r.Lines, r.Pages = v, lines, pages
+	}
+	gen.Repairs = append(gen.Repairs, fmt.Sprintf("shortening passes for length constraints: %d (%d of %d violations remain)", r.Passes, len(r.Violations), len(r.Initial)))
+	return r, b.Transcript
+}
+
+// shortenSelection fits a profile resume to c by editing sel and
+// reassembling it, so titles, employers, and dates stay as in the profile.
+// An over-long bullet first falls back to the entry's own text when that
+// fits, or is dropped; each role then keeps its first MaxBulletsPerRole
+// entries; and while lines or pages are over, the least relevant entries
+// are dropped. A role's last selected entry is never dropped, since
+// AssembleResume would print the role's first entry in its place. It returns
+// the resume and the dropped entry IDs.
+func shortenSelection(p *Profile, sel *ProfileSelection, c ResumeConstraints, layout PDFLayout) (string, []string) {
+	var dropped []string
+	perRole := map[int]int{}
+	for _, e := range sel.Entries {
+		_, role := p.entry(e.ID)
+		perRole[role]++
+	}
+	drop := func(i int) {
+		_, role := p.entry(sel.Entries[i].ID)
+		perRole[role]--
+		dropped = append(dropped, sel.Entries[i].ID)
+		sel.Entries = slices.Delete(sel.Entries, i, i+1)
+	}
+	droppable := func(i int) bool {
+		_, role := p.entry(sel.Entries[i].ID)
+		return perRole[role] > 1
+	}
+
+	if c.MaxBulletChars > 0 {
+		for i := len(sel.Entries) - 1; i >= 0; i-- {
+			e := &sel.Entries[i]
+			if utf8.RuneCountInString(e.Text) <= c.MaxBulletChars {
+				continue
+			}
+			if orig, _ := p.entry(e.ID); utf8.RuneCountInString(orig.Text) <= c.MaxBulletChars {
+				e.Text = orig.Text
+			} else if droppable(i) {
+				drop(i)
+			}
+		}
+	}
+	if c.MaxBulletsPerRole > 0 {
+		kept := map[int]int{}
+		for i := 0; i < len(sel.Entries); {
+			_, role := p.entry(sel.Entries[i].ID)
+			if kept[role] == c.MaxBulletsPerRole {
+				drop(i)
+				continue
+			}
+			kept[role]++
+			i++
+		}
+	}
+
+	resume := AssembleResume(p, *sel)
+	for {
+		v, _, _ := CheckConstraints(resume, c, layout)
+		if !slices.ContainsFunc(v, func(v Violation) bool {
+			return v.Rule == RuleMaxLines || v.Rule == RuleTargetPages
+		}) {
+			break
+		}
+		i := len(sel.Entries) - 1
+		for i >= 0 && !droppable(i) {
+			i--
+		}
+		if i < 0 {
+			break
+		}
+		drop(i)
+		resume = AssembleResume(p, *sel)
+	}
+	return resume, dropped
+}
+
+// GetConstraintReport reads a job's constraints.json.
+func GetConstraintReport(a *App, id string) (*ConstraintReport, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	return LoadJSON[ConstraintReport](filepath.Join(a.Paths.Jobs, id, constraintsFile))
+}
@@ -0,0 +1,150 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckConstraints(t *testing.T) {
+	resume := sampleResume + `
+
+Copywriter | Beta Agency | 2016 - 2020
+• Rewrote 200 landing pages for search
+• Contributed to five winning pitches
+• Ran a weekly writing workshop for the junior team and edited their drafts
+`
+	c := ResumeConstraints{MaxLines: 10, MaxBulletChars: 60, MaxBulletsPerRole: 2, TargetPages: 1}
+	got, lines, pages := CheckConstraints(resume, c, pdfLayouts["modern"])
+	if lines != 15 || pages != 1 {
+		t.Errorf("lines, pages = %d, %d; want 15, 1", lines, pages)
+	}
+	want := []Violation{
+		{Rule: RuleMaxLines, Limit: 10, Actual: 15},
+		{Rule: RuleMaxBulletChars, Limit: 60, Actual: 73, Text: "Ran a weekly writing workshop for the junior team and edited their drafts"},
+		{Rule: RuleMaxBulletsPerRole, Limit: 2, Actual: 3, Text: "Copywriter | Beta Agency | 2016 - 2020"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("violations = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("violation %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	long := sampleResume + "\n\nEXPERIENCE\n" + strings.Repeat("• Delivered a measurable improvement to a process that mattered\n", 80)
+	got, _, pages = CheckConstraints(long, ResumeConstraints{TargetPages: 1}, pdfLayouts["modern"])
+	if pages < 2 || len(got) != 1 || got[0].Rule != RuleTargetPages || got[0].Actual != pages {
+		t.Errorf("pages = %d, violations = %+v", pages, got)
+	}
+}
+
+func TestShortenResume(t *testing.T) {
+	var sent string
+	invoker := func(_ context.Context, _ string, _ *http.Client, _ int, b json.RawMessage) (string, error) {
+		sent = string(b)
+		return completionBody("<resume>\nJANE DOE\n• Shorter\n</resume>", "")
+	}
+	v := []Violation{{Rule: RuleMaxBulletChars, Limit: 20, Actual: 46, Text: "Grew newsletter subscribers by 40% in one year"}}
+	out, _, err := ShortenResume(context.Background(), invoker, "", TaskParams{}, nil, "We need a copywriter.", sampleResume, v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "JANE DOE\n• Shorter" {
+		t.Errorf("out = %q", out)
+	}
+	for _, want := range []string{"We need a copywriter.", "This bullet has 46 characters; the limit is 20"} {
+		if !strings.Contains(sent, want) {
+			t.Errorf("request missing %q", want)
+		}
+	}
+}
+
+func TestEnforceConstraints(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.Constraints = ResumeConstraints{MaxLines: 6}
+	a.Config.Transcripts.Enabled = true
+	ctx := context.Background()
+	resume := func(bullets ...string) string {
+		return "JANE DOE\n\n• " + strings.Join(bullets, "\n• ")
+	}
+	record := func(from, to string) {
+		t.Helper()
+		v, _, _ := CheckConstraints(from, a.Config.Constraints, pdfLayouts[DefaultPDFLayout])
+		var calls int
+		inv := recordInvoker(a.RecordingsDir(), fakeInvoker("<resume>\n"+to+"\n</resume>", &calls))
+		if _, _, err := ShortenResume(ctx, inv, "", a.TaskParams(TaskShorten), nil, "JD", from, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 10 lines to 8 is still over the limit but closer, so it is kept and
+	// the second pass gets to fix it.
+	r10 := resume("a", "b", "c", "d", "e", "f", "g", "h", "i")
+	r8 := resume("a", "b", "c", "d", "e", "f", "g")
+	r6 := resume("a", "b", "c", "d", "e")
+	record(r10, r8)
+	record(r8, r6)
+	gen := &Generation{Resume: r10}
+	r, rec := a.enforceConstraints(ctx, "JD", gen, nil, nil, func(ProgressEvent) {})
+	if r.Passes != 2 || len(r.Violations) != 0 || len(r.Initial) != 1 || r.Lines != 6 || r.Error != "" {
+		t.Errorf("report = %+v", r)
+	}
+	if gen.Resume != r6 {
+		t.Errorf("resume = %q, want %q", gen.Resume, r6)
+	}
+	dir := t.TempDir()
+	if err := rec.Flush(dir); err != nil {
+		t.Fatal(err)
+	}
+	if names, _ := transcriptNames(filepath.Join(dir, transcriptDir)); len(names) != 2 {
+		t.Errorf("shortening transcripts = %v, want 2", names)
+	}
+
+	// A pass that trades lines for a rule it did not break before is
+	// discarded, even though its total overshoot is lower.
+	a.Config.Constraints.MaxBulletChars = 30
+	record(r10, resume("a", "b", "c", "A bullet well over thirty chars", "e"))
+	gen = &Generation{Resume: r10}
+	r, _ = a.enforceConstraints(ctx, "JD", gen, nil, nil, func(ProgressEvent) {})
+	if r.Passes != 1 || len(r.Violations) != 1 || gen.Resume != r10 {
+		t.Errorf("report = %+v, resume = %q", r, gen.Resume)
+	}
+}
+
+func TestEnforceConstraintsProfile(t *testing.T) {
+	a := newTestApp(t)
+	p := writeProfile(t, a)
+	sel := &ProfileSelection{Summary: p.Summary, Entries: []SelectedEntry{
+		{ID: "acme-newsletter", Text: "Grew the email newsletter's subscriber base by 40% in one year"},
+		{ID: "beta-seo", Text: "Rewrote 200 landing pages for search"},
+		{ID: "acme-launch", Text: "Wrote launch copy for three products"},
+		{ID: "beta-pitch", Text: "Contributed to five winning pitches"},
+	}}
+	gen := &Generation{Resume: AssembleResume(p, *sel)}
+	a.Config.Constraints = ResumeConstraints{MaxLines: 14, MaxBulletChars: 46}
+
+	// No recordings exist, so any LLM call would fail the shortening.
+	r, rec := a.enforceConstraints(context.Background(), "JD", gen, p, sel, func(ProgressEvent) {})
+	if rec != nil || r.Passes != 0 || r.Error != "" || len(r.Violations) != 0 {
+		t.Errorf("report = %+v", r)
+	}
+	if strings.Join(r.Dropped, ",") != "beta-pitch,acme-launch" || len(sel.Entries) != 2 {
+		t.Errorf("dropped = %v, selection = %+v", r.Dropped, sel.Entries)
+	}
+	for _, want := range []string{
+		"Senior Copywriter | Acme Corp | 2020 - Present\n• Grew newsletter subscribers by 40% in one year\n",
+		"Copywriter | Beta Agency | Berlin | 2016 - 2020\n• Rewrote 200 landing pages for search\n",
+	} {
+		if !strings.Contains(gen.Resume, want) {
+			t.Errorf("resume missing %q:\n%s", want, gen.Resume)
+		}
+	}
+	if gen.Resume != AssembleResume(p, *sel) {
+		t.Error("resume does not match the shortened selection")
+	}
+}
@@ -0,0 +1,274 @@
+package jdextract
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// coverBlocksDir holds the reusable cover letter paragraphs, one per
+// config/templates/cover_blocks/<id>.txt.
+const coverBlocksDir = "cover_blocks"
+
+// maxCoverBlocks caps how many blocks one cover letter is built from.
+const maxCoverBlocks = 3
+
+// Cover letter tone presets for Config.CoverTone and ProcessOptions.Tone.
+const (
+	ToneFormal        = "formal"
+	ToneStartupCasual = "startup-casual"
+	ToneConcise       = "concise"
+)
+
+var toneInstructions = map[string]string{
+	ToneFormal:        "Write the cover letter in a formal register: complete sentences, no contractions or slang, a respectful salutation and closing.",
+	ToneStartupCasual: "Write the cover letter in a warm, conversational startup tone: plain words, contractions are fine, enthusiasm without hype, and no stiff formalities.",
+	ToneConcise:       "Keep the cover letter concise: at most three short paragraphs and under 200 words, without repeating what the resume already says.",
+}
+
+// CoverTones returns the tone preset names, sorted.
+func CoverTones() []string {
+	names := make([]string, 0, len(toneInstructions))
+	for n := range toneInstructions {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validTone reports whether tone is empty (the base letter's own tone) or a
+// preset.
+func validTone(tone string) bool {
+	_, ok := toneInstructions[tone]
+	return tone == "" || ok
+}
+
+// CoverBlock is one reusable cover letter paragraph. A block file may start
+// with a "tags: a, b" line; without one, the words of its ID are its tags.
+type CoverBlock struct {
+	ID   string   `json:"id"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+}
+
+// parseCoverBlock reads a block file's content.
+func parseCoverBlock(id, content string) CoverBlock {
+	b := CoverBlock{ID: id, Text: strings.TrimSpace(content)}
+	first, rest, _ := strings.Cut(b.Text, "\n")
+	if label, tags, ok := strings.Cut(first, ":"); ok && strings.EqualFold(strings.TrimSpace(label), "tags") {
+		for _, t := range strings.Split(tags, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				b.Tags = append(b.Tags, t)
+			}
+		}
+		b.Text = strings.TrimSpace(rest)
+	}
+	if len(b.Tags) == 0 {
+		b.Tags = strings.FieldsFunc(id, func(r rune) bool { return r == '-' || r == '_' })
+	}
+	return b
+}
+
+// LoadCoverBlocks reads the cover block library, sorted by ID. A missing
+// directory is an empty library.
+func LoadCoverBlocks(a *App) ([]CoverBlock, error) {
+	dir := filepath.Join(a.Paths.Templates, coverBlocksDir)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []CoverBlock{}, nil
+		}
+		return nil, err
+	}
+	blocks := []CoverBlock{}
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".txt")
+		if !ok || e.IsDir() || !validTemplateName(id) {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("read cover block %q: %w", id, err)
+		}
+		if b := parseCoverBlock(id, string(content)); b.Text != "" {
+			blocks = append(blocks, b)
+		}
+	}
+	return blocks, nil
+}
+
+// jdSection is the text under one heading of a job description; the text
+// before the first heading has an empty Heading.
+type jdSection struct {
+	Heading string
+	Text    string
+}
+
+func jdSections(nodes []JobDescriptionNode) []jdSection {
+	var out []jdSection
+	cur := jdSection{}
+	for _, n := range nodes {
+		switch n.NodeType {
+		case NodeHeading, NodeSectionHeader, NodeJobTitle:
+			if strings.TrimSpace(cur.Text) != "" {
+				out = append(out, cur)
+			}
+			cur = jdSection{Heading: strings.TrimSpace(strings.Trim(strings.TrimSpace(n.Content), "#*:"))}
+		default:
+			cur.Text += n.Content + "\n"
+		}
+	}
+	if strings.TrimSpace(cur.Text) != "" {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// CoverBlockChoice is a block selected for a cover letter and the JD
+// section it answers.
+type CoverBlockChoice struct {
+	CoverBlock
+	Section string `json:"section"`
+}
+
+// SelectCoverBlocks picks up to maxCoverBlocks blocks for a job, walking
+// its sections in order and taking for each the unused block with the most
+// matching tags; a tag matches when all its keywords occur in the section.
+// Ties go to the block sharing more keywords with the section, then to the
+// lower ID. Sections no block's tags match are skipped.
+func SelectCoverBlocks(blocks []CoverBlock, nodes []JobDescriptionNode) []CoverBlockChoice {
+	var out []CoverBlockChoice
+	used := map[string]bool{}
+	for _, s := range jdSections(nodes) {
+		if len(out) == maxCoverBlocks {
+			break
+		}
+		words := keywords(s.Heading + "\n" + s.Text)
+		best, bestTags, bestText := -1, 0, 0
+		for i, b := range blocks {
+			if used[b.ID] {
+				continue
+			}
+			tags := 0
+			for _, t := range b.Tags {
+				kw := keywords(t)
+				if len(kw) > 0 && keywordOverlap(kw, words) == len(kw) {
+					tags++
+				}
+			}
+			text := keywordOverlap(keywords(b.Text), words)
+			if tags > bestTags || tags == bestTags && tags > 0 && text > bestText {
+				best, bestTags, bestText = i, tags, text
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		used[blocks[best].ID] = true
+		out = append(out, CoverBlockChoice{CoverBlock: blocks[best], Section: s.Heading})
+	}
+	return out
+}
+
+// coverInstruction is the task line added to the tailoring prompt for the
+// chosen blocks and tone, or "" when there is neither.
+func coverInstruction(chosen []CoverBlockChoice, tone string) string {
+	var sb strings.Builder
+	if len(chosen) > 0 {
+		sb.WriteString("Build the body of the cover letter from these reusable paragraphs, in this order. Adapt each lightly to the job (the company name, the posting's terms, transitions between paragraphs) without changing its facts or adding claims; take the greeting, opening and closing from the base cover letter.")
+		for _, c := range chosen {
+			if c.Section != "" {
+				fmt.Fprintf(&sb, "\n[%s] (for the posting's %q section)\n%s", c.ID, c.Section, c.Text)
+			} else {
+				fmt.Fprintf(&sb, "\n[%s]\n%s", c.ID, c.Text)
+			}
+		}
+	}
+	if line := toneInstructions[tone]; line != "" {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
+
+// planCover resolves the tone (override, else Config.CoverTone) and picks
+// cover blocks for a job. It returns the prompt instruction and the chosen
+// block IDs.
+func (a *App) planCover(override string, nodes []JobDescriptionNode) (instruction, tone string, ids []string, err error) {
+	tone = override
+	if tone == "" {
+		tone = a.Config.CoverTone
+	}
+	if !validTone(tone) {
+		return "", "", nil, fmt.Errorf("unknown cover tone %q: must be one of %s", tone, strings.Join(CoverTones(), ", "))
+	}
+	blocks, err := LoadCoverBlocks(a)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("load cover blocks: %w", err)
+	}
+	chosen := SelectCoverBlocks(blocks, nodes)
+	for _, c := range chosen {
+		ids = append(ids, c.ID)
+	}
+	return coverInstruction(chosen, tone), tone, ids, nil
+}
+
+// CoverBlockOutcome is how the jobs whose cover letter used a block fared.
+type CoverBlockOutcome struct {
+	ID           string  `json:"id"`
+	Jobs         int     `json:"jobs"`
+	Interviewing int     `json:"interviewing"`      // jobs that reached interviewing (or an offer)
+	Rate         float64 `json:"rate"`              // Interviewing / Jobs
+	Removed      bool    `json:"removed,omitempty"` // recorded in jobs but no longer in the library
+}
+
+// CoverBlockOutcomes returns an outcome per library block, in ID order,
+// followed by blocks recorded in jobs that have since been removed.
+func CoverBlockOutcomes(a *App) ([]CoverBlockOutcome, error) {
+	blocks, err := LoadCoverBlocks(a)
+	if err != nil {
+		return nil, fmt.Errorf("load cover blocks: %w", err)
+	}
+	jobs, err := a.Jobs.List()
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	out := []CoverBlockOutcome{}
+	index := map[string]int{}
+	add := func(id string) *CoverBlockOutcome {
+		i, ok := index[id]
+		if !ok {
+			i = len(out)
+			index[id] = i
+			out = append(out, CoverBlockOutcome{ID: id})
+		}
+		return &out[i]
+	}
+	for _, b := range blocks {
+		add(b.ID)
+	}
+	listed := len(out)
+	for _, m := range jobs {
+		for _, id := range m.CoverBlocks {
+			o := add(id)
+			o.Jobs++
+			if reachedInterview(m) {
+				o.Interviewing++
+			}
+		}
+	}
+	for i := range out {
+		if out[i].Jobs > 0 {
+			out[i].Rate = float64(out[i].Interviewing) / float64(out[i].Jobs)
+		}
+		out[i].Removed = i >= listed
+	}
+	rest := out[listed:]
+	sort.Slice(rest, func(i, j int) bool { return rest[i].ID < rest[j].ID })
+	return out, nil
+}
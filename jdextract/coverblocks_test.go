@@ -0,0 +1,111 @@
+package jdextract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeCoverBlocks(t *testing.T, a *App, blocks map[string]string) {
+	t.Helper()
+	dir := filepath.Join(a.Paths.Templates, coverBlocksDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for id, content := range blocks {
+		if err := os.WriteFile(filepath.Join(dir, id+".txt"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const coverBlocksJD = `# Senior Copywriter
+
+## About the role
+You will own B2B campaigns end to end.
+
+## Requirements
+- Lead a small team of writers
+- SEO content strategy`
+
+func TestSelectCoverBlocks(t *testing.T) {
+	a := newTestApp(t)
+	writeCoverBlocks(t, a, map[string]string{
+		"b2b-campaigns": "I have run B2B campaigns for SaaS brands.",
+		"leadership":    "tags: team lead, mentoring\nI lead a team of four writers.",
+		"seo":           "tags: seo, content strategy\nI built an SEO content strategy from scratch.",
+		"healthcare":    "tags: healthcare\nI wrote for hospitals.",
+		"empty":         "tags: nothing\n",
+	})
+	blocks, err := LoadCoverBlocks(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 4 || blocks[0].ID != "b2b-campaigns" || strings.Join(blocks[0].Tags, ",") != "b2b,campaigns" {
+		t.Fatalf("blocks = %+v", blocks)
+	}
+	if b := blocks[2]; b.ID != "leadership" || b.Text != "I lead a team of four writers." || len(b.Tags) != 2 {
+		t.Errorf("leadership = %+v", b)
+	}
+
+	chosen := SelectCoverBlocks(blocks, Parse(coverBlocksJD))
+	var got []string
+	for _, c := range chosen {
+		got = append(got, c.ID+"@"+c.Section)
+	}
+	if want := "b2b-campaigns@About the role seo@Requirements"; strings.Join(got, " ") != want {
+		t.Errorf("chosen = %v, want %s", got, want)
+	}
+
+	line := coverInstruction(chosen, ToneConcise)
+	for _, want := range []string{"[b2b-campaigns] (for the posting's \"About the role\" section)", "I built an SEO content strategy", "under 200 words"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("instruction missing %q:\n%s", want, line)
+		}
+	}
+	if coverInstruction(nil, "") != "" {
+		t.Error("no blocks and no tone: want empty instruction")
+	}
+}
+
+func TestPlanCover(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.CoverTone = ToneFormal
+	if line, tone, ids, err := a.planCover("", Parse(coverBlocksJD)); err != nil || tone != ToneFormal || ids != nil || !strings.Contains(line, "formal register") {
+		t.Errorf("no library = %q, %q, %v, %v", line, tone, ids, err)
+	}
+	if _, _, _, err := a.planCover("shouty", nil); err == nil {
+		t.Error("unknown tone: want error")
+	}
+}
+
+func TestCoverBlockOutcomes(t *testing.T) {
+	a := newTestApp(t)
+	writeCoverBlocks(t, a, map[string]string{"seo": "SEO.", "leadership": "Lead."})
+	for _, m := range []ApplicationMeta{
+		{Company: "A", CoverBlocks: []string{"seo", "old"}, Status: "interviewing"},
+		{Company: "B", CoverBlocks: []string{"seo"}, Status: "rejected"},
+	} {
+		id, err := a.Jobs.MkDir(m.Company)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Jobs.WriteMeta(id, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out, err := CoverBlockOutcomes(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 || out[0].ID != "leadership" || out[0].Jobs != 0 {
+		t.Fatalf("outcomes = %+v", out)
+	}
+	if s := out[1]; s.ID != "seo" || s.Jobs != 2 || s.Interviewing != 1 || s.Rate != 0.5 || s.Removed {
+		t.Errorf("seo = %+v", s)
+	}
+	if o := out[2]; o.ID != "old" || !o.Removed || o.Rate != 1 {
+		t.Errorf("old = %+v", o)
+	}
+}
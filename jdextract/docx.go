@@ -0,0 +1,205 @@
+package jdextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// docxStyles maps block kinds to the paragraph styles a reference document
+// can define. Styles it lacks fall back to the direct formatting in
+// docxFallback.
+var docxStyles = map[blockKind]string{
+	blockName:       "Title",
+	blockContact:    "Subtitle",
+	blockHeading:    "Heading1",
+	blockSubheading: "Heading2",
+	blockBullet:     "ListBullet",
+	blockParagraph:  "Normal",
+}
+
+// docxFallback is the run formatting used when a reference document does not
+// define a block's style.
+var docxFallback = map[blockKind]string{
+	blockName:       `<w:b/><w:sz w:val="36"/>`,
+	blockHeading:    `<w:b/><w:caps/><w:sz w:val="24"/>`,
+	blockSubheading: `<w:b/>`,
+}
+
+var (
+	docxDocumentRe = regexp.MustCompile(`<w:document\b[^>]*>`)
+	docxSectPrRe   = regexp.MustCompile(`(?s)<w:sectPr\b.*?</w:sectPr>`)
+	docxStyleIDRe  = regexp.MustCompile(`w:styleId="([^"]+)"`)
+)
+
+const (
+	docxNamespaces = `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`
+	// docxSectPr is US Letter with 0.75in margins.
+	docxSectPr = `<w:sectPr><w:pgSz w:w="12240" w:h="15840"/><w:pgMar w:top="1080" w:right="1080" w:bottom="1080" w:left="1080" w:header="720" w:footer="720" w:gutter="0"/></w:sectPr>`
+)
+
+// writeDOCX writes blocks as a .docx package. With a reference document, every
+// part except word/document.xml is copied from it, so its styles, theme,
+// fonts, numbering, and headers apply, and its page setup is kept. Without
+// one, a minimal package with built-in styles is written.
+func writeDOCX(w io.Writer, blocks []docBlock, ref []byte) error {
+	zw := zip.NewWriter(w)
+	open, sectPr := docxNamespaces, docxSectPr
+	styles := map[string]bool{}
+
+	if len(ref) > 0 {
+		zr, err := zip.NewReader(bytes.NewReader(ref), int64(len(ref)))
+		if err != nil {
+			return fmt.Errorf("read reference: %w", err)
+		}
+		found := false
+		for _, f := range zr.File {
+			data, err := readZipFile(f)
+			if err != nil {
+				return fmt.Errorf("read reference %s: %w", f.Name, err)
+			}
+			switch f.Name {
+			case "word/document.xml":
+				found = true
+				if m := docxDocumentRe.Find(data); m != nil {
+					open = string(m)
+				}
+				if m := docxSectPrRe.FindAll(data, -1); len(m) > 0 {
+					sectPr = string(m[len(m)-1])
+				}
+				continue
+			case "word/styles.xml":
+				for _, m := range docxStyleIDRe.FindAllSubmatch(data, -1) {
+					styles[string(m[1])] = true
+				}
+			}
+			if err := writeZipFile(zw, f.Name, data); err != nil {
+				return err
+			}
+		}
+		if !found {
+			return fmt.Errorf("reference has no word/document.xml")
+		}
+	} else {
+		for _, part := range docxParts {
+			if err := writeZipFile(zw, part.name, []byte(part.data)); err != nil {
+				return err
+			}
+		}
+		for _, id := range docxStyles {
+			styles[id] = true
+		}
+	}
+
+	var body strings.Builder
+	body.WriteString(xml.Header)
+	body.WriteString(open)
+	body.WriteString("<w:body>")
+	for _, b := range blocks {
+		body.WriteString(docxParagraph(b, styles))
+	}
+	body.WriteString(sectPr)
+	body.WriteString("</w:body></w:document>")
+	if err := writeZipFile(zw, "word/document.xml", []byte(body.String())); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// docxParagraph renders b as a <w:p>. A bullet whose list style is missing is
+// written as an indented "•" paragraph.
+func docxParagraph(b docBlock, styles map[string]bool) string {
+	var sb strings.Builder
+	sb.WriteString("<w:p>")
+	style := docxStyles[b.Kind]
+	rPr := ""
+	text := b.Text
+	switch {
+	case styles[style]:
+		if style != "Normal" {
+			fmt.Fprintf(&sb, `<w:pPr><w:pStyle w:val="%s"/></w:pPr>`, style)
+		}
+	case b.Kind == blockBullet:
+		sb.WriteString(`<w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr>`)
+		text = "•\t" + text
+	default:
+		rPr = docxFallback[b.Kind]
+	}
+	sb.WriteString("<w:r>")
+	if rPr != "" {
+		sb.WriteString("<w:rPr>" + rPr + "</w:rPr>")
+	}
+	for i, part := range strings.Split(text, "\t") {
+		if i > 0 {
+			sb.WriteString("<w:tab/>")
+		}
+		sb.WriteString(`<w:t xml:space="preserve">`)
+		xml.EscapeText(&sb, []byte(part))
+		sb.WriteString("</w:t>")
+	}
+	sb.WriteString("</w:r></w:p>")
+	return sb.String()
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	fw, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if _, err := fw.Write(data); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// docxParts is the package written when there is no reference document.
+var docxParts = []struct{ name, data string }{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>` +
+		`<Override PartName="/word/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.styles+xml"/>` +
+		`<Override PartName="/word/numbering.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.numbering+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>` +
+		`</Relationships>`},
+	{"word/_rels/document.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/numbering" Target="numbering.xml"/>` +
+		`</Relationships>`},
+	{"word/styles.xml", xml.Header + `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:docDefaults><w:rPrDefault><w:rPr><w:rFonts w:ascii="Calibri" w:hAnsi="Calibri" w:cs="Calibri"/><w:sz w:val="21"/></w:rPr></w:rPrDefault>` +
+		`<w:pPrDefault><w:pPr><w:spacing w:after="60" w:line="259" w:lineRule="auto"/></w:pPr></w:pPrDefault></w:docDefaults>` +
+		`<w:style w:type="paragraph" w:default="1" w:styleId="Normal"><w:name w:val="Normal"/></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Title"><w:name w:val="Title"/><w:basedOn w:val="Normal"/><w:next w:val="Subtitle"/>` +
+		`<w:pPr><w:spacing w:after="40"/></w:pPr><w:rPr><w:b/><w:sz w:val="36"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Subtitle"><w:name w:val="Subtitle"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:spacing w:after="0"/></w:pPr><w:rPr><w:color w:val="404040"/><w:sz w:val="19"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading1"><w:name w:val="heading 1"/><w:basedOn w:val="Normal"/><w:next w:val="Normal"/>` +
+		`<w:pPr><w:keepNext/><w:pBdr><w:bottom w:val="single" w:sz="4" w:space="1" w:color="808080"/></w:pBdr><w:spacing w:before="240" w:after="80"/><w:outlineLvl w:val="0"/></w:pPr>` +
+		`<w:rPr><w:b/><w:caps/><w:sz w:val="24"/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="Heading2"><w:name w:val="heading 2"/><w:basedOn w:val="Normal"/><w:next w:val="ListBullet"/>` +
+		`<w:pPr><w:keepNext/><w:spacing w:before="120" w:after="40"/><w:outlineLvl w:val="1"/></w:pPr><w:rPr><w:b/></w:rPr></w:style>` +
+		`<w:style w:type="paragraph" w:styleId="ListBullet"><w:name w:val="List Bullet"/><w:basedOn w:val="Normal"/>` +
+		`<w:pPr><w:numPr><w:numId w:val="1"/></w:numPr><w:spacing w:after="20"/><w:ind w:left="360" w:hanging="360"/></w:pPr></w:style>` +
+		`</w:styles>`},
+	{"word/numbering.xml", xml.Header + `<w:numbering xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">` +
+		`<w:abstractNum w:abstractNumId="0"><w:lvl w:ilvl="0"><w:start w:val="1"/><w:numFmt w:val="bullet"/><w:lvlText w:val="•"/><w:lvlJc w:val="left"/>` +
+		`<w:pPr><w:ind w:left="360" w:hanging="360"/></w:pPr></w:lvl></w:abstractNum>` +
+		`<w:num w:numId="1"><w:abstractNumId w:val="0"/></w:num>` +
+		`</w:numbering>`},
+}
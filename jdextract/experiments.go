@@ -0,0 +1,270 @@
+package jdextract
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// PromptProfileDefault names config/prompt.json among the prompt profiles.
+// Named profiles live in config/prompts/<name>.json with the same fields.
+const PromptProfileDefault = "default"
+
+const promptProfilesDir = "prompts"
+
+// experimentStateFile holds the round-robin cursor in data/.
+const experimentStateFile = "experiment.json"
+
+// Experiment modes for ExperimentConfig.Mode.
+const (
+	ExperimentOff        = ""
+	ExperimentRandom     = "random"
+	ExperimentRoundRobin = "round-robin"
+)
+
+var validExperimentModes = []string{ExperimentOff, ExperimentRandom, ExperimentRoundRobin}
+
+// ExperimentConfig assigns a prompt profile to each processed job so that
+// outcomes can be compared per profile (see ExperimentReport).
+type ExperimentConfig struct {
+	Mode     string   `json:"mode,omitempty"`     // "", "random", or "round-robin"
+	Profiles []string `json:"profiles,omitempty"` // empty uses "default" and every named profile
+
+	// CostPerMillionTokens prices the report's token totals, in the
+	// currency of your choice. Zero omits cost.
+	CostPerMillionTokens float64 `json:"cost_per_million_tokens,omitempty"`
+}
+
+// validate checks the mode; profile names are checked when assigned.
+func (c ExperimentConfig) validate() error {
+	if !slices.Contains(validExperimentModes, c.Mode) {
+		return fmt.Errorf("mode must be empty, random, or round-robin")
+	}
+	if c.CostPerMillionTokens < 0 {
+		return fmt.Errorf("cost_per_million_tokens must be zero or positive")
+	}
+	return nil
+}
+
+// ListPromptProfiles returns the names of the named prompt profiles, sorted.
+// The default profile is not included.
+func ListPromptProfiles(a *App) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(a.Paths.Config, promptProfilesDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		if n, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && validTemplateName(n) && n != PromptProfileDefault {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// LoadPromptProfile returns the named prompt profile. An empty name or
+// "default" returns the loaded PromptConfig.
+func LoadPromptProfile(a *App, name string) (PromptConfig, error) {
+	if name == "" || name == PromptProfileDefault {
+		return a.PromptConfig, nil
+	}
+	if !validTemplateName(name) {
+		return PromptConfig{}, fmt.Errorf("invalid prompt profile %q", name)
+	}
+	pc, err := LoadJSON[PromptConfig](filepath.Join(a.Paths.Config, promptProfilesDir, name+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return PromptConfig{}, fmt.Errorf("unknown prompt profile %q", name)
+		}
+		return PromptConfig{}, fmt.Errorf("read prompt profile %q: %w", name, err)
+	}
+	if err := pc.Validate(); err != nil {
+		return PromptConfig{}, fmt.Errorf("prompt profile %q: %w", name, err)
+	}
+	return *pc, nil
+}
+
+// SavePromptProfile writes a named prompt profile, creating config/prompts/.
+func SavePromptProfile(a *App, name string, pc PromptConfig) error {
+	if !validTemplateName(name) || name == PromptProfileDefault {
+		return fmt.Errorf("invalid prompt profile %q", name)
+	}
+	dir := filepath.Join(a.Paths.Config, promptProfilesDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return SaveJSON(filepath.Join(dir, name+".json"), pc, 0600)
+}
+
+// experimentProfiles returns the profiles an experiment assigns from.
+func (a *App) experimentProfiles() ([]string, error) {
+	if len(a.Config.Experiment.Profiles) > 0 {
+		return a.Config.Experiment.Profiles, nil
+	}
+	names, err := ListPromptProfiles(a)
+	if err != nil {
+		return nil, fmt.Errorf("list prompt profiles: %w", err)
+	}
+	return append([]string{PromptProfileDefault}, names...), nil
+}
+
+// AssignPromptProfile resolves the prompt profile for a new job. An explicit
+// name wins; otherwise the experiment mode picks one at random or in turn,
+// and with experiments off the default profile is used. The round-robin
+// cursor is kept in data/experiment.json so that it advances across CLI runs.
+func (a *App) AssignPromptProfile(name string) (string, PromptConfig, error) {
+	if name == "" {
+		profiles, err := a.experimentProfiles()
+		if err != nil {
+			return "", PromptConfig{}, err
+		}
+		switch a.Config.Experiment.Mode {
+		case ExperimentRandom:
+			name = profiles[rand.IntN(len(profiles))]
+		case ExperimentRoundRobin:
+			if name, err = a.nextRoundRobin(profiles); err != nil {
+				return "", PromptConfig{}, err
+			}
+		default:
+			name = PromptProfileDefault
+		}
+	}
+	pc, err := LoadPromptProfile(a, name)
+	if err != nil {
+		return "", PromptConfig{}, err
+	}
+	return name, pc, nil
+}
+
+// experimentState is the content of data/experiment.json.
+type experimentState struct {
+	Next int `json:"next"` // index into the experiment's profiles
+}
+
+// nextRoundRobin returns the profile at the cursor and advances it.
+func (a *App) nextRoundRobin(profiles []string) (string, error) {
+	a.experimentMu.Lock()
+	defer a.experimentMu.Unlock()
+	path := filepath.Join(a.Paths.Data, experimentStateFile)
+	var state experimentState
+	if s, err := LoadJSON[experimentState](path); err == nil {
+		state = *s
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read %s: %w", experimentStateFile, err)
+	}
+	name := profiles[state.Next%len(profiles)]
+	state.Next = (state.Next + 1) % len(profiles)
+	if err := SaveJSON(path, state, 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", experimentStateFile, err)
+	}
+	return name, nil
+}
+
+// ProfileOutcome summarizes the jobs generated with one prompt profile.
+type ProfileOutcome struct {
+	Profile      string  `json:"profile"`
+	Jobs         int     `json:"jobs"`
+	Interviewing int     `json:"interviewing"` // jobs that reached interviewing (or an offer)
+	Rate         float64 `json:"rate"`         // Interviewing / Jobs
+
+	// Scores counts jobs by fit score; index 0 holds unscored jobs.
+	Scores    [11]int `json:"scores"`
+	MeanScore float64 `json:"mean_score"`
+
+	// Tokens sums recorded usage. Jobs streamed before usage chunks were
+	// requested have no count, so MeanTokens averages over the TokenJobs
+	// that have one.
+	Tokens     int     `json:"tokens"`
+	TokenJobs  int     `json:"token_jobs"`
+	MeanTokens float64 `json:"mean_tokens"`
+	Cost       float64 `json:"cost,omitempty"` // Tokens priced at CostPerMillionTokens
+}
+
+// ExperimentReport compares outcomes per prompt profile. Jobs processed
+// before prompt profiles were recorded are counted in Unassigned only.
+type ExperimentReport struct {
+	Mode       string           `json:"mode"`
+	Profiles   []ProfileOutcome `json:"profiles"`
+	Unassigned int              `json:"unassigned"`
+}
+
+// reachedInterview reports whether a job got as far as interviewing.
+func reachedInterview(m ApplicationMeta) bool {
+	return m.Interviewed || m.Status == "interviewing" || m.Status == "offer"
+}
+
+// Experiments builds the ExperimentReport from every job's meta. Profiles in
+// the current experiment appear even without jobs; the rest follow by name.
+func Experiments(a *App) (*ExperimentReport, error) {
+	jobs, err := a.Jobs.List()
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	rep := &ExperimentReport{Mode: a.Config.Experiment.Mode, Profiles: []ProfileOutcome{}}
+	index := map[string]int{}
+	add := func(name string) *ProfileOutcome {
+		i, ok := index[name]
+		if !ok {
+			i = len(rep.Profiles)
+			index[name] = i
+			rep.Profiles = append(rep.Profiles, ProfileOutcome{Profile: name})
+		}
+		return &rep.Profiles[i]
+	}
+	if rep.Mode != ExperimentOff {
+		if profiles, err := a.experimentProfiles(); err == nil {
+			for _, p := range profiles {
+				add(p)
+			}
+		}
+	}
+	listed := len(rep.Profiles)
+
+	for _, m := range jobs {
+		if m.PromptProfile == "" {
+			rep.Unassigned++
+			continue
+		}
+		o := add(m.PromptProfile)
+		o.Jobs++
+		if reachedInterview(m) {
+			o.Interviewing++
+		}
+		o.Scores[max(0, min(m.Score, 10))]++
+		if m.Tokens > 0 {
+			o.Tokens += m.Tokens
+			o.TokenJobs++
+		}
+	}
+
+	for i := range rep.Profiles {
+		o := &rep.Profiles[i]
+		scored, sum := 0, 0
+		for s := 1; s <= 10; s++ {
+			scored += o.Scores[s]
+			sum += s * o.Scores[s]
+		}
+		if scored > 0 {
+			o.MeanScore = float64(sum) / float64(scored)
+		}
+		if o.Jobs > 0 {
+			o.Rate = float64(o.Interviewing) / float64(o.Jobs)
+		}
+		if o.TokenJobs > 0 {
+			o.MeanTokens = float64(o.Tokens) / float64(o.TokenJobs)
+		}
+		o.Cost = float64(o.Tokens) / 1e6 * a.Config.Experiment.CostPerMillionTokens
+	}
+	rest := rep.Profiles[listed:]
+	sort.Slice(rest, func(i, j int) bool { return rest[i].Profile < rest[j].Profile })
+	return rep, nil
+}
@@ -0,0 +1,90 @@
+package jdextract
+
+import (
+	"testing"
+)
+
+func TestAssignPromptProfile(t *testing.T) {
+	a := newTestApp(t)
+	a.PromptConfig = PromptConfig{SystemPrompt: "base"}
+	if err := SavePromptProfile(a, "terse", PromptConfig{SystemPrompt: "terse"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SavePromptProfile(a, PromptProfileDefault, PromptConfig{}); err == nil {
+		t.Error("saving over default: want error")
+	}
+
+	name, pc, err := a.AssignPromptProfile("")
+	if err != nil || name != PromptProfileDefault || pc.SystemPrompt != "base" {
+		t.Fatalf("experiments off = %q, %+v, %v", name, pc, err)
+	}
+
+	a.Config.Experiment.Mode = ExperimentRoundRobin
+	var got []string
+	for range 3 {
+		name, pc, err := a.AssignPromptProfile("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == "terse" && pc.SystemPrompt != "terse" {
+			t.Errorf("terse loaded %+v", pc)
+		}
+		got = append(got, name)
+	}
+	if got[0] != "default" || got[1] != "terse" || got[2] != "default" {
+		t.Errorf("round-robin = %v", got)
+	}
+
+	a.Config.Experiment.Mode = ExperimentRandom
+	a.Config.Experiment.Profiles = []string{"terse"}
+	if name, _, _ := a.AssignPromptProfile(""); name != "terse" {
+		t.Errorf("random from [terse] = %q", name)
+	}
+	if name, _, _ := a.AssignPromptProfile(PromptProfileDefault); name != PromptProfileDefault {
+		t.Errorf("explicit profile = %q", name)
+	}
+	if _, _, err := a.AssignPromptProfile("missing"); err == nil {
+		t.Error("unknown profile: want error")
+	}
+}
+
+func TestExperiments(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.Experiment = ExperimentConfig{Mode: ExperimentRoundRobin, Profiles: []string{"default", "terse"}, CostPerMillionTokens: 2}
+	for _, m := range []ApplicationMeta{
+		{Company: "A", PromptProfile: "default", Score: 8, Tokens: 1000, Status: "interviewing"},
+		{Company: "B", PromptProfile: "default", Score: 6, Tokens: 3000, Status: "rejected", Interviewed: true},
+		{Company: "C", PromptProfile: "default", Score: 7, Status: "applied"},
+		{Company: "D", PromptProfile: "old", Score: 5, Tokens: 500},
+		{Company: "E", Score: 9},
+	} {
+		id, err := a.Jobs.MkDir(m.Company)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.Jobs.WriteMeta(id, &m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rep, err := Experiments(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep.Unassigned != 1 || len(rep.Profiles) != 3 {
+		t.Fatalf("report = %+v", rep)
+	}
+	d, terse, old := rep.Profiles[0], rep.Profiles[1], rep.Profiles[2]
+	if d.Profile != "default" || terse.Profile != "terse" || old.Profile != "old" {
+		t.Errorf("order = %s, %s, %s", d.Profile, terse.Profile, old.Profile)
+	}
+	if d.Jobs != 3 || d.Interviewing != 2 || d.MeanScore != 7 || d.Scores[8] != 1 {
+		t.Errorf("default = %+v", d)
+	}
+	if d.Tokens != 4000 || d.TokenJobs != 2 || d.MeanTokens != 2000 || d.Cost != 0.008 {
+		t.Errorf("default tokens = %+v", d)
+	}
+	if terse.Jobs != 0 || terse.Rate != 0 {
+		t.Errorf("terse = %+v", terse)
+	}
+}
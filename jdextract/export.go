@@ -0,0 +1,178 @@
+package jdextract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Export formats.
+const (
+	FormatDOCX = "docx"
+	FormatPDF  = "pdf"
+)
+
+// referenceDocxFile is the optional reference document in config/templates/
+// whose styles, fonts, and page setup DOCX exports inherit.
+const referenceDocxFile = "reference.docx"
+
+// blockKind is the role of a line of a plain-text resume or cover letter.
+type blockKind int
+
+const (
+	blockName       blockKind = iota // the first line
+	blockContact                     // lines directly under the name
+	blockHeading                     // ALL CAPS or "#" section headings
+	blockSubheading                  // "Title | Company | Dates" lines
+	blockBullet
+	blockParagraph
+)
+
+// docBlock is one paragraph of an exported document.
+type docBlock struct {
+	Kind blockKind
+	Text string
+}
+
+var bulletMarkRe = regexp.MustCompile(`^[•\-*–·▪◦]\s+`)
+
+// joinWidth is the length from which a line is taken to be hard-wrapped, so
+// that the next line continues its paragraph or bullet.
+const joinWidth = 60
+
+// parseDocument splits plain text into styled blocks. The first line is the
+// name and the lines up to the next blank line are contact details. After
+// that, bullets, ALL CAPS or "#" headings, and lines containing " | " (role
+// lines) each start a block; other lines start a paragraph unless the line
+// before them was long enough to have been wrapped.
+func parseDocument(text string) []docBlock {
+	var blocks []docBlock
+	header := true
+	prev := ""
+	for _, raw := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			if len(blocks) > 0 {
+				header = false
+			}
+			prev = ""
+			continue
+		}
+		last := len(blocks) - 1
+		switch {
+		case len(blocks) == 0:
+			blocks = append(blocks, docBlock{blockName, strings.TrimSpace(strings.TrimLeft(line, "#"))})
+		case header:
+			blocks = append(blocks, docBlock{blockContact, line})
+		case strings.HasPrefix(line, "#"):
+			blocks = append(blocks, docBlock{blockHeading, strings.TrimSpace(strings.TrimLeft(line, "#"))})
+		case bulletMarkRe.MatchString(line):
+			blocks = append(blocks, docBlock{blockBullet, bulletMarkRe.ReplaceAllString(line, "")})
+		case isUpperLine(line) && len(line) <= joinWidth:
+			blocks = append(blocks, docBlock{blockHeading, line})
+		case strings.Contains(line, " | "):
+			blocks = append(blocks, docBlock{blockSubheading, line})
+		case len(prev) >= joinWidth && !strings.HasSuffix(prev, ",") && !strings.HasSuffix(prev, ":") &&
+			(blocks[last].Kind == blockParagraph || blocks[last].Kind == blockBullet):
+			blocks[last].Text += " " + line
+		default:
+			blocks = append(blocks, docBlock{blockParagraph, line})
+		}
+		prev = line
+	}
+	return blocks
+}
+
+// ExportOptions selects the output of ExportDocument.
+type ExportOptions struct {
+	Format string // FormatDOCX or FormatPDF
+	Layout string // PDF preset; empty uses Config.PDFLayout, then DefaultPDFLayout
+}
+
+// ExportedFile is a document written by ExportJob.
+type ExportedFile struct {
+	Doc   string `json:"doc"` // "resume" or "cover"
+	Path  string `json:"path"`
+	Pages int    `json:"pages,omitempty"` // PDF only
+}
+
+// ExportDocument converts a job's resume.txt or cover.txt (doc "resume" or
+// "cover") and writes it next to the source, e.g. resume.docx.
+func (a *App) ExportDocument(id, doc string, opts ExportOptions) (*ExportedFile, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	if doc != "resume" && doc != "cover" {
+		return nil, fmt.Errorf("invalid document %q: must be resume or cover", doc)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	text, err := os.ReadFile(filepath.Join(dir, doc+".txt"))
+	if err != nil {
+		return nil, fmt.Errorf("read %s.txt: %w", doc, err)
+	}
+
+	var buf bytes.Buffer
+	pages := 0
+	switch opts.Format {
+	case FormatDOCX:
+		ref, err := os.ReadFile(filepath.Join(a.Paths.Templates, referenceDocxFile))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("read %s: %w", referenceDocxFile, err)
+		}
+		if err := writeDOCX(&buf, parseDocument(string(text)), ref); err != nil {
+			return nil, fmt.Errorf("docx: %w", err)
+		}
+	case FormatPDF:
+		layout, err := a.pdfLayout(opts.Layout)
+		if err != nil {
+			return nil, err
+		}
+		if pages, err = writePDF(&buf, parseDocument(string(text)), layout); err != nil {
+			return nil, fmt.Errorf("pdf: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid format %q: must be %s or %s", opts.Format, FormatDOCX, FormatPDF)
+	}
+
+	path := filepath.Join(dir, doc+"."+opts.Format)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", filepath.Base(path), err)
+	}
+	return &ExportedFile{Doc: doc, Path: path, Pages: pages}, nil
+}
+
+// ExportJob exports a job's resume and, if it has one, its cover letter.
+func (a *App) ExportJob(id string, opts ExportOptions) ([]ExportedFile, error) {
+	var out []ExportedFile
+	for _, doc := range []string{"resume", "cover"} {
+		f, err := a.ExportDocument(id, doc, opts)
+		if err != nil {
+			if doc == "cover" && errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			return nil, err
+		}
+		out = append(out, *f)
+	}
+	return out, nil
+}
+
+// pdfLayout resolves a PDF preset name: empty uses Config.PDFLayout, then
+// DefaultPDFLayout.
+func (a *App) pdfLayout(name string) (PDFLayout, error) {
+	if name == "" {
+		name = a.Config.PDFLayout
+	}
+	if name == "" {
+		name = DefaultPDFLayout
+	}
+	layout, ok := pdfLayouts[name]
+	if !ok {
+		return PDFLayout{}, fmt.Errorf("invalid layout %q: must be one of %s", name, strings.Join(PDFLayouts(), ", "))
+	}
+	return layout, nil
+}
@@ -0,0 +1,174 @@
+package jdextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const sampleResume = `JANE DOE
+jane@example.com | (555) 123-4567
+
+PROFESSIONAL SUMMARY
+Copywriter with eight years of experience writing B2B campaigns for software
+companies and agencies.
+
+EXPERIENCE
+Senior Copywriter | Acme Corp | 2020 - Present
+• Grew newsletter subscribers by 40% in one year
+- Wrote launch copy for three products
+
+Thanks,
+Jane`
+
+func TestParseDocument(t *testing.T) {
+	got := parseDocument(sampleResume)
+	want := []docBlock{
+		{blockName, "JANE DOE"},
+		{blockContact, "jane@example.com | (555) 123-4567"},
+		{blockHeading, "PROFESSIONAL SUMMARY"},
+		{blockParagraph, "Copywriter with eight years of experience writing B2B campaigns for software companies and agencies."},
+		{blockHeading, "EXPERIENCE"},
+		{blockSubheading, "Senior Copywriter | Acme Corp | 2020 - Present"},
+		{blockBullet, "Grew newsletter subscribers by 40% in one year"},
+		{blockBullet, "Wrote launch copy for three products"},
+		{blockParagraph, "Thanks,"},
+		{blockParagraph, "Jane"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("block %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+// docxPart returns the named part of a .docx package.
+func docxPart(t *testing.T, docx []byte, name string) string {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(docx), int64(len(docx)))
+	if err != nil {
+		t.Fatalf("open docx: %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name == name {
+			b, err := readZipFile(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return string(b)
+		}
+	}
+	t.Fatalf("docx has no %s", name)
+	return ""
+}
+
+// wellFormed fails t if s is not well-formed XML.
+func wellFormed(t *testing.T, s string) {
+	t.Helper()
+	d := xml.NewDecoder(strings.NewReader(s))
+	for {
+		if _, err := d.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("malformed XML: %v", err)
+		}
+	}
+}
+
+func TestWriteDOCX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeDOCX(&buf, parseDocument(sampleResume+"\nR&D <lead>"), nil); err != nil {
+		t.Fatalf("writeDOCX: %v", err)
+	}
+	doc := docxPart(t, buf.Bytes(), "word/document.xml")
+	wellFormed(t, doc)
+	for _, want := range []string{
+		`<w:pStyle w:val="Title"/></w:pPr><w:r><w:t xml:space="preserve">JANE DOE</w:t>`,
+		`<w:pStyle w:val="Heading1"/>`,
+		`<w:pStyle w:val="ListBullet"/></w:pPr><w:r><w:t xml:space="preserve">Grew newsletter`,
+		"R&amp;D &lt;lead&gt;",
+		`<w:pgSz w:w="12240"`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+	for _, part := range []string{"[Content_Types].xml", "_rels/.rels", "word/styles.xml", "word/numbering.xml"} {
+		wellFormed(t, docxPart(t, buf.Bytes(), part))
+	}
+}
+
+func TestWriteDOCXReference(t *testing.T) {
+	styles := `<w:styles xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:style w:type="paragraph" w:styleId="Heading1"/></w:styles>`
+	var ref bytes.Buffer
+	zw := zip.NewWriter(&ref)
+	writeZipFile(zw, "word/styles.xml", []byte(styles))
+	writeZipFile(zw, "word/document.xml", []byte(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main" xmlns:w14="x"><w:body><w:p/><w:sectPr><w:pgSz w:w="11906" w:h="16838"/></w:sectPr></w:body></w:document>`))
+	zw.Close()
+
+	var buf bytes.Buffer
+	if err := writeDOCX(&buf, parseDocument(sampleResume), ref.Bytes()); err != nil {
+		t.Fatalf("writeDOCX: %v", err)
+	}
+	if got := docxPart(t, buf.Bytes(), "word/styles.xml"); got != styles {
+		t.Errorf("styles.xml not copied from reference: %s", got)
+	}
+	doc := docxPart(t, buf.Bytes(), "word/document.xml")
+	wellFormed(t, doc)
+	for _, want := range []string{
+		`xmlns:w14="x"`,
+		`<w:pgSz w:w="11906" w:h="16838"/>`,
+		`<w:pStyle w:val="Heading1"/>`,
+		`<w:rPr><w:b/><w:sz w:val="36"/></w:rPr><w:t xml:space="preserve">JANE DOE`,
+		`<w:t xml:space="preserve">•</w:t><w:tab/><w:t xml:space="preserve">Grew newsletter`,
+	} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("document.xml missing %q", want)
+		}
+	}
+	if strings.Contains(doc, `w:val="ListBullet"`) {
+		t.Error("used ListBullet style the reference does not define")
+	}
+}
+
+func TestExportJob(t *testing.T) {
+	a := newTestApp(t)
+	id, err := a.Jobs.MkDir("acme-copywriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(a.Paths.Jobs, id, "resume.txt"), []byte(sampleResume), 0644); err != nil {
+		t.Fatal(err)
+	}
+	files, err := a.ExportJob(id, ExportOptions{Format: FormatDOCX})
+	if err != nil {
+		t.Fatalf("ExportJob: %v", err)
+	}
+	if len(files) != 1 || files[0].Doc != "resume" || filepath.Base(files[0].Path) != "resume.docx" {
+		t.Fatalf("files = %+v, want resume.docx only", files)
+	}
+	if _, err := os.Stat(files[0].Path); err != nil {
+		t.Error(err)
+	}
+	pdf, err := a.ExportDocument(id, "resume", ExportOptions{Format: FormatPDF, Layout: "compact"})
+	if err != nil {
+		t.Fatalf("ExportDocument pdf: %v", err)
+	}
+	if pdf.Pages != 1 || filepath.Base(pdf.Path) != "resume.pdf" {
+		t.Errorf("pdf = %+v, want resume.pdf with 1 page", pdf)
+	}
+	if _, err := a.ExportDocument(id, "resume", ExportOptions{Format: FormatPDF, Layout: "poster"}); err == nil {
+		t.Error("unknown layout: want error")
+	}
+	if _, err := a.ExportJob(id, ExportOptions{Format: "odt"}); err == nil {
+		t.Error("unknown format: want error")
+	}
+}
@@ -88,7 +88,7 @@ func SummarizeConversation(
 	ctx context.Context,
 	invoker LLMInvoker,
 	apiKey string,
-	model string,
+	params TaskParams,
 	c *http.Client,
 	conv Conversation,
 ) (string, error) {
@@ -98,13 +98,10 @@ func SummarizeConversation(
 		fmt.Fprintf(&sb, "[%s] %s: %s\n", msg.Date, msg.Sender, Sanitize(msg.Content))
 	}
 
-	reqBody := deepseekRequest{
-		Model: model,
-		Messages: []deepseekMessage{
-			{Role: "system", Content: "You are a concise summarizer. Respond with only the summary, no preamble."},
-			{Role: "user", Content: sb.String()},
-		},
-	}
+	reqBody := params.request([]deepseekMessage{
+		{Role: "system", Content: "You are a concise summarizer. Respond with only the summary, no preamble."},
+		{Role: "user", Content: sb.String()},
+	}, false)
 
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
@@ -115,14 +112,11 @@ func SummarizeConversation(
 	if err != nil {
 		return "", err
 	}
-	var apiResp deepseekResponse
-	if err := json.Unmarshal([]byte(raw), &apiResp); err != nil {
-		return "", fmt.Errorf("decode api response: %w", err)
-	}
-	if len(apiResp.Choices) == 0 {
-		return "", fmt.Errorf("api returned no choices")
+	content, _, _, err := decodeCompletion(raw)
+	if err != nil {
+		return "", err
 	}
-	return strings.TrimSpace(apiResp.Choices[0].Message.Content), nil
+	return strings.TrimSpace(content), nil
 }
 
 // GenerateFollowup builds a prompt from contact context and conversation history,
@@ -132,16 +126,78 @@ func GenerateFollowup(
 	invoker LLMInvoker,
 	streamInvoker StreamingLLMInvoker,
 	apiKey string,
-	model string,
+	params TaskParams,
 	c *http.Client,
 	contact ContactMeta,
 	promptConfig NetworkingPromptConfig,
 	onDelta func(string),
 ) (*FollowupResult, error) {
-	systemPrompt := promptConfig.SystemPrompt + "\n\n" + promptConfig.TaskList + "\n\n" + networkingResponseFormat
+	today := currentDate()
+	prompt, err := promptConfig.render(PromptData{Contact: &contact, Today: today, Default: followupInput(contact, today)})
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+	systemPrompt := prompt.SystemPrompt + "\n\n" + prompt.TaskList + "\n\n" + networkingResponseFormat
+
+	useStreaming := streamInvoker != nil && onDelta != nil
+
+	reqBody := params.request([]deepseekMessage{
+		{Role: "system", Content: systemPrompt},
+		{Role: "user", Content: prompt.User},
+	}, useStreaming)
+
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	var content string
+	if useStreaming {
+		// Reasoning deltas are dropped; only the answer is shown for follow-ups.
+		content, _, err = streamInvoker(ctx, apiKey, c, json.RawMessage(bodyBytes), onDelta, nil)
+		if err != nil {
+			return nil, err
+		}
+		content, _ = splitReasoning(content)
+	} else {
+		raw, err := invoker(ctx, apiKey, c, 0, json.RawMessage(bodyBytes))
+		if err != nil {
+			return nil, err
+		}
+		content, _, _, err = decodeCompletion(raw)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return parseFollowupResult(content)
+}
+
+// parseFollowupResult extracts the networkingResponseFormat tags from content.
+// A missing <message> is an error.
+func parseFollowupResult(content string) (*FollowupResult, error) {
+	timing := strings.TrimSpace(extractTag(followupTimingRe, content))
+	result := &FollowupResult{
+		Subject:           strings.TrimSpace(extractTag(followupSubjectRe, content)),
+		Message:           strings.TrimSpace(extractTag(followupMessageRe, content)),
+		Channel:           strings.TrimSpace(extractTag(followupChannelRe, content)),
+		Timing:            timing,
+		Notes:             strings.TrimSpace(extractTag(followupNotesRe, content)),
+		SuggestedNextDate: parseSuggestedDate(timing),
+	}
 
+	if result.Message == "" {
+		return nil, fmt.Errorf("llm response missing required <message> field")
+	}
+
+	return result, nil
+}
+
+// followupInput is the default follow-up user message: today's date, the
+// contact's details, thread summaries, and the latest thread's last messages.
+func followupInput(contact ContactMeta, today string) string {
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "Today's date: %s\n\n", time.Now().Format("2006-01-02"))
+	fmt.Fprintf(&sb, "Today's date: %s\n\n", today)
 	fmt.Fprintf(&sb, "CONTACT:\nName: %s\n", contact.Name)
 	if contact.Company != "" {
 		fmt.Fprintf(&sb, "Company: %s\n", contact.Company)
@@ -186,57 +242,5 @@ func GenerateFollowup(
 			}
 		}
 	}
-
-	useStreaming := streamInvoker != nil && onDelta != nil
-
-	reqBody := deepseekRequest{
-		Model: model,
-		Messages: []deepseekMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: sb.String()},
-		},
-		Stream: useStreaming,
-	}
-
-	bodyBytes, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("marshal request: %w", err)
-	}
-
-	var content string
-	if useStreaming {
-		content, err = streamInvoker(ctx, apiKey, c, json.RawMessage(bodyBytes), onDelta)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		raw, err := invoker(ctx, apiKey, c, 0, json.RawMessage(bodyBytes))
-		if err != nil {
-			return nil, err
-		}
-		var apiResp deepseekResponse
-		if err := json.Unmarshal([]byte(raw), &apiResp); err != nil {
-			return nil, fmt.Errorf("decode api response: %w", err)
-		}
-		if len(apiResp.Choices) == 0 {
-			return nil, fmt.Errorf("api returned no choices")
-		}
-		content = apiResp.Choices[0].Message.Content
-	}
-
-	timing := strings.TrimSpace(extractTag(followupTimingRe, content))
-	result := &FollowupResult{
-		Subject:           strings.TrimSpace(extractTag(followupSubjectRe, content)),
-		Message:           strings.TrimSpace(extractTag(followupMessageRe, content)),
-		Channel:           strings.TrimSpace(extractTag(followupChannelRe, content)),
-		Timing:            timing,
-		Notes:             strings.TrimSpace(extractTag(followupNotesRe, content)),
-		SuggestedNextDate: parseSuggestedDate(timing),
-	}
-
-	if result.Message == "" {
-		return nil, fmt.Errorf("llm response missing required <message> field")
-	}
-
-	return result, nil
+	return sb.String()
 }
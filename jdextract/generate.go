@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
-	"strconv"
 	"strings"
 )
 
@@ -14,6 +13,7 @@ const responseFormat = `Respond using exactly these XML tags, in this order:
 <company>company name</company>
 <role>role title</role>
 <score>integer 1-10</score>
+` + analysisFormat + `
 <resume>
 full tailored resume text
 </resume>
@@ -21,6 +21,20 @@ full tailored resume text
 tailored cover letter (include ONLY if a base cover letter was provided)
 </cover>`
 
+// analysisFormat requests the per-dimension fit scores and requirement
+// coverage that become analysis.json.
+const analysisFormat = `<subscores>
+skills: integer 1-10
+experience: integer 1-10 (seniority and years)
+domain: integer 1-10
+location: integer 1-10 (location and remote fit)
+compensation: integer 1-10, or 0 if the posting states no compensation
+</subscores>
+<requirements>
+one line per requirement in the job description:
+met|partial|missing | requirement | resume evidence (empty if missing)
+</requirements>`
+
 // LLMInvoker is a function that posts a JSON request body to an LLM endpoint
 // and returns the raw response body. Use InvokeDeepseekApi or InvokeKimiApi.
 type LLMInvoker func(ctx context.Context, apiKey string, c *http.Client, backoff int, body json.RawMessage) (string, error)
@@ -28,11 +42,28 @@ type LLMInvoker func(ctx context.Context, apiKey string, c *http.Client, backoff
 var (
 	companyTagRe = regexp.MustCompile(`(?s)<company>(.*?)</company>`)
 	roleTagRe    = regexp.MustCompile(`(?s)<role>(.*?)</role>`)
-	scoreTagRe   = regexp.MustCompile(`(?s)<score>(\d+)</score>`)
+	scoreTagRe   = regexp.MustCompile(`(?s)<score>(.*?)</score>`)
 	resumeTagRe  = regexp.MustCompile(`(?s)<resume>(.*?)</resume>`)
 	coverTagRe   = regexp.MustCompile(`(?s)<cover>(.*?)</cover>`)
 )
 
+// generationTags lists the GenerateAll response tags in response order.
+var generationTags = []string{"company", "role", "score", "subscores", "requirements", "resume", "cover"}
+
+// Generation is the parsed output of GenerateAll.
+type Generation struct {
+	Company   string
+	Role      string
+	Resume    string
+	Cover     *string // nil when no base cover was given or the model omitted it
+	Score     int     // 0 when the score could not be parsed
+	Tokens    int
+	Repairs   []string     // provenance: each formatting repair or repair turn applied
+	Reasoning string       // reasoning-model output, kept apart from the tagged answer
+	Passes    []Pass       // intermediate outputs when generated by GeneratePipeline
+	Analysis  *FitAnalysis // nil when the model returned no sub-scores or requirements
+}
+
 func extractTag(re *regexp.Regexp, s string) string {
 	m := re.FindStringSubmatch(s)
 	if m == nil {
@@ -48,93 +79,173 @@ func extractTag(re *regexp.Regexp, s string) string {
 // optional — pass nil to skip cover letter generation.
 //
 // The LLM responds in plain text with XML delimiter tags (<company>, <role>,
-// <score>, <resume>, <cover>). GenerateAll extracts each field with compiled
-// regexps. It returns an error if any of company, role, or resume are empty,
-// which surfaces prompt compliance failures rather than silently writing empty
-// files. score defaults to 0 on parse failure (non-fatal). cover is nil when
-// baseCover is nil or the model omitted the tag.
+// <score>, <resume>, <cover>). GenerateAll first repairs common formatting
+// slips (see repairTagged), then extracts each field with compiled regexps.
+// If company, role, or resume are still missing, it sends one short follow-up
+// turn asking the model to re-emit only those tags. It returns an error if
+// they remain empty, which surfaces prompt compliance failures rather than
+// silently writing empty files. Score defaults to 0 on parse failure
+// (non-fatal). Every repair is noted in Generation.Repairs.
+//
+// instructions (may be empty) is appended to the rendered task list verbatim,
+// so text from outside the prompt templates is never parsed as a template.
+//
+// For reasoning models, reasoning deltas stream to onReasoning (may be nil)
+// and the reasoning text is returned in Generation.Reasoning; it is never
+// passed to tag extraction.
 func GenerateAll(
 	ctx context.Context,
 	invoker LLMInvoker,
 	streamInvoker StreamingLLMInvoker,
 	apiKey string,
-	model string,
+	params TaskParams,
 	c *http.Client,
 	nodes []JobDescriptionNode,
 	baseResume string,
 	baseCover *string,
 	promptConfig PromptConfig,
+	instructions string,
 	onDelta func(string),
-) (company, role, resume string, cover *string, score, tokensUsed int, err error) {
-	jobJSON, err := json.Marshal(nodes)
+	onReasoning func(string),
+) (*Generation, error) {
+	data, err := tailorPromptData(nodes, baseResume, baseCover)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := promptConfig.render(data)
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+	prompt.addTasks(instructions)
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: prompt.SystemPrompt + "\n\n" + prompt.TaskList + "\n\n" + responseFormat},
+		{Role: "user", Content: prompt.User},
+	}
+	content, reasoning, tokensUsed, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, onReasoning)
 	if err != nil {
-		return "", "", "", nil, 0, 0, fmt.Errorf("json encode: %w", err)
+		return nil, err
 	}
 
-	// Build system prompt from config
-	systemPrompt := promptConfig.SystemPrompt + "\n\n" + promptConfig.TaskList + "\n\n" + responseFormat
+	gen := &Generation{Tokens: tokensUsed, Reasoning: reasoning}
+	content, gen.Repairs = repairTagged(content, generationTags)
+	gen.extract(content, baseCover != nil)
 
-	var sb strings.Builder
-	fmt.Fprintf(&sb, "JOB DESCRIPTION:\n%s\n\nBASE RESUME:\n%s", jobJSON, Sanitize(baseResume))
-	if baseCover != nil {
-		fmt.Fprintf(&sb, "\n\nBASE COVER LETTER:\n%s", Sanitize(*baseCover))
+	if missing := gen.missing(); len(missing) > 0 && invoker != nil {
+		reply, tokens, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, missing, generationTags)
+		if err != nil {
+			return nil, err
+		}
+		gen.Tokens += tokens
+		gen.Repairs = append(gen.Repairs, fmt.Sprintf("requested missing tags: %s", strings.Join(missing, ", ")))
+		gen.extract(reply, baseCover != nil)
 	}
 
-	useStreaming := streamInvoker != nil && onDelta != nil
+	if missing := gen.missing(); len(missing) > 0 {
+		return nil, fmt.Errorf("llm response missing required fields (company=%q role=%q resume_len=%d)", gen.Company, gen.Role, len(gen.Resume))
+	}
+
+	return gen, nil
+}
 
-	reqBody := deepseekRequest{
-		Model: model,
-		Messages: []deepseekMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: sb.String()},
-		},
-		Stream: useStreaming,
+// generationInput is the default user message shared by GenerateAll and the
+// pipeline passes: the job description nodes and the sanitized base templates.
+func generationInput(d PromptData, withCover bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "JOB DESCRIPTION:\n%s\n\nBASE RESUME:\n%s", d.Job.JSON, d.Resume)
+	if withCover {
+		fmt.Fprintf(&sb, "\n\nBASE COVER LETTER:\n%s", d.Cover)
 	}
+	return sb.String()
+}
+
+// complete sends messages and returns the answer content, any reasoning, and
+// the tokens used; a streamed call reads them from the final usage chunk (0 if
+// the backend sends none). The call streams when both streamInvoker and onDelta
+// are non-nil; reasoning deltas go to onReasoning (may be nil) and inline
+// <think> blocks are split off either way.
+func complete(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	messages []deepseekMessage,
+	onDelta func(string),
+	onReasoning func(string),
+) (content, reasoning string, tokens int, err error) {
+	useStreaming := streamInvoker != nil && onDelta != nil
 
-	bodyBytes, err := json.Marshal(reqBody)
+	bodyBytes, err := json.Marshal(params.request(messages, useStreaming))
 	if err != nil {
-		return "", "", "", nil, 0, 0, fmt.Errorf("marshal request: %w", err)
+		return "", "", 0, fmt.Errorf("marshal request: %w", err)
 	}
 
-	var content string
-	if useStreaming {
-		content, err = streamInvoker(ctx, apiKey, c, json.RawMessage(bodyBytes), onDelta)
-		if err != nil {
-			return "", "", "", nil, 0, 0, err
-		}
-	} else {
+	if !useStreaming {
 		raw, err := invoker(ctx, apiKey, c, 0, json.RawMessage(bodyBytes))
 		if err != nil {
-			return "", "", "", nil, 0, 0, err
-		}
-		var apiResp deepseekResponse
-		if err := json.Unmarshal([]byte(raw), &apiResp); err != nil {
-			return "", "", "", nil, 0, 0, fmt.Errorf("decode api response: %w", err)
-		}
-		if len(apiResp.Choices) == 0 {
-			return "", "", "", nil, 0, 0, fmt.Errorf("api returned no choices")
+			return "", "", 0, err
 		}
-		content = apiResp.Choices[0].Message.Content
-		tokensUsed = apiResp.Usage.TotalTokens
+		return decodeCompletion(raw)
 	}
 
-	company = extractTag(companyTagRe, content)
-	role = extractTag(roleTagRe, content)
-	resume = extractTag(resumeTagRe, content)
-
-	if scoreStr := extractTag(scoreTagRe, content); scoreStr != "" {
-		score, _ = strconv.Atoi(scoreStr)
+	var rb strings.Builder
+	content, tokens, err = streamInvoker(ctx, apiKey, c, json.RawMessage(bodyBytes), onDelta, func(d string) {
+		rb.WriteString(d)
+		if onReasoning != nil {
+			onReasoning(d)
+		}
+	})
+	if err != nil {
+		return "", "", 0, err
 	}
+	content, inline := splitReasoning(content)
+	return content, strings.TrimSpace(rb.String() + "\n\n" + inline), tokens, nil
+}
 
-	if baseCover != nil {
+// extract fills any still-empty fields of g from tagged content. Fields that
+// are already set are kept, so a repair turn only contributes what was missing.
+func (g *Generation) extract(content string, wantCover bool) {
+	if g.Company == "" {
+		g.Company = extractTag(companyTagRe, content)
+	}
+	if g.Role == "" {
+		g.Role = extractTag(roleTagRe, content)
+	}
+	if g.Resume == "" {
+		g.Resume = extractTag(resumeTagRe, content)
+	}
+	if g.Score == 0 {
+		if raw := extractTag(scoreTagRe, content); raw != "" {
+			score, repaired := parseScore(raw)
+			g.Score = score
+			if repaired {
+				g.Repairs = append(g.Repairs, fmt.Sprintf("parsed score %d from %q", score, raw))
+			}
+		}
+	}
+	if wantCover && g.Cover == nil {
 		if c := extractTag(coverTagRe, content); c != "" {
-			cover = &c
+			g.Cover = &c
 		}
 	}
-
-	if company == "" || role == "" || resume == "" {
-		return "", "", "", nil, 0, 0, fmt.Errorf("llm response missing required fields (company=%q role=%q resume_len=%d)", company, role, len(resume))
+	if g.Analysis == nil {
+		g.Analysis = extractAnalysis(content, g.Score)
 	}
+}
 
-	return company, role, resume, cover, score, tokensUsed, nil
+// missing returns the names of required tags that are still empty.
+func (g *Generation) missing() []string {
+	var out []string
+	if g.Company == "" {
+		out = append(out, "company")
+	}
+	if g.Role == "" {
+		out = append(out, "role")
+	}
+	if g.Resume == "" {
+		out = append(out, "resume")
+	}
+	return out
 }
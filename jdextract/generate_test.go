@@ -0,0 +1,323 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeGeneration = `<company>Acme Corp</company>
+<role>Senior Copywriter</role>
+<score>8</score>
+<subscores>
+skills: 8
+experience: 7/10
+</subscores>
+<requirements>
+met | B2B copywriting | Wrote B2B campaigns
+missing | Kubernetes |
+</requirements>
+<resume>
+JANE DOE
+Senior Copywriter
+</resume>
+<cover>
+Dear Hiring Manager,
+</cover>`
+
+// fakeInvoker answers every request with content and counts calls.
+func fakeInvoker(content string, calls *int) LLMInvoker {
+	return func(_ context.Context, _ string, _ *http.Client, _ int, _ json.RawMessage) (string, error) {
+		*calls++
+		return completionBody(content, "")
+	}
+}
+
+// newTestApp returns an App rooted in a temporary directory, replaying LLM
+// calls from recordings.
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	root := t.TempDir()
+	a := &App{
+		Paths: PortablePaths{
+			Root:      root,
+			Data:      filepath.Join(root, "data"),
+			Jobs:      filepath.Join(root, "data", "jobs"),
+			Config:    filepath.Join(root, "config"),
+			Templates: filepath.Join(root, "config", "templates"),
+			Contacts:  filepath.Join(root, "data", "contacts"),
+		},
+		Config: Config{DeepSeekModel: "deepseek-chat", LLMMode: LLMModeReplay},
+	}
+	a.Config.RecordingsDir = filepath.Join(root, "recordings")
+	a.Jobs = Store[ApplicationMeta]{BasePath: a.Paths.Jobs, SetDir: func(m *ApplicationMeta, d string) { m.Dir = d }}
+	a.Contacts = Store[ContactMeta]{BasePath: a.Paths.Contacts, SetDir: func(m *ContactMeta, d string) { m.Dir = d }}
+	if err := a.Setup(); err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestStreamDeltasRoundTrip(t *testing.T) {
+	inputs := []string{
+		fakeGeneration,
+		"  leading and trailing  \n",
+		"Überraschung: naïve café — 100% ✓",
+		"",
+	}
+	for _, in := range inputs {
+		deltas := streamDeltas(in)
+		if got := strings.Join(deltas, ""); got != in {
+			t.Errorf("streamDeltas(%q) joined = %q", in, got)
+		}
+		if len(in) > 20 && len(deltas) < 5 {
+			t.Errorf("streamDeltas(%q) produced only %d chunks", in, len(deltas))
+		}
+	}
+}
+
+func TestGenerateAllRecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	nodes := Parse(sampleJD)
+	cover := "Dear Hiring Manager,"
+	calls := 0
+
+	record := recordInvoker(dir, fakeInvoker(fakeGeneration, &calls))
+	gen, err := GenerateAll(
+		context.Background(), record, nil, "key", TaskParams{Model: "deepseek-chat"}, nil,
+		nodes, "JANE DOE", &cover, PromptConfig{SystemPrompt: "sys", TaskList: "tasks"}, "", nil, nil,
+	)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("invoker calls = %d, want 1", calls)
+	}
+	if gen.Company != "Acme Corp" || gen.Role != "Senior Copywriter" || gen.Score != 8 || !strings.Contains(gen.Resume, "JANE DOE") || gen.Cover == nil {
+		t.Fatalf("unexpected record result: %+v", gen)
+	}
+
+	var streamed strings.Builder
+	gen, err = GenerateAll(
+		context.Background(), replayInvoker(dir), replayStreamInvoker(dir), "", TaskParams{Model: "deepseek-chat"}, nil,
+		nodes, "JANE DOE", &cover, PromptConfig{SystemPrompt: "sys", TaskList: "tasks"}, "",
+		func(d string) { streamed.WriteString(d) }, nil,
+	)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if gen.Company != "Acme Corp" || gen.Role != "Senior Copywriter" || gen.Score != 8 {
+		t.Errorf("unexpected replay result: %+v", gen)
+	}
+	if streamed.String() != fakeGeneration {
+		t.Errorf("streamed content differs from recording")
+	}
+}
+
+func TestReplayMissFails(t *testing.T) {
+	_, err := GenerateAll(
+		context.Background(), replayInvoker(t.TempDir()), nil, "", TaskParams{Model: "deepseek-chat"}, nil,
+		Parse(sampleJD), "resume", nil, PromptConfig{}, "", nil, nil,
+	)
+	if err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Fatalf("expected replay miss error, got %v", err)
+	}
+}
+
+// noNetwork fails any HTTP request made through the client it is installed in.
+type noNetwork struct{ t *testing.T }
+
+func (n noNetwork) RoundTrip(r *http.Request) (*http.Response, error) {
+	n.t.Errorf("unexpected live request to %s", r.URL)
+	return nil, errors.New("network disabled in test")
+}
+
+func TestProcessReplayNeverGoesLive(t *testing.T) {
+	a := newTestApp(t)
+	a.Client = http.Client{Transport: noNetwork{t}}
+
+	// No recording: the replay miss is the error.
+	_, err := a.ProcessWithProgress(context.Background(), sampleJD, func(ProgressEvent) {})
+	if err == nil || !strings.Contains(err.Error(), "no recording") {
+		t.Errorf("replay miss: err = %v", err)
+	}
+
+	t.Setenv("JDEXTRACT_LLM_MODE", "live")
+	if m, err := a.LLMMode(); err != nil || m != LLMModeLive {
+		t.Errorf("LLMMode(\"live\") = %q, %v", m, err)
+	}
+
+	// A misspelled mode fails every call instead of falling back to live.
+	t.Setenv("JDEXTRACT_LLM_MODE", "replaying")
+	if _, err := a.LLMMode(); err == nil {
+		t.Error("LLMMode accepted \"replaying\"")
+	}
+	_, err = a.ProcessWithProgress(context.Background(), sampleJD, func(ProgressEvent) {})
+	if err == nil || !strings.Contains(err.Error(), `unknown JDEXTRACT_LLM_MODE "replaying"`) {
+		t.Errorf("bad mode: err = %v", err)
+	}
+}
+
+func TestGenerateFollowupReplay(t *testing.T) {
+	dir := t.TempDir()
+	contact := ContactMeta{Name: "Jane Doe", Company: "Acme", Status: "replied"}
+	content := "<subject>Hi</subject><message>\nThanks for the chat!\n</message><channel>email</channel><timing>in 3 days</timing><notes>warm</notes>"
+	calls := 0
+
+	if _, err := GenerateFollowup(context.Background(), recordInvoker(dir, fakeInvoker(content, &calls)), nil, "key", TaskParams{Model: "m"}, nil, contact, NetworkingPromptConfig{}, nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	got, err := GenerateFollowup(context.Background(), replayInvoker(dir), nil, "", TaskParams{Model: "m"}, nil, contact, NetworkingPromptConfig{}, nil)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if got.Message != "Thanks for the chat!" || got.Channel != "email" || got.Subject != "Hi" {
+		t.Errorf("unexpected followup: %+v", got)
+	}
+}
+
+func TestProcessReplayEndToEnd(t *testing.T) {
+	a := newTestApp(t)
+	baseResume, err := fetchResume(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baseCover, err := fetchCover(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calls := 0
+	_, err = GenerateAll(
+		context.Background(), recordInvoker(a.RecordingsDir(), fakeInvoker(fakeGeneration, &calls)), nil, "", a.TaskParams(TaskTailor), nil,
+		Parse(sampleJD), baseResume, &baseCover, a.PromptConfig, "", nil, nil,
+	)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	var stages []ProgressStage
+	dir, err := a.ProcessWithProgress(context.Background(), sampleJD, func(e ProgressEvent) {
+		stages = append(stages, e.Stage)
+	})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	meta, err := a.Jobs.ReadMeta(filepath.Base(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Company != "Acme Corp" || meta.Score != 8 || meta.Template != TemplateDefault {
+		t.Errorf("unexpected meta: %+v", meta)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "cover.txt")); err != nil {
+		t.Errorf("cover.txt not written: %v", err)
+	}
+	analysis, err := GetAnalysis(a, filepath.Base(dir))
+	if err != nil {
+		t.Fatalf("analysis.json: %v", err)
+	}
+	if analysis.Score != 8 || analysis.SubScores.Experience != 7 || len(analysis.Requirements) != 2 {
+		t.Errorf("unexpected analysis: %+v", analysis)
+	}
+	if v, err := GetVerification(a, filepath.Base(dir)); err != nil || len(v.Findings) != 0 || len(meta.Warnings) != 0 {
+		t.Errorf("verification = %+v (%v), warnings = %v", v, err, meta.Warnings)
+	}
+	jobs, err := ListJobs(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := FilterJobsByRequirement(a, jobs, RequirementMissing, "kubernetes"); len(got) != 1 {
+		t.Errorf("missing=kubernetes matched %d jobs, want 1", len(got))
+	}
+	if got := FilterJobsByRequirement(a, jobs, RequirementMissing, "copywriting"); len(got) != 0 {
+		t.Errorf("missing=copywriting matched %d jobs, want 0", len(got))
+	}
+	sawContent := false
+	for _, s := range stages {
+		if s == StageContent {
+			sawContent = true
+		}
+	}
+	if !sawContent {
+		t.Errorf("no streamed content events; stages = %v", stages)
+	}
+}
+
+func TestGenerateAllRepairTurn(t *testing.T) {
+	replies := []string{
+		"```xml\n<Company>Acme Corp</Company>\n<score>7/10</score>\n<resume>\nJANE DOE",
+		"<role>Senior Copywriter</role>",
+	}
+	var requests []deepseekRequest
+	invoker := func(_ context.Context, _ string, _ *http.Client, _ int, body json.RawMessage) (string, error) {
+		var req deepseekRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, req)
+		return completionBody(replies[len(requests)-1], "")
+	}
+
+	gen, err := GenerateAll(context.Background(), invoker, nil, "", TaskParams{Model: "m"}, nil, Parse(sampleJD), "JANE DOE", nil, PromptConfig{}, "", nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateAll: %v", err)
+	}
+	if gen.Company != "Acme Corp" || gen.Role != "Senior Copywriter" || gen.Score != 7 || gen.Resume != "JANE DOE" {
+		t.Errorf("unexpected generation: %+v", gen)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("requests = %d, want 2", len(requests))
+	}
+	repair := requests[1].Messages
+	if last := repair[len(repair)-1].Content; !strings.Contains(last, "<role>") || strings.Contains(last, "<resume>") {
+		t.Errorf("repair turn should request only <role>, got %q", last)
+	}
+	if len(gen.Repairs) < 4 {
+		t.Errorf("expected fence, case, unclosed, score and turn repairs, got %v", gen.Repairs)
+	}
+}
+
+func TestGenerateAllSeparatesReasoning(t *testing.T) {
+	dir := t.TempDir()
+	calls := 0
+	reasoningInvoker := func(_ context.Context, _ string, _ *http.Client, _ int, _ json.RawMessage) (string, error) {
+		calls++
+		return completionBody("<think>draft <company>Wrong</company></think>\n"+fakeGeneration, "Weighing the copywriter role.")
+	}
+	nodes := Parse(sampleJD)
+	gen, err := GenerateAll(context.Background(), recordInvoker(dir, reasoningInvoker), nil, "", TaskParams{Model: "deepseek-reasoner"}, nil,
+		nodes, "JANE DOE", nil, PromptConfig{}, "", nil, nil)
+	if err != nil {
+		t.Fatalf("GenerateAll: %v", err)
+	}
+	if gen.Company != "Acme Corp" {
+		t.Errorf("company = %q, reasoning leaked into extraction", gen.Company)
+	}
+	if !strings.Contains(gen.Reasoning, "Weighing") || !strings.Contains(gen.Reasoning, "draft") {
+		t.Errorf("reasoning = %q", gen.Reasoning)
+	}
+
+	var reasoning, content strings.Builder
+	gen, err = GenerateAll(context.Background(), replayInvoker(dir), replayStreamInvoker(dir), "", TaskParams{Model: "deepseek-reasoner"}, nil,
+		nodes, "JANE DOE", nil, PromptConfig{}, "",
+		func(d string) { content.WriteString(d) },
+		func(d string) { reasoning.WriteString(d) },
+	)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if gen.Company != "Acme Corp" {
+		t.Errorf("streamed company = %q", gen.Company)
+	}
+	if !strings.HasPrefix(reasoning.String(), "Weighing") {
+		t.Errorf("reasoning deltas = %q", reasoning.String())
+	}
+	if strings.Contains(content.String(), "Weighing") {
+		t.Errorf("reasoning_content leaked into content deltas")
+	}
+}
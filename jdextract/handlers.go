@@ -5,11 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -23,10 +25,41 @@ func (a *App) registerRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("PATCH /api/config", a.handleUpdateConfig)
 	mux.HandleFunc("GET /api/config/prompt", a.handleGetPromptConfig)
 	mux.HandleFunc("PATCH /api/config/prompt", a.handleUpdatePromptConfig)
+	mux.HandleFunc("GET /api/config/prompts", a.handleListPromptProfiles)
+	mux.HandleFunc("GET /api/config/prompts/{name}", a.handleGetPromptProfile)
+	mux.HandleFunc("PUT /api/config/prompts/{name}", a.handlePutPromptProfile)
+	mux.HandleFunc("GET /api/experiments", a.handleExperiments)
+	mux.HandleFunc("GET /api/cover-blocks", a.handleCoverBlocks)
+	mux.HandleFunc("POST /api/config/prompt/preview", a.handlePreviewPrompt)
 	mux.HandleFunc("GET /api/templates", a.handleGetTemplates)
 	mux.HandleFunc("PATCH /api/templates", a.handleSaveTemplates)
+	mux.HandleFunc("POST /api/templates/import", a.handleImportTemplate)
+	mux.HandleFunc("GET /api/templates/resumes", a.handleListResumeTemplates)
 	mux.HandleFunc("GET /api/jobs/{id}/files", a.handleGetJobFiles)
 	mux.HandleFunc("PATCH /api/jobs/{id}/files", a.handleSaveJobFiles)
+	mux.HandleFunc("GET /api/jobs/{id}/analysis", a.handleGetAnalysis)
+	mux.HandleFunc("GET /api/jobs/{id}/verification", a.handleGetVerification)
+	mux.HandleFunc("GET /api/jobs/{id}/constraints", a.handleGetConstraints)
+	mux.HandleFunc("GET /api/jobs/{id}/ats-check", a.handleGetATSCheck)
+	mux.HandleFunc("POST /api/jobs/{id}/ats-check", a.handleATSCheck)
+	mux.HandleFunc("POST /api/jobs/{id}/revise", a.handleReviseJob)
+	mux.HandleFunc("GET /api/jobs/{id}/revisions", a.handleListRevisions)
+	mux.HandleFunc("GET /api/jobs/{id}/revisions/{name}", a.handleGetRevision)
+	mux.HandleFunc("GET /api/jobs/{id}/chat", a.handleGetChat)
+	mux.HandleFunc("POST /api/jobs/{id}/chat", a.handleChatJob)
+	mux.HandleFunc("DELETE /api/jobs/{id}/chat", a.handleClearChat)
+	mux.HandleFunc("POST /api/jobs/{id}/chat/{index}/apply", a.handleApplyChatBlock)
+	mux.HandleFunc("POST /api/jobs/{id}/prep", a.handlePrepJob)
+	mux.HandleFunc("GET /api/jobs/{id}/prep", a.handleGetPrep)
+	mux.HandleFunc("GET /api/jobs/{id}/answers", a.handleGetAnswers)
+	mux.HandleFunc("POST /api/jobs/{id}/answers", a.handleAnswerQuestions)
+	mux.HandleFunc("PATCH /api/jobs/{id}/answers/{index}", a.handleUpdateAnswer)
+	mux.HandleFunc("GET /api/answers", a.handleSearchAnswerBank)
+	mux.HandleFunc("POST /api/jobs/{id}/messages", a.handleJobMessage)
+	mux.HandleFunc("POST /api/jobs/{id}/messages/send", a.handleSendJobMessage)
+	mux.HandleFunc("GET /api/jobs/{id}/export", a.handleExport)
+	mux.HandleFunc("GET /api/jobs/{id}/transcripts", a.handleListTranscripts)
+	mux.HandleFunc("GET /api/jobs/{id}/transcripts/{name}", a.handleGetTranscript)
 	mux.HandleFunc("GET /api/search", a.handleSearch)
 	mux.HandleFunc("GET /api/jobs", a.handleListJobs)
 	mux.HandleFunc("PATCH /api/jobs/{id}", a.handleUpdateJobStatus)
@@ -127,6 +160,54 @@ func (a *App) handleSaveTemplates(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleImportTemplate converts an uploaded .docx, Markdown, or text file
+// (multipart field "file") to a template and returns it with the template it
+// would replace. Form fields: "target" is resume (default) or cover, "name"
+// picks a variant, and "apply=true" saves it; otherwise nothing is written.
+func (a *App) handleImportTemplate(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "file is required: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "read upload: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	target := r.FormValue("target")
+	if target != "" && target != "resume" && target != "cover" {
+		http.Error(w, "invalid target: must be resume or cover", http.StatusBadRequest)
+		return
+	}
+	imp, err := PreviewTemplateImport(a, header.Filename, data, target == "cover", r.FormValue("name"))
+	if err != nil {
+		http.Error(w, "import: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("apply") == "true" {
+		if err := SaveTemplate(filepath.Join(a.Paths.Templates, imp.Path), imp.Text); err != nil {
+			http.Error(w, "write template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		imp.Saved = true
+	}
+	writeJSON(w, imp)
+}
+
+// handleListResumeTemplates returns the names of the named resume variants
+// under config/templates/resumes/, for use as the process "template" field.
+func (a *App) handleListResumeTemplates(w http.ResponseWriter, r *http.Request) {
+	names, err := ListResumeTemplates(a)
+	if err != nil {
+		http.Error(w, "list templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, names)
+}
+
 // handleGetJobFiles returns the resume and (optionally) cover letter content
 // for a job identified by its exact directory name.
 func (a *App) handleGetJobFiles(w http.ResponseWriter, r *http.Request) {
@@ -160,6 +241,7 @@ func (a *App) handleGetJobFiles(w http.ResponseWriter, r *http.Request) {
 
 // handleSaveJobFiles writes resume.txt and/or cover.txt for a job.
 // Only non-nil fields in the body are written; omitted fields are left untouched.
+// A new resume is re-checked for ATS readiness.
 func (a *App) handleSaveJobFiles(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	if !validID(id) {
@@ -186,9 +268,509 @@ func (a *App) handleSaveJobFiles(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if body.Resume != nil {
+		a.refreshATS(id)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleGetAnalysis returns a job's analysis.json. Jobs generated before fit
+// analysis existed, or whose model omitted it, return 404.
+func (a *App) handleGetAnalysis(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	f, err := GetAnalysis(a, id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "no analysis for job", http.StatusNotFound)
+		} else {
+			http.Error(w, "read analysis: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, f)
+}
+
+// handleGetVerification returns a job's verification.json: the fabrication
+// check findings behind meta's warnings. Jobs predating the check return 404.
+func (a *App) handleGetVerification(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	v, err := GetVerification(a, id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "no verification for job", http.StatusNotFound)
+		} else {
+			http.Error(w, "read verification: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, v)
+}
+
+// handleGetConstraints returns a job's constraints.json: the length
+// constraint check before and after the shortening passes. Jobs generated
+// without constraints return 404.
+func (a *App) handleGetConstraints(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	rep, err := GetConstraintReport(a, id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "no constraint report for job", http.StatusNotFound)
+		} else {
+			http.Error(w, "read constraints: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// handleGetATSCheck returns a job's last ATS check (ats.json).
+func (a *App) handleGetATSCheck(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	rep, err := GetATSReport(a, id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "no ats check for job", http.StatusNotFound)
+		} else {
+			http.Error(w, "read ats check: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// handleATSCheck re-runs the ATS check on a job's current resume, saves it,
+// and returns the report.
+func (a *App) handleATSCheck(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	rep, err := a.CheckJobATS(id)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "job not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "ats check: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// handleReviseJob revises a job's resume or cover letter per the user's
+// instructions, streaming progress as SSE. Body: {"target":"resume"|"cover",
+// "instructions":"..."}. The previous version is kept under revisions/.
+func (a *App) handleReviseJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Target       string `json:"target"`
+		Instructions string `json:"instructions"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if _, ok := targetFiles[body.Target]; !ok {
+		http.Error(w, "invalid target: must be resume or cover", http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Instructions) == "" {
+		http.Error(w, "instructions required", http.StatusBadRequest)
+		return
+	}
+	if _, err := os.Stat(filepath.Join(a.Paths.Jobs, id, targetFiles[body.Target])); err != nil {
+		http.Error(w, body.Target+" not found", http.StatusNotFound)
+		return
+	}
+	flusher := initSSE(w)
+	if flusher == nil {
+		return
+	}
+	err := a.ReviseJob(r.Context(), id, body.Target, body.Instructions, func(e ProgressEvent) {
+		writeSSE(w, flusher, e)
+	})
+	if err != nil {
+		writeSSE(w, flusher, ProgressEvent{Stage: StageError, Message: err.Error()})
+		return
+	}
+	writeSSE(w, flusher, ProgressEvent{Stage: StageComplete, Dir: id})
+}
+
+// handleListRevisions returns a job's archived document versions, newest first.
+func (a *App) handleListRevisions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	list, err := ListRevisions(a, id)
+	if err != nil {
+		http.Error(w, "list revisions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// handleGetRevision returns the text of one archived version.
+func (a *App) handleGetRevision(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	text, err := GetRevision(a, id, r.PathValue("name"))
+	if err != nil {
+		http.Error(w, "revision not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]string{"content": text})
+}
+
+// handleGetChat returns a job's chat thread, oldest message first.
+func (a *App) handleGetChat(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	msgs, err := GetChat(a, id)
+	if err != nil {
+		http.Error(w, "read chat: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, msgs)
+}
+
+// handleChatJob adds a user message to a job's chat and streams the reply as
+// SSE. Body: {"message":"..."}. The final event carries the job ID.
+func (a *App) handleChatJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Message string `json:"message"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if strings.TrimSpace(body.Message) == "" {
+		http.Error(w, "message required", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.Jobs.ReadMeta(id); err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	flusher := initSSE(w)
+	if flusher == nil {
+		return
+	}
+	_, err := a.ChatJob(r.Context(), id, body.Message, func(e ProgressEvent) {
+		writeSSE(w, flusher, e)
+	})
+	if err != nil {
+		writeSSE(w, flusher, ProgressEvent{Stage: StageError, Message: err.Error()})
+		return
+	}
+	writeSSE(w, flusher, ProgressEvent{Stage: StageComplete, Dir: id})
+}
+
+// handleClearChat deletes a job's chat thread.
+func (a *App) handleClearChat(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	if err := ClearChat(a, id); err != nil {
+		http.Error(w, "clear chat: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleApplyChatBlock saves the resume or cover letter block of a chat reply
+// as a new revision of that file. Body: {"target":"resume"|"cover"}.
+func (a *App) handleApplyChatBlock(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		http.Error(w, "invalid message index", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Target string `json:"target"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if _, ok := targetFiles[body.Target]; !ok {
+		http.Error(w, "invalid target: must be resume or cover", http.StatusBadRequest)
+		return
+	}
+	if err := ApplyChatBlock(a, id, index, body.Target); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, "apply chat block: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handlePrepJob generates a job's interview prep packet, streaming progress
+// and content as SSE. The final event carries the job ID.
+func (a *App) handlePrepJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.Jobs.ReadMeta(id); err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	flusher := initSSE(w)
+	if flusher == nil {
+		return
+	}
+	_, err := a.PrepJob(r.Context(), id, func(e ProgressEvent) {
+		writeSSE(w, flusher, e)
+	})
+	if err != nil {
+		writeSSE(w, flusher, ProgressEvent{Stage: StageError, Message: err.Error()})
+		return
+	}
+	writeSSE(w, flusher, ProgressEvent{Stage: StageComplete, Dir: id})
+}
+
+// exportContentTypes are the download MIME types of the export formats.
+var exportContentTypes = map[string]string{
+	FormatDOCX: "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	FormatPDF:  "application/pdf",
+}
+
+// handleExport converts a job's resume (or cover letter, with ?doc=cover) to
+// ?format= and returns it as a download. The file is also kept in the job
+// folder. PDFs take an optional ?layout= preset and report their page count
+// in the X-Page-Count header.
+func (a *App) handleExport(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	format := r.URL.Query().Get("format")
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		http.Error(w, "invalid format: must be docx or pdf", http.StatusBadRequest)
+		return
+	}
+	doc := r.URL.Query().Get("doc")
+	if doc == "" {
+		doc = "resume"
+	}
+	if doc != "resume" && doc != "cover" {
+		http.Error(w, "invalid doc: must be resume or cover", http.StatusBadRequest)
+		return
+	}
+	layout := r.URL.Query().Get("layout")
+	if layout != "" && !slices.Contains(PDFLayouts(), layout) {
+		http.Error(w, "invalid layout: must be one of "+strings.Join(PDFLayouts(), ", "), http.StatusBadRequest)
+		return
+	}
+	f, err := a.ExportDocument(id, doc, ExportOptions{Format: format, Layout: layout})
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "job has no "+doc, http.StatusNotFound)
+		} else {
+			http.Error(w, "export: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	if f.Pages > 0 {
+		w.Header().Set("X-Page-Count", strconv.Itoa(f.Pages))
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", id+"-"+filepath.Base(f.Path)))
+	http.ServeFile(w, r, f.Path)
+}
+
+// handleGetPrep returns a job's prep.md, or 404 if none has been generated.
+func (a *App) handleGetPrep(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	b, err := os.ReadFile(filepath.Join(a.Paths.Jobs, id, prepFile))
+	if err != nil {
+		http.Error(w, "no prep for job", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, map[string]string{"content": string(b)})
+}
+
+// handleGetAnswers returns a job's drafted application answers.
+func (a *App) handleGetAnswers(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	answers, err := GetAnswers(a, id)
+	if err != nil {
+		http.Error(w, "read answers: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, answers)
+}
+
+// handleAnswerQuestions drafts answers to {"questions": [...]} for a job and
+// returns the job's full answer list.
+func (a *App) handleAnswerQuestions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Questions []string `json:"questions"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	var questions []string
+	for _, q := range body.Questions {
+		if q = strings.TrimSpace(q); q != "" {
+			questions = append(questions, q)
+		}
+	}
+	if len(questions) == 0 {
+		http.Error(w, "questions required", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.Jobs.ReadMeta(id); err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	answers, err := a.AnswerQuestions(r.Context(), id, questions)
+	if err != nil {
+		http.Error(w, "answers: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+	writeJSON(w, answers)
+}
+
+// handleUpdateAnswer edits and/or approves one answer. Approved answers are
+// copied into the global answer bank.
+func (a *App) handleUpdateAnswer(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		http.Error(w, "invalid answer index", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Answer   *string `json:"answer"`
+		Approved *bool   `json:"approved"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	ans, err := a.UpdateAnswer(id, index, body.Answer, body.Approved)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "answer not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "update answer: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, ans)
+}
+
+// handleSearchAnswerBank returns bank entries similar to ?q=, best first, or
+// the whole bank when q is empty.
+func (a *App) handleSearchAnswerBank(w http.ResponseWriter, r *http.Request) {
+	bank, err := LoadAnswerBank(a)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeJSON(w, bank)
+		return
+	}
+	writeJSON(w, SearchAnswerBank(bank, q))
+}
+
+// handleListTranscripts returns summaries of a job's LLM transcripts, newest first.
+func (a *App) handleListTranscripts(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	list, err := ListTranscripts(a, id)
+	if err != nil {
+		http.Error(w, "list transcripts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, list)
+}
+
+// handleGetTranscript returns a single transcript, including request and response bodies.
+func (a *App) handleGetTranscript(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	t, err := GetTranscript(a, id, r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "transcript not found", http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	writeJSON(w, t)
+}
+
 // handleGetConfig returns the current in-memory Config as JSON.
 func (a *App) handleGetConfig(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, a.Config)
@@ -203,16 +785,79 @@ func (a *App) handleGetPromptConfig(w http.ResponseWriter, r *http.Request) {
 // updates the in-memory Config. Only non-nil fields in the body are applied.
 func (a *App) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	var body struct {
-		DeepSeekApiKey *string `json:"deepseek_api_key"`
-		DeepSeekModel  *string `json:"deepseek_model"`
-		KimiApiKey     *string `json:"kimi_api_key"`
-		KimiModel      *string `json:"kimi_model"`
-		Backend        *string `json:"backend"`
-		Port           *int    `json:"port"`
+		DeepSeekApiKey *string                   `json:"deepseek_api_key"`
+		DeepSeekModel  *string                   `json:"deepseek_model"`
+		KimiApiKey     *string                   `json:"kimi_api_key"`
+		KimiModel      *string                   `json:"kimi_model"`
+		Backend        *string                   `json:"backend"`
+		Port           *int                      `json:"port"`
+		DeepSeekLimits *RateLimitConfig          `json:"deepseek_limits"`
+		KimiLimits     *RateLimitConfig          `json:"kimi_limits"`
+		Transcripts    *TranscriptConfig         `json:"transcripts"`
+		LLMMode        *string                   `json:"llm_mode"`
+		SaveReasoning  *bool                     `json:"save_reasoning"`
+		Tasks          map[string]TaskParams     `json:"tasks"`
+		Pipeline       *bool                     `json:"pipeline"`
+		VerifyLLM      *bool                     `json:"verify_llm"`
+		PDFLayout      *string                   `json:"pdf_layout"`
+		Constraints    *ResumeConstraints        `json:"constraints"`
+		Experiment     *ExperimentConfig         `json:"experiment"`
+		Languages      map[string]LanguageConfig `json:"languages"`
+		CoverTone      *string                   `json:"cover_tone"`
 	}
 	if !decodeBody(w, r, &body) {
 		return
 	}
+	if body.LLMMode != nil {
+		mode, ok := normalizeLLMMode(*body.LLMMode)
+		if !ok {
+			http.Error(w, "invalid llm_mode: must be live, record, or replay", http.StatusBadRequest)
+			return
+		}
+		body.LLMMode = &mode
+	}
+	if body.PDFLayout != nil && *body.PDFLayout != "" && !slices.Contains(PDFLayouts(), *body.PDFLayout) {
+		http.Error(w, "invalid pdf_layout: must be one of "+strings.Join(PDFLayouts(), ", "), http.StatusBadRequest)
+		return
+	}
+	if body.CoverTone != nil && !validTone(*body.CoverTone) {
+		http.Error(w, "invalid cover_tone: must be empty or one of "+strings.Join(CoverTones(), ", "), http.StatusBadRequest)
+		return
+	}
+	if err := validateLanguages(body.Languages); err != nil {
+		http.Error(w, "invalid languages: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Experiment != nil {
+		if err := body.Experiment.validate(); err != nil {
+			http.Error(w, "invalid experiment: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, name := range body.Experiment.Profiles {
+			if _, err := LoadPromptProfile(a, name); err != nil {
+				http.Error(w, "invalid experiment: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	}
+	if body.Constraints != nil {
+		if err := body.Constraints.validate(); err != nil {
+			http.Error(w, "invalid constraints: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	for _, l := range []*RateLimitConfig{body.DeepSeekLimits, body.KimiLimits} {
+		if l != nil && (l.MaxInFlight < 0 || l.RequestsPerMinute < 0 || l.TokensPerMinute < 0) {
+			http.Error(w, "invalid limits: values must be zero or positive", http.StatusBadRequest)
+			return
+		}
+	}
+	for name, p := range body.Tasks {
+		if err := p.validate(); err != nil {
+			http.Error(w, fmt.Sprintf("invalid task %q: %s", name, err), http.StatusBadRequest)
+			return
+		}
+	}
 	if body.DeepSeekModel != nil && !slices.Contains(validDeepSeekModels, *body.DeepSeekModel) {
 		http.Error(w, "invalid model: must be deepseek-chat or deepseek-reasoner", http.StatusBadRequest)
 		return
@@ -243,6 +888,45 @@ func (a *App) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	if body.Port != nil {
 		a.Config.Port = *body.Port
 	}
+	if body.DeepSeekLimits != nil {
+		a.Config.DeepSeekLimits = *body.DeepSeekLimits
+	}
+	if body.KimiLimits != nil {
+		a.Config.KimiLimits = *body.KimiLimits
+	}
+	if body.Transcripts != nil {
+		a.Config.Transcripts = *body.Transcripts
+	}
+	if body.LLMMode != nil {
+		a.Config.LLMMode = *body.LLMMode
+	}
+	if body.SaveReasoning != nil {
+		a.Config.SaveReasoning = *body.SaveReasoning
+	}
+	if body.Tasks != nil {
+		a.Config.Tasks = body.Tasks
+	}
+	if body.Pipeline != nil {
+		a.Config.Pipeline = *body.Pipeline
+	}
+	if body.VerifyLLM != nil {
+		a.Config.VerifyLLM = *body.VerifyLLM
+	}
+	if body.PDFLayout != nil {
+		a.Config.PDFLayout = *body.PDFLayout
+	}
+	if body.Constraints != nil {
+		a.Config.Constraints = *body.Constraints
+	}
+	if body.Experiment != nil {
+		a.Config.Experiment = *body.Experiment
+	}
+	if body.Languages != nil {
+		a.Config.Languages = body.Languages
+	}
+	if body.CoverTone != nil {
+		a.Config.CoverTone = *body.CoverTone
+	}
 	path := filepath.Join(a.Paths.Config, "config.json")
 	if err := SaveJSON(path, a.Config, 0600); err != nil {
 		http.Error(w, "failed to save config: "+err.Error(), http.StatusInternalServerError)
@@ -251,22 +935,45 @@ func (a *App) handleUpdateConfig(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// promptConfigUpdate is the body of PATCH /api/config/prompt; unset fields
+// are left unchanged.
+type promptConfigUpdate struct {
+	TaskList     *string `json:"task_list"`
+	SystemPrompt *string `json:"system_prompt"`
+	User         *string `json:"user"`
+	Profile      *string `json:"profile"`
+}
+
+func (u promptConfigUpdate) apply(pc PromptConfig) PromptConfig {
+	if u.TaskList != nil {
+		pc.TaskList = *u.TaskList
+	}
+	if u.SystemPrompt != nil {
+		pc.SystemPrompt = *u.SystemPrompt
+	}
+	if u.User != nil {
+		pc.User = *u.User
+	}
+	if u.Profile != nil {
+		pc.Profile = *u.Profile
+	}
+	return pc
+}
+
 // handleUpdatePromptConfig applies a prompt config update, persists it to disk, and
-// updates the in-memory PromptConfig.
+// updates the in-memory PromptConfig. Templates that fail to parse or render
+// are rejected with 400 and nothing is saved.
 func (a *App) handleUpdatePromptConfig(w http.ResponseWriter, r *http.Request) {
-	var body struct {
-		TaskList     *string `json:"task_list"`
-		SystemPrompt *string `json:"system_prompt"`
-	}
+	var body promptConfigUpdate
 	if !decodeBody(w, r, &body) {
 		return
 	}
-	if body.TaskList != nil {
-		a.PromptConfig.TaskList = *body.TaskList
-	}
-	if body.SystemPrompt != nil {
-		a.PromptConfig.SystemPrompt = *body.SystemPrompt
+	pc := body.apply(a.PromptConfig)
+	if err := pc.Validate(); err != nil {
+		http.Error(w, "invalid prompt template: "+err.Error(), http.StatusBadRequest)
+		return
 	}
+	a.PromptConfig = pc
 	path := filepath.Join(a.Paths.Config, "prompt.json")
 	if err := SaveJSON(path, a.PromptConfig, 0600); err != nil {
 		http.Error(w, "failed to save prompt config: "+err.Error(), http.StatusInternalServerError)
@@ -275,6 +982,98 @@ func (a *App) handleUpdatePromptConfig(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// handleListPromptProfiles returns the names of the named prompt profiles
+// under config/prompts/. "default" (prompt.json) is not included.
+func (a *App) handleListPromptProfiles(w http.ResponseWriter, r *http.Request) {
+	names, err := ListPromptProfiles(a)
+	if err != nil {
+		http.Error(w, "list prompt profiles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, names)
+}
+
+// handleGetPromptProfile returns one prompt profile; "default" is prompt.json.
+func (a *App) handleGetPromptProfile(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if !validTemplateName(name) {
+		http.Error(w, "invalid prompt profile name", http.StatusBadRequest)
+		return
+	}
+	pc, err := LoadPromptProfile(a, name)
+	if err != nil {
+		http.Error(w, "prompt profile: "+err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, pc)
+}
+
+// handlePutPromptProfile creates or replaces a named prompt profile. Use
+// PATCH /api/config/prompt for the default profile.
+func (a *App) handlePutPromptProfile(w http.ResponseWriter, r *http.Request) {
+	var pc PromptConfig
+	if !decodeBody(w, r, &pc) {
+		return
+	}
+	if err := pc.Validate(); err != nil {
+		http.Error(w, "invalid prompt template: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := SavePromptProfile(a, r.PathValue("name"), pc); err != nil {
+		http.Error(w, "save prompt profile: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleExperiments compares job outcomes per prompt profile.
+func (a *App) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	rep, err := Experiments(a)
+	if err != nil {
+		http.Error(w, "experiments: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, rep)
+}
+
+// handleCoverBlocks returns the cover block library with the interview rate
+// of the jobs whose cover letters used each block.
+func (a *App) handleCoverBlocks(w http.ResponseWriter, r *http.Request) {
+	out, err := CoverBlockOutcomes(a)
+	if err != nil {
+		http.Error(w, "cover blocks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, out)
+}
+
+// handlePreviewPrompt renders the final tailoring prompt for an existing job
+// without calling the LLM. Prompt fields in the body override the saved
+// config for this preview only, so edits can be checked before saving.
+func (a *App) handlePreviewPrompt(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Job string `json:"job"`
+		promptConfigUpdate
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if !validID(body.Job) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	preview, err := PreviewPrompt(a, body.Job, body.apply(a.PromptConfig))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			http.Error(w, "job not found", http.StatusNotFound)
+		} else {
+			http.Error(w, "preview prompt: "+err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+	writeJSON(w, preview)
+}
+
 // jobResponse is the wire type for GET /api/jobs. It embeds ApplicationMeta
 // and adds Dir as a JSON field — Dir is excluded from the stored meta.json
 // (json:"-") so it must be lifted here for the frontend to use as an ID.
@@ -292,6 +1091,7 @@ func (a *App) handleListJobs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	jobs = applyJobFilters(jobs, r)
+	jobs = applyAnalysisFilters(a, jobs, r)
 	out := make([]jobResponse, len(jobs))
 	for i, j := range jobs {
 		out[i] = jobResponse{ApplicationMeta: j, Dir: j.Dir}
@@ -322,6 +1122,7 @@ func (a *App) handleSearch(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		jobs = applyJobFilters(jobs, r)
+		jobs = applyAnalysisFilters(a, jobs, r)
 		resp.Jobs = make([]jobResponse, len(jobs))
 		for i, j := range jobs {
 			resp.Jobs[i] = jobResponse{ApplicationMeta: j, Dir: j.Dir}
@@ -387,6 +1188,7 @@ func (a *App) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 func (a *App) handleProcess(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		URL string `json:"url"`
+		processOverrides
 	}
 	if !decodeBody(w, r, &body) || body.URL == "" {
 		http.Error(w, "url required", http.StatusBadRequest)
@@ -397,7 +1199,7 @@ func (a *App) handleProcess(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "fetch error: "+err.Error(), http.StatusBadGateway)
 		return
 	}
-	dir, err := a.Process(r.Context(), raw)
+	dir, err := a.ProcessWithOptions(r.Context(), raw, body.apply(a.DefaultProcessOptions()), func(_ ProgressEvent) {})
 	if err != nil {
 		http.Error(w, "process error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -407,6 +1209,35 @@ func (a *App) handleProcess(w http.ResponseWriter, r *http.Request) {
 	}{Dir: dir})
 }
 
+// processOverrides are the optional per-request fields accepted by the
+// process endpoints; unset fields keep the configured default.
+type processOverrides struct {
+	Pipeline      *bool  `json:"pipeline"`
+	Template      string `json:"template"`
+	PromptProfile string `json:"prompt_profile"`
+	Language      string `json:"language"`
+	Tone          string `json:"tone"`
+}
+
+func (o processOverrides) apply(opts ProcessOptions) ProcessOptions {
+	if o.Pipeline != nil {
+		opts.Pipeline = *o.Pipeline
+	}
+	if o.Template != "" {
+		opts.Template = o.Template
+	}
+	if o.PromptProfile != "" {
+		opts.PromptProfile = o.PromptProfile
+	}
+	if o.Language != "" {
+		opts.Language = o.Language
+	}
+	if o.Tone != "" {
+		opts.Tone = o.Tone
+	}
+	return opts
+}
+
 // batchItemResult is the per-URL outcome returned by handleProcessBatch.
 type batchItemResult struct {
 	URL   string `json:"url"`
@@ -419,13 +1250,14 @@ type batchItemResult struct {
 func (a *App) handleProcessBatch(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		URLs []string `json:"urls"`
+		processOverrides
 	}
 	if !decodeBody(w, r, &body) || len(body.URLs) == 0 {
 		http.Error(w, "urls required", http.StatusBadRequest)
 		return
 	}
 	var results []batchItemResult
-	for br := range a.ProcessBatch(r.Context(), body.URLs) {
+	for br := range a.ProcessBatch(r.Context(), body.URLs, body.apply(a.DefaultProcessOptions())) {
 		res := batchItemResult{URL: br.URL, Dir: br.Dir}
 		if br.Err != nil {
 			res.Error = br.Err.Error()
@@ -440,12 +1272,13 @@ func (a *App) handleProcessBatch(w http.ResponseWriter, r *http.Request) {
 func (a *App) handleProcessLocal(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		Content string `json:"content"`
+		processOverrides
 	}
 	if !decodeBody(w, r, &body) || body.Content == "" {
 		http.Error(w, "content required", http.StatusBadRequest)
 		return
 	}
-	dir, err := a.Process(r.Context(), body.Content)
+	dir, err := a.ProcessWithOptions(r.Context(), body.Content, body.apply(a.DefaultProcessOptions()), func(_ ProgressEvent) {})
 	if err != nil {
 		http.Error(w, "process error: "+err.Error(), http.StatusInternalServerError)
 		return
@@ -480,6 +1313,7 @@ func initSSE(w http.ResponseWriter) http.Flusher {
 func (a *App) handleProcessStream(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		URL string `json:"url"`
+		processOverrides
 	}
 	if !decodeBody(w, r, &body) || body.URL == "" {
 		http.Error(w, "url required", http.StatusBadRequest)
@@ -497,7 +1331,7 @@ func (a *App) handleProcessStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dir, err := a.ProcessWithProgress(r.Context(), raw, func(e ProgressEvent) {
+	dir, err := a.ProcessWithOptions(r.Context(), raw, body.apply(a.DefaultProcessOptions()), func(e ProgressEvent) {
 		writeSSE(w, flusher, e)
 	})
 	if err != nil {
@@ -512,6 +1346,7 @@ func (a *App) handleProcessStream(w http.ResponseWriter, r *http.Request) {
 func (a *App) handleProcessLocalStream(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		Content string `json:"content"`
+		processOverrides
 	}
 	if !decodeBody(w, r, &body) || body.Content == "" {
 		http.Error(w, "content required", http.StatusBadRequest)
@@ -522,7 +1357,7 @@ func (a *App) handleProcessLocalStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dir, err := a.ProcessWithProgress(r.Context(), body.Content, func(e ProgressEvent) {
+	dir, err := a.ProcessWithOptions(r.Context(), body.Content, body.apply(a.DefaultProcessOptions()), func(e ProgressEvent) {
 		writeSSE(w, flusher, e)
 	})
 	if err != nil {
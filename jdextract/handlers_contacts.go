@@ -2,9 +2,13 @@ package jdextract
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 )
 
 // contactResponse is the wire type for GET /api/contacts. It embeds ContactMeta
@@ -243,9 +247,10 @@ func (a *App) handleSummarizeConversation(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	b := a.Backend()
+	b := a.BackendFor(TaskSummarize, nil)
 
-	summary, err := SummarizeConversation(r.Context(), b.Invoker, b.APIKey, b.Model, &a.Client, conv)
+	summary, err := SummarizeConversation(r.Context(), b.Invoker, b.APIKey, b.Params, &a.Client, conv)
+	flushTranscripts(b.Transcript, filepath.Join(a.Paths.Contacts, id))
 	if err != nil {
 		http.Error(w, "summarize: "+err.Error(), http.StatusBadGateway)
 		return
@@ -270,9 +275,10 @@ func (a *App) handleGenerateFollowup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	b := a.Backend()
+	b := a.BackendFor(TaskFollowup, nil)
 
-	result, err := GenerateFollowup(r.Context(), b.Invoker, nil, b.APIKey, b.Model, &a.Client, *contact, a.NetworkingPromptConfig, nil)
+	result, err := GenerateFollowup(r.Context(), b.Invoker, nil, b.APIKey, b.Params, &a.Client, *contact, a.NetworkingPromptConfig, nil)
+	flushTranscripts(b.Transcript, filepath.Join(a.Paths.Contacts, id))
 	if err != nil {
 		http.Error(w, "generate followup: "+err.Error(), http.StatusBadGateway)
 		return
@@ -298,7 +304,9 @@ func (a *App) handleGenerateFollowupStream(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	b := a.Backend()
+	b := a.BackendFor(TaskFollowup, func(e ProgressEvent) {
+		writeSSE(w, flusher, e)
+	})
 
 	writeSSE(w, flusher, ProgressEvent{Stage: StageGenerating, Message: "Generating follow-up message\u2026"})
 
@@ -306,7 +314,8 @@ func (a *App) handleGenerateFollowupStream(w http.ResponseWriter, r *http.Reques
 		writeSSE(w, flusher, ProgressEvent{Stage: StageContent, Delta: delta})
 	}
 
-	result, err := GenerateFollowup(r.Context(), b.Invoker, b.StreamInvoker, b.APIKey, b.Model, &a.Client, *contact, a.NetworkingPromptConfig, onDelta)
+	result, err := GenerateFollowup(r.Context(), b.Invoker, b.StreamInvoker, b.APIKey, b.Params, &a.Client, *contact, a.NetworkingPromptConfig, onDelta)
+	flushTranscripts(b.Transcript, filepath.Join(a.Paths.Contacts, id))
 	if err != nil {
 		writeSSE(w, flusher, ProgressEvent{Stage: StageError, Message: "generate followup: " + err.Error()})
 		return
@@ -356,6 +365,101 @@ func (a *App) handleSendFollowup(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, contactResponse{ContactMeta: *updated, Dir: id})
 }
 
+// handleJobMessage drafts a thank-you, decline, accept, ask-for-feedback, or
+// withdraw message for a job. The recipient is the "contact" in the body,
+// which must be linked to the job, or the job's only linked contact.
+func (a *App) handleJobMessage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Kind    string `json:"kind"`
+		Contact string `json:"contact"`
+		Notes   string `json:"notes"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if _, ok := messageKinds[body.Kind]; !ok {
+		http.Error(w, "invalid kind: must be one of "+strings.Join(MessageKinds(), ", "), http.StatusBadRequest)
+		return
+	}
+	if body.Contact != "" && !validID(body.Contact) {
+		http.Error(w, "invalid contact id", http.StatusBadRequest)
+		return
+	}
+	if _, err := a.Jobs.ReadMeta(id); err != nil {
+		http.Error(w, "job not found", http.StatusNotFound)
+		return
+	}
+	result, contact, err := a.JobMessage(r.Context(), id, body.Kind, body.Contact, body.Notes, nil)
+	if err != nil {
+		switch {
+		case errors.Is(err, errNotLinked):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, os.ErrNotExist):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, "generate message: "+err.Error(), http.StatusBadGateway)
+		}
+		return
+	}
+	writeJSON(w, struct {
+		*FollowupResult
+		Contact string `json:"contact,omitempty"`
+	}{result, contact})
+}
+
+// handleSendJobMessage logs a sent job message to the linked contact's
+// conversation, as handleSendFollowup does.
+func (a *App) handleSendJobMessage(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if !validID(id) {
+		http.Error(w, "invalid job id", http.StatusBadRequest)
+		return
+	}
+	var body struct {
+		Contact          string `json:"contact"`
+		Content          string `json:"content"`
+		Channel          string `json:"channel"`
+		NextFollowUpDate string `json:"next_followup_date"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+	if !validID(body.Contact) {
+		http.Error(w, "contact is required", http.StatusBadRequest)
+		return
+	}
+	if body.Content == "" || body.Channel == "" {
+		http.Error(w, "content and channel are required", http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(validFollowupChannels, body.Channel) {
+		http.Error(w, "invalid channel: must be one of "+strings.Join(validFollowupChannels, ", "), http.StatusBadRequest)
+		return
+	}
+	updated, err := SendJobMessage(a, id, body.Contact, SendFollowupInput{
+		Content:          body.Content,
+		Channel:          body.Channel,
+		NextFollowUpDate: body.NextFollowUpDate,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errNotLinked):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, os.ErrNotExist):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+	writeJSON(w, contactResponse{ContactMeta: *updated, Dir: body.Contact})
+}
+
 func contactsToResponses(contacts []ContactMeta) []contactResponse {
 	out := make([]contactResponse, len(contacts))
 	for i, c := range contacts {
@@ -395,21 +499,36 @@ func (a *App) handleGetNetworkingPromptConfig(w http.ResponseWriter, r *http.Req
 	writeJSON(w, a.NetworkingPromptConfig)
 }
 
-// handleUpdateNetworkingPromptConfig updates and persists the networking prompt config.
+// handleUpdateNetworkingPromptConfig validates, updates, and persists the
+// networking prompt config.
 func (a *App) handleUpdateNetworkingPromptConfig(w http.ResponseWriter, r *http.Request) {
 	var body struct {
 		SystemPrompt *string `json:"system_prompt"`
 		TaskList     *string `json:"task_list"`
+		User         *string `json:"user"`
+		Profile      *string `json:"profile"`
 	}
 	if !decodeBody(w, r, &body) {
 		return
 	}
+	pc := a.NetworkingPromptConfig
 	if body.SystemPrompt != nil {
-		a.NetworkingPromptConfig.SystemPrompt = *body.SystemPrompt
+		pc.SystemPrompt = *body.SystemPrompt
 	}
 	if body.TaskList != nil {
-		a.NetworkingPromptConfig.TaskList = *body.TaskList
+		pc.TaskList = *body.TaskList
+	}
+	if body.User != nil {
+		pc.User = *body.User
+	}
+	if body.Profile != nil {
+		pc.Profile = *body.Profile
+	}
+	if err := pc.Validate(); err != nil {
+		http.Error(w, "invalid prompt template: "+err.Error(), http.StatusBadRequest)
+		return
 	}
+	a.NetworkingPromptConfig = pc
 	path := filepath.Join(a.Paths.Config, "networking_prompt.json")
 	if err := SaveJSON(path, a.NetworkingPromptConfig, 0600); err != nil {
 		http.Error(w, "save networking prompt config: "+err.Error(), http.StatusInternalServerError)
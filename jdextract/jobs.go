@@ -22,6 +22,37 @@ type ApplicationMeta struct {
 	Date    string `json:"date"`
 	Status  string `json:"status,omitempty"`
 	Dir     string `json:"-"`
+
+	// Provenance records how the output was produced, e.g. each repair
+	// applied to malformed model output.
+	Provenance []string `json:"provenance,omitempty"`
+	Template   string   `json:"template,omitempty"` // base template used; see SelectTemplates
+
+	// ProfileEntries lists the profile entry IDs selected for the resume
+	// when Template is "profile", most relevant first.
+	ProfileEntries []string `json:"profile_entries,omitempty"`
+
+	// PromptProfile is the prompt profile the job was generated with; see
+	// AssignPromptProfile. Interviewed stays set once the status has been
+	// interviewing or offer, so experiments count later rejections too.
+	PromptProfile string `json:"prompt_profile,omitempty"`
+	Interviewed   bool   `json:"interviewed,omitempty"`
+
+	// Language is the ISO 639-1 code the documents were written in.
+	Language string `json:"language,omitempty"`
+
+	// CoverBlocks lists the cover block IDs the cover letter was built from
+	// (see SelectCoverBlocks); CoverTone is the tone preset, if any.
+	CoverBlocks []string `json:"cover_blocks,omitempty"`
+	CoverTone   string   `json:"cover_tone,omitempty"`
+
+	// ATSCoverage is the keyword coverage percentage of the last ATS check;
+	// nil if the resume was never checked.
+	ATSCoverage *int `json:"ats_coverage,omitempty"`
+
+	// Warnings lists resume claims not found in the base template; details
+	// are in verification.json.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 func (m *ApplicationMeta) SetDir(d string) { m.Dir = d }
@@ -155,6 +186,9 @@ func UpdateJobStatus(a *App, prefix, status string) error {
 		return fmt.Errorf("read meta.json: %w", err)
 	}
 	m.Status = status
+	if status == "interviewing" || status == "offer" {
+		m.Interviewed = true
+	}
 	if err := a.Jobs.WriteMeta(dir, m); err != nil {
 		return fmt.Errorf("write meta.json: %w", err)
 	}
@@ -0,0 +1,163 @@
+package jdextract
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// LanguageEnglish is the default output language.
+const LanguageEnglish = "en"
+
+// Per-language output modes for LanguageConfig.Mode.
+const (
+	LanguageKeepEnglish = "english"   // write in English regardless of the posting
+	LanguageTranslate   = "translate" // write in the posting's language
+)
+
+// languageNames are the supported ISO 639-1 codes.
+var languageNames = map[string]string{
+	"en": "English",
+	"de": "German",
+	"fr": "French",
+	"es": "Spanish",
+	"it": "Italian",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+}
+
+// stopwords are frequent function words that rarely occur in the other
+// supported languages.
+var stopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "with", "for", "you", "our", "we", "are", "will", "is", "in", "your", "experience", "team", "work", "this", "be", "an"},
+	"de": {"und", "der", "die", "das", "mit", "für", "sie", "wir", "ist", "ein", "eine", "zu", "von", "im", "auf", "bei", "unser", "unsere", "ihre", "erfahrung", "oder", "sind"},
+	"fr": {"et", "le", "la", "les", "des", "du", "de", "vous", "nous", "pour", "avec", "une", "est", "dans", "sur", "votre", "vos", "expérience", "au", "aux", "qui"},
+	"es": {"y", "el", "los", "las", "del", "con", "para", "por", "una", "es", "en", "su", "tu", "experiencia", "nuestro", "nuestra", "que", "como", "al"},
+	"it": {"e", "il", "di", "della", "delle", "con", "per", "una", "è", "nel", "nella", "che", "sono", "esperienza", "nostro", "nostra", "gli", "degli", "lo"},
+	"nl": {"en", "het", "de", "van", "een", "met", "voor", "je", "jij", "wij", "onze", "ons", "zijn", "op", "ervaring", "bij", "wat", "niet"},
+	"pt": {"e", "o", "os", "as", "do", "da", "dos", "das", "com", "para", "uma", "é", "em", "no", "na", "experiência", "nosso", "nossa", "você", "que"},
+}
+
+var stopwordIndex = func() map[string][]string {
+	idx := map[string][]string{}
+	for lang, words := range stopwords {
+		for _, w := range words {
+			idx[w] = append(idx[w], lang)
+		}
+	}
+	return idx
+}()
+
+// LanguageConfig is the output policy for one posting language.
+type LanguageConfig struct {
+	Mode     string `json:"mode,omitempty"`     // "english" (default) or "translate"
+	Template string `json:"template,omitempty"` // base template variant written in this language; implies its output
+}
+
+// ValidLanguage reports whether code is a supported language code.
+func ValidLanguage(code string) bool {
+	_, ok := languageNames[code]
+	return ok
+}
+
+// LanguageName returns the English name of a supported language code.
+func LanguageName(code string) string {
+	if n, ok := languageNames[code]; ok {
+		return n
+	}
+	return code
+}
+
+// DetectLanguage guesses the language of text from stopword frequencies and
+// returns its code. Text with too few matches, or where English ties, is
+// reported as English.
+func DetectLanguage(text string) string {
+	counts := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		for _, lang := range stopwordIndex[w] {
+			counts[lang]++
+		}
+	}
+	best, bestCount := LanguageEnglish, counts[LanguageEnglish]
+	langs := make([]string, 0, len(counts))
+	for lang := range counts {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	for _, lang := range langs {
+		if counts[lang] > bestCount {
+			best, bestCount = lang, counts[lang]
+		}
+	}
+	if bestCount < 5 {
+		return LanguageEnglish
+	}
+	return best
+}
+
+// languagePlan is how Process handles a posting's language.
+type languagePlan struct {
+	Job      string // detected or requested posting language
+	Output   string // language the documents are written in
+	Template string // base template variant for the language, if configured
+}
+
+// translated reports whether the documents are written in another language
+// than the English base template.
+func (p languagePlan) translated() bool {
+	return p.Output != LanguageEnglish && p.Template == ""
+}
+
+// instruction is the task line added to the tailoring prompt, or "" for
+// English output.
+func (p languagePlan) instruction() string {
+	if p.Output == LanguageEnglish {
+		return ""
+	}
+	name := LanguageName(p.Output)
+	if !p.translated() {
+		return fmt.Sprintf("Write the tailored resume and cover letter in %s, the language of the base resume and of the job description.", name)
+	}
+	return fmt.Sprintf("Write the tailored resume and cover letter in %[1]s, the language of the job description: translate the base resume's content and section headings into natural %[1]s. Keep personal names, employer and product names, technologies, numbers, and dates unchanged.", name)
+}
+
+// planLanguage resolves the posting language (override, or detected from
+// text) and the output language and template per Config.Languages.
+func (a *App) planLanguage(override, text string) (languagePlan, error) {
+	p := languagePlan{Job: override}
+	if p.Job == "" {
+		p.Job = DetectLanguage(text)
+	} else if !ValidLanguage(p.Job) {
+		return p, fmt.Errorf("unsupported language %q", p.Job)
+	}
+	p.Output = LanguageEnglish
+	if p.Job == LanguageEnglish {
+		return p, nil
+	}
+	lc := a.Config.Languages[p.Job]
+	if lc.Mode == LanguageTranslate || lc.Template != "" {
+		p.Output = p.Job
+		p.Template = lc.Template
+	}
+	return p, nil
+}
+
+// validateLanguages checks Config.Languages keys, modes, and template names.
+func validateLanguages(langs map[string]LanguageConfig) error {
+	for code, lc := range langs {
+		if !ValidLanguage(code) || code == LanguageEnglish {
+			return fmt.Errorf("unsupported language %q", code)
+		}
+		if lc.Mode != "" && lc.Mode != LanguageKeepEnglish && lc.Mode != LanguageTranslate {
+			return fmt.Errorf("%s: mode must be english or translate", code)
+		}
+		if lc.Template != "" && !validTemplateName(lc.Template) {
+			return fmt.Errorf("%s: invalid template name %q", code, lc.Template)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package jdextract
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	cases := map[string]string{
+		sampleJD: "en",
+		"Wir suchen ab sofort eine Senior Texterin (m/w/d) für unser Team in Berlin. Du hast Erfahrung mit B2B-Kampagnen und bist sicher in der deutschen und englischen Sprache. Wir bieten eine unbefristete Stelle mit flexiblen Arbeitszeiten und die Möglichkeit, im Homeoffice zu arbeiten.": "de",
+		"Nous recherchons un(e) rédacteur(trice) pour rejoindre notre équipe à Paris. Vous avez une expérience de la rédaction B2B et vous maîtrisez le français et l'anglais. Le poste est basé dans nos bureaux avec des jours de télétravail.": "fr",
+		"Buscamos un redactor con experiencia en campañas B2B para nuestro equipo en Madrid. Es imprescindible el dominio del español y del inglés, y valoramos la experiencia con SEO.": "es",
+		"Copywriter, Berlin": "en",
+	}
+	for text, want := range cases {
+		if got := DetectLanguage(text); got != want {
+			t.Errorf("DetectLanguage(%.40q…) = %s, want %s", text, got, want)
+		}
+	}
+}
+
+func TestPlanLanguage(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.Languages = map[string]LanguageConfig{
+		"de": {Mode: LanguageTranslate},
+		"fr": {Template: "french"},
+		"es": {Mode: LanguageKeepEnglish},
+	}
+
+	p, err := a.planLanguage("de", "")
+	if err != nil || p.Output != "de" || !p.translated() {
+		t.Errorf("de = %+v, %v", p, err)
+	}
+	if line := p.instruction(); !strings.Contains(line, "translate the base resume") || !strings.Contains(line, "German") {
+		t.Errorf("de instruction = %q", line)
+	}
+	if p, _ := a.planLanguage("fr", ""); p.Output != "fr" || p.Template != "french" || p.translated() {
+		t.Errorf("fr = %+v", p)
+	}
+	for _, code := range []string{"es", "it", "en"} {
+		if p, _ := a.planLanguage(code, ""); p.Output != LanguageEnglish || p.instruction() != "" {
+			t.Errorf("%s = %+v", code, p)
+		}
+	}
+	if _, err := a.planLanguage("xx", ""); err == nil {
+		t.Error("unsupported override: want error")
+	}
+
+	if err := validateLanguages(map[string]LanguageConfig{"de": {Mode: "machine"}}); err == nil {
+		t.Error("invalid mode: want error")
+	}
+	if err := validateLanguages(map[string]LanguageConfig{"en": {Mode: LanguageTranslate}}); err == nil {
+		t.Error("english entry: want error")
+	}
+}
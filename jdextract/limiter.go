@@ -0,0 +1,262 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// defaultMaxInFlight is the in-flight cap applied when a backend's
+// RateLimitConfig leaves MaxInFlight unset. It matches the historical batch
+// concurrency so existing installs keep the same ceiling, now app-wide.
+const defaultMaxInFlight = 10
+
+// RateLimitConfig caps LLM traffic for a single backend. MaxInFlight defaults
+// to defaultMaxInFlight when zero; RequestsPerMinute and TokensPerMinute are
+// unlimited when zero.
+type RateLimitConfig struct {
+	MaxInFlight       int `json:"max_in_flight,omitempty"`
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	TokensPerMinute   int `json:"tokens_per_minute,omitempty"`
+}
+
+// bucket is a continuously refilling token bucket holding up to capacity
+// units, refilled at capacity per minute. A zero capacity means unlimited.
+// level may go negative when actual usage exceeds the reservation, which
+// delays later callers until the debt is repaid.
+type bucket struct {
+	capacity float64
+	level    float64
+	last     time.Time
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.capacity == 0 {
+		return
+	}
+	b.level = min(b.capacity, b.level+now.Sub(b.last).Minutes()*b.capacity)
+	b.last = now
+}
+
+// delay returns how long until n units are available. Requests larger than
+// the bucket wait for a full bucket and then drain it.
+func (b *bucket) delay(n float64) time.Duration {
+	if b.capacity == 0 {
+		return 0
+	}
+	n = min(n, b.capacity)
+	if b.level >= n {
+		return 0
+	}
+	return time.Duration((n - b.level) / b.capacity * float64(time.Minute))
+}
+
+func (b *bucket) take(n float64) {
+	if b.capacity == 0 {
+		return
+	}
+	b.level -= n
+}
+
+// resize changes the bucket capacity, keeping the current level within bounds.
+func (b *bucket) resize(capacity int, now time.Time) {
+	if b.capacity == 0 {
+		b.level = float64(capacity)
+	}
+	b.capacity = float64(capacity)
+	b.level = min(b.level, b.capacity)
+	b.last = now
+}
+
+// Limiter governs access to one LLM backend. Callers queue in FIFO order and
+// are admitted once an in-flight slot is free and both the request and token
+// buckets can cover the call.
+type Limiter struct {
+	mu       sync.Mutex
+	cfg      RateLimitConfig
+	inFlight int
+	requests bucket
+	tokens   bucket
+	queue    []*struct{}
+	wake     chan struct{} // closed and replaced whenever state changes
+}
+
+// NewLimiter returns a Limiter enforcing cfg.
+func NewLimiter(cfg RateLimitConfig) *Limiter {
+	l := &Limiter{wake: make(chan struct{})}
+	l.configure(cfg)
+	return l
+}
+
+// configure applies cfg, resizing buckets in place so queued callers and
+// in-flight accounting survive a config change.
+func (l *Limiter) configure(cfg RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if cfg == l.cfg && !l.requests.last.IsZero() {
+		return
+	}
+	now := time.Now()
+	l.cfg = cfg
+	l.requests.resize(cfg.RequestsPerMinute, now)
+	l.tokens.resize(cfg.TokensPerMinute, now)
+	l.broadcast()
+}
+
+func (l *Limiter) maxInFlight() int {
+	if l.cfg.MaxInFlight > 0 {
+		return l.cfg.MaxInFlight
+	}
+	return defaultMaxInFlight
+}
+
+// broadcast wakes every waiter so it can re-check admission. Caller holds mu.
+func (l *Limiter) broadcast() {
+	close(l.wake)
+	l.wake = make(chan struct{})
+}
+
+// Acquire blocks until the caller may issue a request estimated at tokens
+// tokens, or ctx is cancelled. While waiting, onQueue (if non-nil) is called
+// with the caller's 1-based queue position each time it changes.
+//
+// The returned release function must be called exactly once when the request
+// finishes. Pass the actual token usage if known (or 0) so the token bucket
+// can be corrected for the difference from the estimate.
+func (l *Limiter) Acquire(ctx context.Context, tokens int, onQueue func(position int)) (func(used int), error) {
+	ticket := &struct{}{}
+	l.mu.Lock()
+	l.queue = append(l.queue, ticket)
+	reported := 0
+	for {
+		now := time.Now()
+		l.requests.refill(now)
+		l.tokens.refill(now)
+
+		pos := slices.Index(l.queue, ticket) + 1
+		var wait time.Duration
+		if pos == 1 && l.inFlight < l.maxInFlight() {
+			wait = max(l.requests.delay(1), l.tokens.delay(float64(tokens)))
+			if wait == 0 {
+				l.requests.take(1)
+				l.tokens.take(float64(tokens))
+				l.inFlight++
+				l.queue = l.queue[1:]
+				l.broadcast()
+				l.mu.Unlock()
+				return l.releaseFunc(tokens), nil
+			}
+		}
+
+		wake := l.wake
+		l.mu.Unlock()
+
+		if onQueue != nil && pos != reported {
+			onQueue(pos)
+			reported = pos
+		}
+
+		var timer *time.Timer
+		var fire <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			fire = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			l.mu.Lock()
+			if i := slices.Index(l.queue, ticket); i >= 0 {
+				l.queue = slices.Delete(l.queue, i, i+1)
+			}
+			l.broadcast()
+			l.mu.Unlock()
+			return nil, ctx.Err()
+		case <-wake:
+		case <-fire:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		l.mu.Lock()
+	}
+}
+
+func (l *Limiter) releaseFunc(reserved int) func(used int) {
+	var once sync.Once
+	return func(used int) {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.inFlight--
+			if used > 0 && l.tokens.capacity > 0 {
+				l.tokens.level = min(l.tokens.capacity, l.tokens.level+float64(reserved-used))
+			}
+			l.broadcast()
+		})
+	}
+}
+
+// estimateTokens approximates the prompt size of a request body at roughly
+// four bytes per token. It is only used to reserve token-bucket capacity.
+func estimateTokens(body json.RawMessage) int {
+	return len(body)/4 + 1
+}
+
+// responseTokens extracts usage.total_tokens from a non-streaming response,
+// returning 0 if the body cannot be decoded.
+func responseTokens(raw string) int {
+	var resp deepseekResponse
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return 0
+	}
+	return resp.Usage.TotalTokens
+}
+
+// queueReporter adapts onProgress into a Limiter queue callback.
+func queueReporter(onProgress func(ProgressEvent)) func(int) {
+	if onProgress == nil {
+		return nil
+	}
+	return func(pos int) {
+		onProgress(ProgressEvent{
+			Stage:   StageQueued,
+			Message: fmt.Sprintf("Waiting for LLM capacity (position %d)\u2026", pos),
+			Queue:   pos,
+		})
+	}
+}
+
+// limitInvoker wraps inv so every call is admitted by l first.
+func limitInvoker(l *Limiter, inv LLMInvoker, onProgress func(ProgressEvent)) LLMInvoker {
+	onQueue := queueReporter(onProgress)
+	return func(ctx context.Context, apiKey string, c *http.Client, backoff int, body json.RawMessage) (string, error) {
+		release, err := l.Acquire(ctx, estimateTokens(body), onQueue)
+		if err != nil {
+			return "", err
+		}
+		raw, err := inv(ctx, apiKey, c, backoff, body)
+		release(responseTokens(raw))
+		return raw, err
+	}
+}
+
+// limitStreamInvoker wraps inv so every streaming call is admitted by l first.
+func limitStreamInvoker(l *Limiter, inv StreamingLLMInvoker, onProgress func(ProgressEvent)) StreamingLLMInvoker {
+	onQueue := queueReporter(onProgress)
+	return func(ctx context.Context, apiKey string, c *http.Client, body json.RawMessage, onDelta, onReasoning func(string)) (string, int, error) {
+		release, err := l.Acquire(ctx, estimateTokens(body), onQueue)
+		if err != nil {
+			return "", 0, err
+		}
+		content, tokens, err := inv(ctx, apiKey, c, body, onDelta, onReasoning)
+		release(tokens)
+		return content, tokens, err
+	}
+}
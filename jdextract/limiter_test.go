@@ -0,0 +1,83 @@
+package jdextract
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterMaxInFlight(t *testing.T) {
+	l := NewLimiter(RateLimitConfig{MaxInFlight: 1})
+
+	release, err := l.Acquire(context.Background(), 10, nil)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+
+	positions := make(chan int, 4)
+	acquired := make(chan struct{})
+	go func() {
+		r, err := l.Acquire(context.Background(), 10, func(p int) { positions <- p })
+		if err != nil {
+			t.Errorf("second acquire: %v", err)
+			return
+		}
+		r(0)
+		close(acquired)
+	}()
+
+	select {
+	case p := <-positions:
+		if p != 1 {
+			t.Errorf("queue position = %d, want 1", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("second caller was not queued")
+	}
+	select {
+	case <-acquired:
+		t.Fatal("second caller admitted while slot was held")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	release(0)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second caller not admitted after release")
+	}
+}
+
+func TestLimiterCancelWhileQueued(t *testing.T) {
+	l := NewLimiter(RateLimitConfig{MaxInFlight: 1})
+	release, err := l.Acquire(context.Background(), 1, nil)
+	if err != nil {
+		t.Fatalf("first acquire: %v", err)
+	}
+	defer release(0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, 1, nil); err == nil {
+		t.Fatal("expected context error while queued")
+	}
+	if len(l.queue) != 0 {
+		t.Errorf("queue length = %d after cancel, want 0", len(l.queue))
+	}
+}
+
+func TestLimiterRequestsPerMinute(t *testing.T) {
+	l := NewLimiter(RateLimitConfig{MaxInFlight: 10, RequestsPerMinute: 2})
+	for i := range 2 {
+		r, err := l.Acquire(context.Background(), 1, nil)
+		if err != nil {
+			t.Fatalf("acquire %d: %v", i, err)
+		}
+		r(0)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := l.Acquire(ctx, 1, nil); err == nil {
+		t.Fatal("third request within the minute should have waited")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -23,15 +24,25 @@ type deepseekMessage struct {
 }
 
 type deepseekRequest struct {
-	Model    string            `json:"model"`
-	Messages []deepseekMessage `json:"messages"`
-	Stream   bool              `json:"stream"`
+	Model         string            `json:"model"`
+	Messages      []deepseekMessage `json:"messages"`
+	Stream        bool              `json:"stream"`
+	StreamOptions *streamOptions    `json:"stream_options,omitempty"` // streams only: ask for a final usage chunk
+	Temperature   *float64          `json:"temperature,omitempty"`
+	TopP          *float64          `json:"top_p,omitempty"`
+	MaxTokens     int               `json:"max_tokens,omitempty"`
+	Stop          []string          `json:"stop,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type deepseekResponse struct {
 	Choices []struct {
 		Message struct {
-			Content string `json:"content"`
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // reasoning models only
 		} `json:"message"`
 	} `json:"choices"`
 	Usage struct {
@@ -99,7 +110,8 @@ func InvokeKimiApi(ctx context.Context, apiKey string, c *http.Client, backoff i
 type streamChunk struct {
 	Choices []struct {
 		Delta struct {
-			Content string `json:"content"`
+			Content          string `json:"content"`
+			ReasoningContent string `json:"reasoning_content"` // reasoning models only
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -109,31 +121,39 @@ type streamChunk struct {
 }
 
 // StreamingLLMInvoker posts a streaming request and calls onDelta for each
-// content token. It returns the fully accumulated content string.
-type StreamingLLMInvoker func(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string)) (string, error)
+// content token. Reasoning models (e.g. deepseek-reasoner) stream
+// reasoning_content before the answer; those deltas go to onReasoning, which
+// may be nil, and are never part of the returned content. It returns the fully
+// accumulated answer content string and the total tokens reported by the
+// stream's usage chunk, or 0 when the provider sent none.
+type StreamingLLMInvoker func(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string), onReasoning func(string)) (string, int, error)
 
 // invokeAPIStream posts requestBody to url with streaming enabled and calls
-// onDelta for each content delta. Returns the full accumulated content.
-func invokeAPIStream(ctx context.Context, url, authHeader string, c *http.Client, requestBody json.RawMessage, onDelta func(string)) (string, error) {
+// onDelta for each content delta and onReasoning (if non-nil) for each
+// reasoning delta. Returns the full accumulated answer content and the total
+// tokens from the usage chunk sent last when stream_options.include_usage is
+// set.
+func invokeAPIStream(ctx context.Context, url, authHeader string, c *http.Client, requestBody json.RawMessage, onDelta func(string), onReasoning func(string)) (string, int, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(requestBody))
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", authHeader)
 
 	resp, err := c.Do(req)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("api returned status %d: %s", resp.StatusCode, string(body))
+		return "", 0, fmt.Errorf("api returned status %d: %s", resp.StatusCode, string(body))
 	}
 
 	var sb strings.Builder
+	tokens := 0
 	scanner := bufio.NewScanner(resp.Body)
 	for scanner.Scan() {
 		line := scanner.Text()
@@ -148,7 +168,13 @@ func invokeAPIStream(ctx context.Context, url, authHeader string, c *http.Client
 		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
 			continue
 		}
+		if chunk.Usage.TotalTokens > 0 {
+			tokens = chunk.Usage.TotalTokens
+		}
 		if len(chunk.Choices) > 0 {
+			if r := chunk.Choices[0].Delta.ReasoningContent; r != "" && onReasoning != nil {
+				onReasoning(r)
+			}
 			delta := chunk.Choices[0].Delta.Content
 			if delta != "" {
 				fmt.Fprintf(&sb, "%s", delta)
@@ -157,17 +183,51 @@ func invokeAPIStream(ctx context.Context, url, authHeader string, c *http.Client
 		}
 	}
 	if err := scanner.Err(); err != nil {
-		return "", fmt.Errorf("stream read error: %w", err)
+		return "", 0, fmt.Errorf("stream read error: %w", err)
 	}
-	return sb.String(), nil
+	return sb.String(), tokens, nil
 }
 
 // InvokeDeepseekApiStream calls the DeepSeek API with streaming enabled.
-func InvokeDeepseekApiStream(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string)) (string, error) {
-	return invokeAPIStream(ctx, deepseekURL, "Bearer "+apiKey, c, requestBody, onDelta)
+func InvokeDeepseekApiStream(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string), onReasoning func(string)) (string, int, error) {
+	return invokeAPIStream(ctx, deepseekURL, "Bearer "+apiKey, c, requestBody, onDelta, onReasoning)
 }
 
 // InvokeKimiApiStream calls the Kimi API with streaming enabled.
-func InvokeKimiApiStream(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string)) (string, error) {
-	return invokeAPIStream(ctx, kimiURL, "Api-Key "+apiKey, c, requestBody, onDelta)
+func InvokeKimiApiStream(ctx context.Context, apiKey string, c *http.Client, requestBody json.RawMessage, onDelta func(string), onReasoning func(string)) (string, int, error) {
+	return invokeAPIStream(ctx, kimiURL, "Api-Key "+apiKey, c, requestBody, onDelta, onReasoning)
+}
+
+// thinkBlockRe matches inline <think>…</think> reasoning blocks that some
+// providers emit inside the answer content instead of reasoning_content.
+var thinkBlockRe = regexp.MustCompile(`(?s)<think>(.*?)</think>`)
+
+// splitReasoning separates inline <think> blocks from content, returning the
+// answer with those blocks removed and the reasoning they contained.
+func splitReasoning(content string) (answer, reasoning string) {
+	var parts []string
+	for _, m := range thinkBlockRe.FindAllStringSubmatch(content, -1) {
+		parts = append(parts, strings.TrimSpace(m[1]))
+	}
+	if len(parts) == 0 {
+		return content, ""
+	}
+	return strings.TrimSpace(thinkBlockRe.ReplaceAllString(content, "")), strings.Join(parts, "\n\n")
+}
+
+// decodeCompletion extracts the first choice from a non-streaming response.
+// content never includes reasoning: reasoning_content and inline <think>
+// blocks are returned separately.
+func decodeCompletion(raw string) (content, reasoning string, tokens int, err error) {
+	var apiResp deepseekResponse
+	if err := json.Unmarshal([]byte(raw), &apiResp); err != nil {
+		return "", "", 0, fmt.Errorf("decode api response: %w", err)
+	}
+	if len(apiResp.Choices) == 0 {
+		return "", "", 0, fmt.Errorf("api returned no choices")
+	}
+	msg := apiResp.Choices[0].Message
+	content, inline := splitReasoning(msg.Content)
+	reasoning = strings.TrimSpace(strings.Join([]string{msg.ReasoningContent, inline}, "\n\n"))
+	return content, reasoning, apiResp.Usage.TotalTokens, nil
 }
@@ -0,0 +1,50 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInvokeAPIStreamUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req deepseekRequest
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil || req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("request = %s, want stream_options.include_usage", body)
+		}
+		for _, chunk := range []string{
+			`{"choices":[{"delta":{"reasoning_content":"hmm"}}]}`,
+			`{"choices":[{"delta":{"content":"Hello"}}]}`,
+			`{"choices":[{"delta":{"content":" there"},"finish_reason":"stop"}]}`,
+			`{"choices":[],"usage":{"prompt_tokens":40,"completion_tokens":2,"total_tokens":42}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	}))
+	defer srv.Close()
+
+	body, err := json.Marshal(TaskParams{Model: "m"}.request([]deepseekMessage{{Role: "user", Content: "hi"}}, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasoning string
+	content, tokens, err := invokeAPIStream(context.Background(), srv.URL, "Bearer k", srv.Client(), body,
+		func(string) {}, func(d string) { reasoning += d })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content != "Hello there" || reasoning != "hmm" || tokens != 42 {
+		t.Errorf("content, reasoning, tokens = %q, %q, %d", content, reasoning, tokens)
+	}
+
+	// Non-streaming requests carry no stream_options.
+	if req := (TaskParams{}).request(nil, false); req.StreamOptions != nil {
+		t.Errorf("non-streaming request has stream_options")
+	}
+}
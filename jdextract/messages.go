@@ -0,0 +1,194 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// TaskMessage is the Config.Tasks key for job-aware messages.
+const TaskMessage = "message"
+
+// Job message kinds.
+const (
+	MessageThankYou = "thank-you"
+	MessageDecline  = "decline"
+	MessageAccept   = "accept"
+	MessageFeedback = "ask-for-feedback"
+	MessageWithdraw = "withdraw"
+)
+
+// messageKinds maps each kind to the instruction given to the model.
+var messageKinds = map[string]string{
+	MessageThankYou: "A thank-you note after an interview. Thank them for their time, recall one or two specifics from the conversation or the role, restate interest briefly, and keep it short.",
+	MessageDecline:  "Declining an offer. Be gracious and appreciative, give a brief reason only if the notes provide one, and leave the door open.",
+	MessageAccept:   "Accepting an offer. Express enthusiasm, confirm the role and any terms or start date given in the notes, and ask about next steps.",
+	MessageFeedback: "Asking for feedback after a rejection. Thank them, accept the decision without arguing, and ask politely for one or two points to improve on.",
+	MessageWithdraw: "Withdrawing from the process. Be courteous and brief, thank them for their time, and give a reason only if the notes provide one.",
+}
+
+// MessageKinds returns the supported job message kinds, sorted.
+func MessageKinds() []string {
+	kinds := make([]string, 0, len(messageKinds))
+	for k := range messageKinds {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// JobMessageInput is the material for a job-aware message.
+type JobMessageInput struct {
+	Kind           string
+	Company, Role  string
+	Status         string
+	JobDescription string
+	Contact        *ContactMeta // recipient; nil addresses the hiring team
+	Notes          string       // the candidate's own context, e.g. who they met
+}
+
+// GenerateJobMessage drafts a message of in.Kind about an application. It
+// uses the networking system prompt and response format, so the result has
+// the same fields as GenerateFollowup's.
+func GenerateJobMessage(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	promptConfig NetworkingPromptConfig,
+	in JobMessageInput,
+	onDelta func(string),
+) (*FollowupResult, error) {
+	instruction, ok := messageKinds[in.Kind]
+	if !ok {
+		return nil, fmt.Errorf("invalid message kind %q: must be one of %s", in.Kind, strings.Join(MessageKinds(), ", "))
+	}
+
+	today := currentDate()
+	var sb strings.Builder
+	if in.Contact != nil {
+		sb.WriteString(followupInput(*in.Contact, today))
+	} else {
+		fmt.Fprintf(&sb, "Today's date: %s\n\nRECIPIENT: no contact linked; address the hiring team.\n", today)
+	}
+	fmt.Fprintf(&sb, "\nAPPLICATION:\nCompany: %s\nRole: %s\n", in.Company, in.Role)
+	if in.Status != "" {
+		fmt.Fprintf(&sb, "Status: %s\n", in.Status)
+	}
+	if in.JobDescription != "" {
+		fmt.Fprintf(&sb, "\nJOB DESCRIPTION:\n%s\n", Sanitize(in.JobDescription))
+	}
+	if in.Notes != "" {
+		fmt.Fprintf(&sb, "\nCANDIDATE NOTES:\n%s\n", Sanitize(in.Notes))
+	}
+
+	data := PromptData{Contact: in.Contact, Today: today, Default: sb.String()}
+	if data.Contact == nil {
+		data.Contact = &ContactMeta{}
+	}
+	prompt, err := promptConfig.render(data)
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+	messages := []deepseekMessage{
+		{Role: "system", Content: prompt.SystemPrompt + "\n\nWrite this message: " + instruction + "\n\n" + networkingResponseFormat},
+		{Role: "user", Content: prompt.User},
+	}
+	content, _, _, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, nil)
+	if err != nil {
+		return nil, err
+	}
+	return parseFollowupResult(content)
+}
+
+// LinkedContacts returns the contacts linked to job id.
+func LinkedContacts(a *App, id string) ([]ContactMeta, error) {
+	contacts, err := ListContacts(a)
+	if err != nil {
+		return nil, err
+	}
+	var out []ContactMeta
+	for _, c := range contacts {
+		if slices.Contains(c.LinkedJobs, id) {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// errNotLinked is returned for a contact that is not linked to the job.
+var errNotLinked = errors.New("contact is not linked to job")
+
+// linkedContact returns contactID if it is linked to job id. An empty
+// contactID picks the job's only linked contact, or none if it has zero or
+// several.
+func linkedContact(a *App, id, contactID string) (*ContactMeta, error) {
+	linked, err := LinkedContacts(a, id)
+	if err != nil {
+		return nil, err
+	}
+	if contactID == "" {
+		if len(linked) == 1 {
+			return &linked[0], nil
+		}
+		return nil, nil
+	}
+	for i := range linked {
+		if linked[i].Dir == contactID {
+			return &linked[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: contact %q, job %q", errNotLinked, contactID, id)
+}
+
+// JobMessage drafts a message of kind for job id addressed to contactID (see
+// linkedContact). It returns the draft and the recipient's ID, which is ""
+// when the message addresses the hiring team.
+func (a *App) JobMessage(ctx context.Context, id, kind, contactID, notes string, onDelta func(string)) (*FollowupResult, string, error) {
+	if !ValidID(id) {
+		return nil, "", fmt.Errorf("invalid job id %q", id)
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return nil, "", fmt.Errorf("read meta: %w", err)
+	}
+	contact, err := linkedContact(a, id, contactID)
+	if err != nil {
+		return nil, "", err
+	}
+	in := JobMessageInput{Kind: kind, Company: meta.Company, Role: meta.Role, Status: meta.Status, Contact: contact, Notes: notes}
+	if jd, err := os.ReadFile(filepath.Join(a.Paths.Jobs, id, jdFile)); err == nil {
+		in.JobDescription = string(jd)
+	}
+
+	b := a.BackendFor(TaskMessage, nil)
+	result, err := GenerateJobMessage(ctx, b.Invoker, b.StreamInvoker, b.APIKey, b.Params, &a.Client, a.NetworkingPromptConfig, in, onDelta)
+	flushTranscripts(b.Transcript, filepath.Join(a.Paths.Jobs, id))
+	if err != nil {
+		return nil, "", err
+	}
+	if contact == nil {
+		return result, "", nil
+	}
+	return result, contact.Dir, nil
+}
+
+// SendJobMessage logs a sent job message to contactID's conversation via
+// SendFollowup. The contact must be linked to job id.
+func SendJobMessage(a *App, id, contactID string, input SendFollowupInput) (*ContactMeta, error) {
+	if contactID == "" {
+		return nil, fmt.Errorf("contact is required")
+	}
+	if _, err := linkedContact(a, id, contactID); err != nil {
+		return nil, err
+	}
+	return SendFollowup(a, contactID, input)
+}
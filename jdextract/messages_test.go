@@ -0,0 +1,73 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJobMessage(t *testing.T) {
+	var body string
+	invoker := func(_ context.Context, _ string, _ *http.Client, _ int, b json.RawMessage) (string, error) {
+		body = string(b)
+		return completionBody("<subject>Thank you</subject>\n<message>Thanks for your time today.</message>\n<channel>email</channel>\n<timing>within 2 days</timing>", "")
+	}
+	contact := ContactMeta{Name: "Sam Lee", Company: "Acme Corp"}
+	got, err := GenerateJobMessage(context.Background(), invoker, nil, "", TaskParams{Model: "m"}, nil, NetworkingPromptConfig{},
+		JobMessageInput{Kind: MessageThankYou, Company: "Acme Corp", Role: "Copywriter", JobDescription: sampleJD, Contact: &contact, Notes: "Met the content lead"}, nil)
+	if err != nil {
+		t.Fatalf("GenerateJobMessage: %v", err)
+	}
+	if got.Message != "Thanks for your time today." || got.Subject != "Thank you" || got.SuggestedNextDate == "" {
+		t.Errorf("result = %+v", got)
+	}
+	for _, want := range []string{"Sam Lee", "Role: Copywriter", "JOB DESCRIPTION", "Met the content lead", "thank-you note"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request missing %q", want)
+		}
+	}
+
+	if _, err := GenerateJobMessage(context.Background(), invoker, nil, "", TaskParams{Model: "m"}, nil, NetworkingPromptConfig{},
+		JobMessageInput{Kind: "apology"}, nil); err == nil {
+		t.Error("unknown kind: want error")
+	}
+}
+
+func TestSendJobMessage(t *testing.T) {
+	a := newTestApp(t)
+	job, err := a.Jobs.MkDir("acme-copywriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveJSON(filepath.Join(a.Paths.Jobs, job, "meta.json"), ApplicationMeta{Company: "Acme Corp", Role: "Copywriter"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	linked, err := CreateContact(a, ContactMeta{Name: "Sam Lee", LinkedJobs: []string{job}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := CreateContact(a, ContactMeta{Name: "Pat Kim"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := linkedContact(a, job, "")
+	if err != nil || c == nil || c.Dir != linked {
+		t.Fatalf("linkedContact(\"\") = %v, %v; want %s", c, err, linked)
+	}
+	in := SendFollowupInput{Content: "Thanks again.", Channel: "email"}
+	if _, err := SendJobMessage(a, job, other, in); !errors.Is(err, errNotLinked) {
+		t.Errorf("unlinked contact: err = %v, want errNotLinked", err)
+	}
+	updated, err := SendJobMessage(a, job, linked, in)
+	if err != nil {
+		t.Fatalf("SendJobMessage: %v", err)
+	}
+	if len(updated.Conversations) == 0 {
+		t.Error("message not logged to contact")
+	}
+}
@@ -0,0 +1,340 @@
+package jdextract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// PDFLayout is a page and type preset for PDF export. Sizes are in points.
+type PDFLayout struct {
+	Regular, Bold string  // standard 14 font names
+	Size          float64 // body text size
+	Leading       float64 // line height as a multiple of the text size
+	Margin        float64
+	Width, Height float64 // page size
+}
+
+// pdfLayouts are the PDF export presets.
+var pdfLayouts = map[string]PDFLayout{
+	"classic": {Regular: "Times-Roman", Bold: "Times-Bold", Size: 11, Leading: 1.2, Margin: 72, Width: 612, Height: 792},
+	"modern":  {Regular: "Helvetica", Bold: "Helvetica-Bold", Size: 10.5, Leading: 1.25, Margin: 54, Width: 612, Height: 792},
+	"compact": {Regular: "Helvetica", Bold: "Helvetica-Bold", Size: 9.5, Leading: 1.15, Margin: 36, Width: 612, Height: 792},
+	"a4":      {Regular: "Helvetica", Bold: "Helvetica-Bold", Size: 10.5, Leading: 1.25, Margin: 54, Width: 595.28, Height: 841.89},
+}
+
+// DefaultPDFLayout is used when neither the request nor Config.PDFLayout
+// names a preset.
+const DefaultPDFLayout = "modern"
+
+// PDFLayouts returns the names of the PDF layout presets, sorted.
+func PDFLayouts() []string {
+	names := make([]string, 0, len(pdfLayouts))
+	for name := range pdfLayouts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// pdfFont indexes the two fonts of a layout, named /F1 and /F2 in the PDF.
+type pdfFont int
+
+const (
+	fontRegular pdfFont = iota
+	fontBold
+)
+
+// pdfDoc lays out lines top to bottom, starting new pages as needed.
+type pdfDoc struct {
+	layout PDFLayout
+	widths [2]*fontMetrics
+	pages  []*bytes.Buffer
+	y      float64 // top of the next line
+}
+
+func (d *pdfDoc) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = d.layout.Height - d.layout.Margin
+}
+
+// ensure starts a new page unless h points fit above the bottom margin.
+func (d *pdfDoc) ensure(h float64) {
+	if len(d.pages) == 0 || d.y-h < d.layout.Margin {
+		d.newPage()
+	}
+}
+
+func (d *pdfDoc) textWidth(s string, f pdfFont, size float64) float64 {
+	var w float64
+	for _, r := range s {
+		w += float64(d.widths[f].width(r))
+	}
+	return w * size / 1000
+}
+
+// line writes s at x on the next line and moves down by the line height.
+func (d *pdfDoc) line(x float64, s string, f pdfFont, size float64) {
+	d.ensure(size * d.layout.Leading)
+	d.text(x, d.baseline(size), s, f, size)
+	d.y -= size * d.layout.Leading
+}
+
+// baseline is the text baseline of the next line at size, which sits
+// centered in its line height.
+func (d *pdfDoc) baseline(size float64) float64 {
+	h := size * d.layout.Leading
+	return d.y - h + (h-size)/2 + size*0.2
+}
+
+func (d *pdfDoc) text(x, y float64, s string, f pdfFont, size float64) {
+	page := d.pages[len(d.pages)-1]
+	fmt.Fprintf(page, "BT /F%d %.2f Tf %.2f %.2f Td (", f+1, size, x, y)
+	for _, r := range s {
+		b := winAnsi(r)
+		if b == '\\' || b == '(' || b == ')' {
+			page.WriteByte('\\')
+		}
+		page.WriteByte(b)
+	}
+	page.WriteString(") Tj ET\n")
+}
+
+// rule draws a thin line across the text width just below the last line.
+func (d *pdfDoc) rule() {
+	y := d.y + 1
+	fmt.Fprintf(d.pages[len(d.pages)-1], "0.5 w %.2f %.2f m %.2f %.2f l S\n",
+		d.layout.Margin, y, d.layout.Width-d.layout.Margin, y)
+}
+
+// wrap breaks s into lines no wider than width, at spaces where possible.
+func (d *pdfDoc) wrap(s string, f pdfFont, size, width float64) []string {
+	var lines []string
+	cur := ""
+	for _, word := range strings.Fields(s) {
+		next := word
+		if cur != "" {
+			next = cur + " " + word
+		}
+		if d.textWidth(next, f, size) <= width {
+			cur = next
+			continue
+		}
+		if cur != "" {
+			lines = append(lines, cur)
+		}
+		// Split words wider than the line, e.g. long URLs.
+		for d.textWidth(word, f, size) > width {
+			cut := 0
+			for i := range word {
+				if i > 0 && d.textWidth(word[:i], f, size) > width {
+					break
+				}
+				cut = i
+			}
+			if cut == 0 {
+				_, cut = utf8.DecodeRuneInString(word)
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		cur = word
+	}
+	if cur != "" {
+		lines = append(lines, cur)
+	}
+	return lines
+}
+
+// writePDF typesets blocks with layout and writes a PDF 1.4 file to w. It
+// returns the number of pages.
+func writePDF(w io.Writer, blocks []docBlock, layout PDFLayout) (int, error) {
+	d := &pdfDoc{layout: layout, widths: [2]*fontMetrics{standardFonts[layout.Regular], standardFonts[layout.Bold]}}
+	if d.widths[0] == nil || d.widths[1] == nil {
+		return 0, fmt.Errorf("unsupported font %q or %q", layout.Regular, layout.Bold)
+	}
+	s := layout.Size
+	left := layout.Margin
+	width := layout.Width - 2*layout.Margin
+	d.newPage()
+
+	for i, b := range blocks {
+		switch b.Kind {
+		case blockName:
+			d.line(left, b.Text, fontBold, s*1.9)
+			d.y -= s * 0.2
+		case blockContact:
+			for _, l := range d.wrap(b.Text, fontRegular, s*0.92, width) {
+				d.line(left, l, fontRegular, s*0.92)
+			}
+		case blockHeading:
+			// Keep the heading with the first line below it.
+			d.y -= s * 0.8
+			d.ensure((s*1.1 + s) * layout.Leading)
+			d.line(left, b.Text, fontBold, s*1.1)
+			d.rule()
+			d.y -= s * 0.3
+		case blockSubheading:
+			if i > 0 && blocks[i-1].Kind != blockHeading {
+				d.y -= s * 0.4
+			}
+			d.ensure(2 * s * layout.Leading)
+			for _, l := range d.wrap(b.Text, fontBold, s, width) {
+				d.line(left, l, fontBold, s)
+			}
+		case blockBullet:
+			indent := s * 1.2
+			for j, l := range d.wrap(b.Text, fontRegular, s, width-indent) {
+				if j == 0 {
+					d.ensure(s * layout.Leading)
+					d.text(left+s*0.3, d.baseline(s), "•", fontRegular, s)
+				}
+				d.line(left+indent, l, fontRegular, s)
+			}
+		default:
+			for _, l := range d.wrap(b.Text, fontRegular, s, width) {
+				d.line(left, l, fontRegular, s)
+			}
+			d.y -= s * 0.4
+		}
+	}
+
+	if err := writePDFObjects(w, d); err != nil {
+		return 0, err
+	}
+	return len(d.pages), nil
+}
+
+// writePDFObjects serializes the pages: the catalog is object 1, the page
+// tree 2, the fonts 3 and 4, then a page and its content stream per page.
+func writePDFObjects(w io.Writer, d *pdfDoc) error {
+	var buf bytes.Buffer
+	var offsets []int
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	for _, name := range []string{d.layout.Regular, d.layout.Bold} {
+		obj("<< /Type /Font /Subtype /Type1 /BaseFont /" + name + " /Encoding /WinAnsiEncoding >>")
+	}
+	for i, page := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			d.layout.Width, d.layout.Height, 6+2*i))
+		var z bytes.Buffer
+		zw := zlib.NewWriter(&z)
+		zw.Write(page.Bytes())
+		if err := zw.Close(); err != nil {
+			return fmt.Errorf("compress page %d: %w", i+1, err)
+		}
+		obj(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// winAnsiExtra maps the runes WinAnsiEncoding places in 0x80-0x9F.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B, 'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// winAnsi encodes r for a standard 14 font; runes outside the encoding
+// become "?".
+func winAnsi(r rune) byte {
+	switch {
+	case r == '\t':
+		return ' '
+	case r >= 0x20 && r < 0x7F, r >= 0xA0 && r <= 0xFF:
+		return byte(r)
+	}
+	if b, ok := winAnsiExtra[r]; ok {
+		return b
+	}
+	return '?'
+}
+
+// fontMetrics holds advance widths in 1/1000 em.
+type fontMetrics struct {
+	ascii   [95]uint16      // ' ' through '~'
+	extra   map[rune]uint16 // punctuation outside ASCII
+	average uint16          // everything else, e.g. accented letters
+}
+
+func (m *fontMetrics) width(r rune) uint16 {
+	if r == '\t' {
+		r = ' '
+	}
+	if r >= 0x20 && r < 0x7F {
+		return m.ascii[r-0x20]
+	}
+	if w, ok := m.extra[r]; ok {
+		return w
+	}
+	if winAnsi(r) == '?' {
+		return m.ascii['?'-0x20]
+	}
+	return m.average
+}
+
+var (
+	helveticaExtra = map[rune]uint16{'•': 350, '–': 556, '—': 1000, '‘': 222, '’': 222, '“': 333, '”': 333, '…': 1000, '€': 556, '™': 1000, '·': 278, '°': 400, '©': 737, '®': 737}
+	timesExtra     = map[rune]uint16{'•': 350, '–': 500, '—': 1000, '‘': 333, '’': 333, '“': 444, '”': 444, '…': 1000, '€': 500, '™': 980, '·': 250, '°': 400, '©': 760, '®': 760}
+)
+
+// standardFonts are the AFM widths of the standard 14 fonts the layouts
+// use.
+var standardFonts = map[string]*fontMetrics{
+	"Helvetica": {average: 556, extra: helveticaExtra, ascii: [95]uint16{
+		278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+		1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+		333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+		556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+	}},
+	"Helvetica-Bold": {average: 556, extra: helveticaExtra, ascii: [95]uint16{
+		278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278,
+		556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 333, 333, 584, 584, 584, 611,
+		975, 722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, 722, 778,
+		667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 333, 278, 333, 584, 556,
+		333, 556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, 611, 611,
+		611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, 389, 280, 389, 584,
+	}},
+	"Times-Roman": {average: 500, extra: timesExtra, ascii: [95]uint16{
+		250, 333, 408, 500, 500, 833, 778, 180, 333, 333, 500, 564, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 278, 278, 564, 564, 564, 444,
+		921, 722, 667, 667, 722, 611, 556, 722, 722, 333, 389, 722, 611, 889, 722, 722,
+		556, 722, 667, 556, 611, 722, 722, 944, 722, 722, 611, 333, 278, 333, 469, 500,
+		333, 444, 500, 444, 500, 444, 333, 500, 500, 278, 278, 500, 278, 778, 500, 500,
+		500, 500, 333, 389, 278, 500, 500, 722, 500, 500, 444, 480, 200, 480, 541,
+	}},
+	"Times-Bold": {average: 500, extra: timesExtra, ascii: [95]uint16{
+		250, 333, 555, 500, 500, 1000, 833, 278, 333, 333, 500, 570, 250, 333, 250, 278,
+		500, 500, 500, 500, 500, 500, 500, 500, 500, 500, 333, 333, 570, 570, 570, 500,
+		930, 722, 667, 722, 722, 667, 611, 778, 778, 389, 500, 778, 667, 944, 722, 778,
+		611, 778, 722, 556, 667, 722, 722, 1000, 722, 722, 667, 333, 278, 333, 581, 500,
+		333, 500, 556, 444, 556, 444, 333, 500, 556, 278, 333, 556, 278, 833, 556, 500,
+		556, 556, 444, 389, 333, 556, 500, 722, 500, 500, 444, 394, 220, 394, 520,
+	}},
+}
@@ -0,0 +1,114 @@
+package jdextract
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var pdfStreamRe = regexp.MustCompile(`(?s)stream\n(.*?)\nendstream`)
+
+// pdfText returns the decompressed content streams of a PDF written by
+// writePDF, after checking that each xref offset points at its object.
+func pdfText(t *testing.T, pdf []byte) string {
+	t.Helper()
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatal("missing PDF header or trailer")
+	}
+	start := bytes.LastIndex(pdf, []byte("startxref\n"))
+	xref, err := strconv.Atoi(strings.Fields(string(pdf[start+len("startxref\n"):]))[0])
+	if err != nil || !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref does not point at the xref table")
+	}
+	for i, line := range strings.Split(string(pdf[xref:]), "\n")[3:] {
+		if !strings.HasSuffix(line, " n ") {
+			break
+		}
+		off, _ := strconv.Atoi(line[:10])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[off:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[off:off+10])
+		}
+	}
+
+	var sb strings.Builder
+	for _, m := range pdfStreamRe.FindAllSubmatch(pdf, -1) {
+		zr, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		b, err := io.ReadAll(zr)
+		if err != nil {
+			t.Fatalf("content stream: %v", err)
+		}
+		sb.Write(b)
+	}
+	return sb.String()
+}
+
+func TestWritePDF(t *testing.T) {
+	var buf bytes.Buffer
+	pages, err := writePDF(&buf, parseDocument(sampleResume+"\nLed (and shipped) the café redesign"), pdfLayouts["classic"])
+	if err != nil {
+		t.Fatalf("writePDF: %v", err)
+	}
+	if pages != 1 {
+		t.Errorf("pages = %d, want 1", pages)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte("/BaseFont /Times-Roman /Encoding /WinAnsiEncoding")) {
+		t.Error("layout font not used")
+	}
+	text := pdfText(t, buf.Bytes())
+	for _, want := range []string{
+		"(JANE DOE) Tj",
+		"(PROFESSIONAL SUMMARY) Tj",
+		"(\x95) Tj",
+		"(Led \\(and shipped\\) the caf\xe9 redesign) Tj",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("content missing %q", want)
+		}
+	}
+}
+
+func TestWritePDFPages(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString(sampleResume + "\n\nEXPERIENCE\n")
+	for i := 0; i < 120; i++ {
+		sb.WriteString("• Delivered a measurable improvement to a process that mattered to the business and its customers\n")
+	}
+	var buf bytes.Buffer
+	pages, err := writePDF(&buf, parseDocument(sb.String()), pdfLayouts["modern"])
+	if err != nil {
+		t.Fatalf("writePDF: %v", err)
+	}
+	if pages < 2 {
+		t.Fatalf("pages = %d, want a page break", pages)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(fmt.Sprintf("/Count %d", pages))) {
+		t.Error("page tree count does not match")
+	}
+	pdfText(t, buf.Bytes())
+}
+
+func TestPDFWrap(t *testing.T) {
+	layout := pdfLayouts["modern"]
+	d := &pdfDoc{layout: layout, widths: [2]*fontMetrics{standardFonts[layout.Regular], standardFonts[layout.Bold]}}
+	text := "See https://example.com/" + strings.Repeat("a", 200) + " for details about the project"
+	lines := d.wrap(text, fontRegular, 10, 200)
+	if len(lines) < 3 {
+		t.Fatalf("lines = %q", lines)
+	}
+	for _, l := range lines {
+		if w := d.textWidth(l, fontRegular, 10); w > 200 {
+			t.Errorf("line %q is %.1fpt wide", l, w)
+		}
+	}
+	if got := strings.Join(lines, ""); strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(text, " ", "") {
+		t.Errorf("wrap lost text: %q", lines)
+	}
+}
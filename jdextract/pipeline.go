@@ -0,0 +1,233 @@
+package jdextract
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// pipelineDir is the job subdirectory holding intermediate pipeline outputs.
+const pipelineDir = "pipeline"
+
+const analyzePrompt = `Before anything is rewritten, map the job's requirements to the candidate's resume.
+List every requirement the role states or clearly implies: skills, tools, experience level, domain, and responsibilities. Mark each as met, partial, or missing and quote or closely paraphrase the resume evidence that supports it. Never invent evidence.
+
+Respond using exactly these XML tags, in this order:
+<company>company name</company>
+<role>role title</role>
+<score>integer 1-10</score>
+` + analysisFormat
+
+const tailorFormat = `Use the requirement mapping to decide what to emphasize and which keywords to mirror. Only use facts present in the base resume; requirements marked missing must not be claimed.
+
+Respond using exactly these XML tags, in this order:
+<resume>
+full tailored resume text
+</resume>
+<cover>
+tailored cover letter (include ONLY if a base cover letter was provided)
+</cover>`
+
+const critiquePrompt = `You are a strict reviewer of a tailored resume and cover letter. Compare them with the base resume and the job description and report concrete problems:
+- Fabrication: any employer, title, date, metric, credential, or skill not supported by the base resume.
+- Keyword coverage: requirements from the job description that the base resume supports but the tailored documents do not mention.
+- Length: sections or bullets that are padded, repetitive, or make the resume longer than the base resume.
+Write one issue per line with the fix to apply. If there are no issues, write NONE.
+
+Respond using exactly this XML tag:
+<critique>
+- issue :: fix
+</critique>`
+
+const revisePrompt = `A reviewer found the issues below in your draft. Apply every fix and return the complete revised documents in the same XML tags as before. Do not change anything the critique does not mention.
+
+CRITIQUE:
+%s`
+
+var (
+	requirementsTagRe = regexp.MustCompile(`(?s)<requirements>(.*?)</requirements>`)
+	critiqueTagRe     = regexp.MustCompile(`(?s)<critique>(.*?)</critique>`)
+)
+
+// Pass is one stage of GeneratePipeline and its repaired output, saved as
+// pipeline/<n>-<name>.txt in the job directory.
+type Pass struct {
+	Name   string
+	Output string
+}
+
+// Pipeline stage names, in order. Each also names the ProgressStage its
+// events are emitted under.
+var pipelinePasses = []struct {
+	name    string
+	stage   ProgressStage
+	message string
+}{
+	{"analyze", StageAnalyzing, "Mapping requirements to resume evidence\u2026"},
+	{"tailor", StageTailoring, "Writing tailored resume\u2026"},
+	{"critique", StageCritiquing, "Reviewing draft\u2026"},
+	{"revise", StageRevising, "Applying review\u2026"},
+}
+
+// pipeline carries the LLM plumbing shared by every pass.
+type pipeline struct {
+	invoker       LLMInvoker
+	streamInvoker StreamingLLMInvoker
+	apiKey        string
+	params        TaskParams
+	c             *http.Client
+	onProgress    func(ProgressEvent)
+	gen           *Generation
+}
+
+// run executes pass i: it announces the stage, streams content deltas under
+// the stage name, repairs the tagged output, and asks once for any required
+// tags still missing. The repaired output is recorded in gen.Passes.
+func (p *pipeline) run(ctx context.Context, i int, messages []deepseekMessage, tags []string, required ...string) (string, error) {
+	pass := pipelinePasses[i]
+	p.onProgress(ProgressEvent{Stage: pass.stage, Message: pass.message})
+	onDelta := func(d string) {
+		p.onProgress(ProgressEvent{Stage: pass.stage, Delta: d})
+	}
+	onReasoning := func(d string) {
+		p.onProgress(ProgressEvent{Stage: StageReasoning, Delta: d})
+	}
+	content, reasoning, tokens, err := complete(ctx, p.invoker, p.streamInvoker, p.apiKey, p.params, p.c, messages, onDelta, onReasoning)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", pass.name, err)
+	}
+	p.gen.Tokens += tokens
+	if reasoning != "" {
+		p.gen.Reasoning = strings.TrimSpace(p.gen.Reasoning + "\n\n[" + pass.name + "]\n" + reasoning)
+	}
+	content, notes := repairTagged(content, tags)
+	for _, n := range notes {
+		p.gen.Repairs = append(p.gen.Repairs, pass.name+": "+n)
+	}
+
+	var missing []string
+	for _, tag := range required {
+		if extractTag(tagRe(tag), content) == "" {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) > 0 && p.invoker != nil {
+		reply, tokens, err := requestMissingTags(ctx, p.invoker, p.apiKey, p.params, p.c, messages, content, missing, tags)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", pass.name, err)
+		}
+		p.gen.Tokens += tokens
+		p.gen.Repairs = append(p.gen.Repairs, fmt.Sprintf("%s: requested missing tags: %s", pass.name, strings.Join(missing, ", ")))
+		content = reply + "\n" + content // extraction takes the first match
+	}
+
+	p.gen.Passes = append(p.gen.Passes, Pass{Name: pass.name, Output: content})
+	return content, nil
+}
+
+// GeneratePipeline is the multi-pass alternative to GenerateAll. It takes the
+// same inputs and returns the same Generation, produced in four calls:
+//
+//  1. analyze  — extract company, role, score, and the fit analysis
+//  2. tailor   — write the resume (and cover) from that map
+//  3. critique — review the draft for fabrication, keyword coverage, and length
+//  4. revise   — apply the critique, continuing the tailor conversation
+//
+// The revise pass is skipped when the critique reports NONE. Each pass streams
+// content under its own ProgressStage (StageAnalyzing, …) through onProgress,
+// and its output is kept in Generation.Passes. instructions is added to the
+// tailor pass as in GenerateAll.
+func GeneratePipeline(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	nodes []JobDescriptionNode,
+	baseResume string,
+	baseCover *string,
+	promptConfig PromptConfig,
+	instructions string,
+	onProgress func(ProgressEvent),
+) (*Generation, error) {
+	data, err := tailorPromptData(nodes, baseResume, baseCover)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := promptConfig.render(data)
+	if err != nil {
+		return nil, fmt.Errorf("render prompt: %w", err)
+	}
+	prompt.addTasks(instructions)
+	input := prompt.User
+	gen := &Generation{}
+	p := &pipeline{invoker, streamInvoker, apiKey, params, c, onProgress, gen}
+	wantCover := baseCover != nil
+
+	// 1. Analyze
+	analysis, err := p.run(ctx, 0, []deepseekMessage{
+		{Role: "system", Content: prompt.SystemPrompt + "\n\n" + analyzePrompt},
+		{Role: "user", Content: input},
+	}, []string{"company", "role", "score", "subscores", "requirements"}, "company", "role")
+	if err != nil {
+		return nil, err
+	}
+	gen.extract(analysis, false)
+	if gen.Company == "" || gen.Role == "" {
+		return nil, fmt.Errorf("analyze: llm response missing required fields (company=%q role=%q)", gen.Company, gen.Role)
+	}
+	requirements := extractTag(requirementsTagRe, analysis)
+
+	// 2. Tailor
+	tailorMessages := []deepseekMessage{
+		{Role: "system", Content: prompt.SystemPrompt + "\n\n" + prompt.TaskList + "\n\n" + tailorFormat},
+		{Role: "user", Content: input + "\n\nREQUIREMENT MAPPING:\n" + requirements},
+	}
+	draft, err := p.run(ctx, 1, tailorMessages, []string{"resume", "cover"}, "resume")
+	if err != nil {
+		return nil, err
+	}
+	gen.extract(draft, wantCover)
+	if gen.Resume == "" {
+		return nil, fmt.Errorf("tailor: llm response missing resume")
+	}
+
+	// 3. Critique
+	var review strings.Builder
+	fmt.Fprintf(&review, "%s\n\nTAILORED RESUME:\n%s", input, gen.Resume)
+	if gen.Cover != nil {
+		fmt.Fprintf(&review, "\n\nTAILORED COVER LETTER:\n%s", *gen.Cover)
+	}
+	critiqueOut, err := p.run(ctx, 2, []deepseekMessage{
+		{Role: "system", Content: critiquePrompt},
+		{Role: "user", Content: review.String()},
+	}, []string{"critique"})
+	if err != nil {
+		return nil, err
+	}
+	critique := extractTag(critiqueTagRe, critiqueOut)
+	if critique == "" || strings.EqualFold(critique, "none") {
+		return gen, nil
+	}
+
+	// 4. Revise
+	revised, err := p.run(ctx, 3, append(tailorMessages,
+		deepseekMessage{Role: "assistant", Content: draft},
+		deepseekMessage{Role: "user", Content: fmt.Sprintf(revisePrompt, critique)},
+	), []string{"resume", "cover"}, "resume")
+	if err != nil {
+		return nil, err
+	}
+	if r := extractTag(resumeTagRe, revised); r != "" {
+		gen.Resume = r
+	} else {
+		gen.Repairs = append(gen.Repairs, "revise: no resume returned; kept draft")
+	}
+	if cv := extractTag(coverTagRe, revised); wantCover && cv != "" {
+		gen.Cover = &cv
+	}
+	return gen, nil
+}
@@ -0,0 +1,95 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// scriptedStream answers successive streaming calls with replies in order,
+// emitting each reply as a single delta.
+func scriptedStream(t *testing.T, replies []string, requests *[]deepseekRequest) StreamingLLMInvoker {
+	return func(_ context.Context, _ string, _ *http.Client, body json.RawMessage, onDelta, _ func(string)) (string, int, error) {
+		var req deepseekRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+		*requests = append(*requests, req)
+		if len(*requests) > len(replies) {
+			t.Fatalf("unexpected call %d", len(*requests))
+		}
+		reply := replies[len(*requests)-1]
+		onDelta(reply)
+		return reply, 0, nil
+	}
+}
+
+func TestGeneratePipeline(t *testing.T) {
+	replies := []string{
+		"<company>Acme Corp</company>\n<role>Senior Copywriter</role>\n<score>7</score>\n<subscores>\nskills: 6\n</subscores>\n<requirements>\nmet | B2B copy | Wrote B2B campaigns\nmissing | Kubernetes |\n</requirements>",
+		"<resume>\nJANE DOE\nKubernetes expert\n</resume>\n<cover>\nDear team,\n</cover>",
+		"<critique>\n- Claims Kubernetes :: remove it\n</critique>",
+		"<resume>\nJANE DOE\n</resume>\n<cover>\nDear team,\n</cover>",
+	}
+	var requests []deepseekRequest
+	cover := "Dear Hiring Manager,"
+	var stages []ProgressStage
+	gen, err := GeneratePipeline(context.Background(), nil, scriptedStream(t, replies, &requests), "", TaskParams{Model: "m"}, nil,
+		Parse(sampleJD), "JANE DOE", &cover, PromptConfig{}, "", func(e ProgressEvent) {
+			if e.Delta != "" {
+				stages = append(stages, e.Stage)
+			}
+		})
+	if err != nil {
+		t.Fatalf("GeneratePipeline: %v", err)
+	}
+	if gen.Company != "Acme Corp" || gen.Role != "Senior Copywriter" || gen.Score != 7 {
+		t.Errorf("unexpected analysis: %+v", gen)
+	}
+	if gen.Analysis == nil || gen.Analysis.SubScores.Skills != 6 || !gen.Analysis.Has(RequirementMissing, "kubernetes") {
+		t.Errorf("analysis = %+v", gen.Analysis)
+	}
+	if gen.Resume != "JANE DOE" {
+		t.Errorf("resume = %q, critique not applied", gen.Resume)
+	}
+	if gen.Cover == nil || *gen.Cover != "Dear team," {
+		t.Errorf("cover = %v", gen.Cover)
+	}
+	want := []ProgressStage{StageAnalyzing, StageTailoring, StageCritiquing, StageRevising}
+	if !slices.Equal(stages, want) {
+		t.Errorf("delta stages = %v, want %v", stages, want)
+	}
+	if len(gen.Passes) != 4 || gen.Passes[3].Name != "revise" {
+		t.Errorf("passes = %+v", gen.Passes)
+	}
+	if !strings.Contains(requests[1].Messages[1].Content, "missing | Kubernetes") {
+		t.Errorf("tailor pass did not receive the requirement mapping")
+	}
+	revise := requests[3].Messages
+	if len(revise) != 4 || !strings.Contains(revise[3].Content, "remove it") {
+		t.Errorf("revise pass should continue the tailor conversation with the critique, got %d messages", len(revise))
+	}
+}
+
+func TestGeneratePipelineSkipsReviseWhenClean(t *testing.T) {
+	replies := []string{
+		"<company>Acme Corp</company>\n<role>Senior Copywriter</role>\n<score>8</score>\n<requirements>\nmet | B2B copy | Wrote B2B campaigns\n</requirements>",
+		"<resume>\nJANE DOE\n</resume>",
+		"<critique>NONE</critique>",
+	}
+	var requests []deepseekRequest
+	gen, err := GeneratePipeline(context.Background(), nil, scriptedStream(t, replies, &requests), "", TaskParams{Model: "m"}, nil,
+		Parse(sampleJD), "JANE DOE", nil, PromptConfig{}, "", func(ProgressEvent) {})
+	if err != nil {
+		t.Fatalf("GeneratePipeline: %v", err)
+	}
+	if len(requests) != 3 || len(gen.Passes) != 3 {
+		t.Errorf("requests = %d, passes = %d; want 3 each", len(requests), len(gen.Passes))
+	}
+	if gen.Resume != "JANE DOE" || gen.Cover != nil {
+		t.Errorf("unexpected generation: %+v", gen)
+	}
+}
@@ -0,0 +1,197 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TaskPrep is the Config.Tasks key for interview prep generation.
+const TaskPrep = "prep"
+
+// prepFile is the per-job interview prep packet written by PrepJob.
+const prepFile = "prep.md"
+
+const prepPrompt = `You are an interview coach preparing a candidate for interviews for the role below. Base every answer outline on facts in the base resume; never invent experience. Where the resume has no evidence for a requirement, say so and suggest how to address the gap honestly.
+
+Respond using exactly these XML tags, in this order:
+<technical>
+one likely technical question per line: requirement | question
+</technical>
+<behavioral>
+one likely behavioral question per line: requirement | question
+</behavioral>
+<star>
+STAR answer outlines in Markdown: for each, a "### " heading naming the story, then Situation, Task, Action, and Result bullets drawn from the base resume
+</star>
+<ask>
+one question for the interviewer per line
+</ask>
+<redflags>
+one red flag or open question about the role or company to probe, per line
+</redflags>`
+
+var prepTags = []string{"technical", "behavioral", "star", "ask", "redflags"}
+
+// PrepInput is the material for an interview prep packet.
+type PrepInput struct {
+	Company, Role  string
+	JobDescription string
+	Resume         string // the tailored resume sent with the application
+	BaseResume     string // source of truth for STAR outlines
+	Requirements   []Requirement
+}
+
+// GeneratePrep asks the LLM for interview questions mapped to the job's
+// requirements, STAR outlines, questions to ask, and red flags, and renders
+// them as a Markdown packet. Streaming, reasoning, and tag repair behave as
+// in GenerateAll; a response without any questions gets one repair turn.
+func GeneratePrep(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	in PrepInput,
+	onDelta func(string),
+	onReasoning func(string),
+) (string, error) {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "COMPANY: %s\nROLE: %s\n\nJOB DESCRIPTION:\n%s\n\n", in.Company, in.Role, Sanitize(in.JobDescription))
+	if len(in.Requirements) > 0 {
+		sb.WriteString("REQUIREMENT MAPPING:\n")
+		for _, r := range in.Requirements {
+			fmt.Fprintf(&sb, "%s | %s | %s\n", r.Status, r.Text, r.Evidence)
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "BASE RESUME:\n%s\n\nTAILORED RESUME:\n%s", Sanitize(in.BaseResume), Sanitize(in.Resume))
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: prepPrompt},
+		{Role: "user", Content: sb.String()},
+	}
+	content, _, _, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, onReasoning)
+	if err != nil {
+		return "", err
+	}
+	content, _ = repairTagged(content, prepTags)
+
+	sections := extractTags(content, prepTags)
+	if sections["technical"] == "" && sections["behavioral"] == "" && invoker != nil {
+		reply, _, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, []string{"technical", "behavioral"}, prepTags)
+		if err != nil {
+			return "", err
+		}
+		for tag, v := range extractTags(reply, prepTags) {
+			if sections[tag] == "" {
+				sections[tag] = v
+			}
+		}
+	}
+	if sections["technical"] == "" && sections["behavioral"] == "" {
+		return "", fmt.Errorf("llm response missing interview questions")
+	}
+	return renderPrep(in.Company, in.Role, sections), nil
+}
+
+// extractTags returns the trimmed content of each tag in s; absent tags map
+// to "".
+func extractTags(s string, tags []string) map[string]string {
+	out := make(map[string]string, len(tags))
+	for _, tag := range tags {
+		out[tag] = extractTag(tagRe(tag), s)
+	}
+	return out
+}
+
+// renderPrep lays out the tagged sections as prep.md. Question lines of the
+// form "requirement | question" become "- question *(requirement)*".
+func renderPrep(company, role string, sections map[string]string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Interview prep: %s at %s\n", role, company)
+	for _, s := range []struct{ tag, title string }{
+		{"technical", "Technical questions"},
+		{"behavioral", "Behavioral questions"},
+		{"star", "STAR outlines"},
+		{"ask", "Questions to ask"},
+		{"redflags", "Red flags to probe"},
+	} {
+		body := sections[s.tag]
+		if body == "" {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n## %s\n\n", s.title)
+		if s.tag == "star" {
+			sb.WriteString(body + "\n")
+			continue
+		}
+		for _, line := range strings.Split(body, "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+			if line == "" {
+				continue
+			}
+			if req, q, ok := strings.Cut(line, "|"); ok && (s.tag == "technical" || s.tag == "behavioral") {
+				fmt.Fprintf(&sb, "- %s *(%s)*\n", strings.TrimSpace(q), strings.TrimSpace(req))
+			} else {
+				fmt.Fprintf(&sb, "- %s\n", line)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// PrepJob generates prep.md for a job from its stored JD, tailored resume,
+// base template, and fit analysis, and returns the file's path. Progress,
+// content deltas, and reasoning go to onProgress.
+func (a *App) PrepJob(ctx context.Context, id string, onProgress func(ProgressEvent)) (string, error) {
+	if !ValidID(id) {
+		return "", fmt.Errorf("invalid job id %q", id)
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return "", fmt.Errorf("read meta: %w", err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	jd, err := os.ReadFile(filepath.Join(dir, jdFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("job has no stored job description")
+		}
+		return "", fmt.Errorf("read %s: %w", jdFile, err)
+	}
+	resume, err := os.ReadFile(filepath.Join(dir, "resume.txt"))
+	if err != nil {
+		return "", fmt.Errorf("read resume.txt: %w", err)
+	}
+	in := PrepInput{Company: meta.Company, Role: meta.Role, JobDescription: string(jd), Resume: string(resume)}
+	if base, err := LoadTemplates(a, meta.Template); err == nil {
+		in.BaseResume = base.Resume
+	}
+	if f, err := GetAnalysis(a, id); err == nil {
+		in.Requirements = f.Requirements
+	}
+
+	b := a.BackendFor(TaskPrep, onProgress)
+	onProgress(ProgressEvent{Stage: StageGenerating, Message: "Preparing interview packet\u2026"})
+	prep, err := GeneratePrep(ctx, b.Invoker, b.StreamInvoker, b.APIKey, b.Params, &a.Client, in,
+		func(d string) { onProgress(ProgressEvent{Stage: StageContent, Delta: d}) },
+		func(d string) { onProgress(ProgressEvent{Stage: StageReasoning, Delta: d}) },
+	)
+	flushTranscripts(b.Transcript, dir)
+	if err != nil {
+		return "", fmt.Errorf("prep: %w", err)
+	}
+
+	onProgress(ProgressEvent{Stage: StageSaving, Message: "Saving prep.md\u2026"})
+	path := filepath.Join(dir, prepFile)
+	if err := os.WriteFile(path, []byte(prep), 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", prepFile, err)
+	}
+	return path, nil
+}
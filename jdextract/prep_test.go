@@ -0,0 +1,45 @@
+package jdextract
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePrep(t *testing.T) {
+	reply := `<technical>
+- B2B copywriting | Walk us through a B2B campaign you wrote.
+</technical>
+<behavioral>
+Stakeholders | Tell us about a time a client rejected your draft.
+</behavioral>
+<star>
+### Newsletter growth
+- Situation: flat subscriber numbers
+</star>
+<ask>
+- How is success measured in the first 90 days?
+</ask>`
+	var calls int
+	got, err := GeneratePrep(context.Background(), fakeInvoker(reply, &calls), nil, "", TaskParams{Model: "m"}, nil,
+		PrepInput{Company: "Acme Corp", Role: "Copywriter", JobDescription: sampleJD, Resume: "JANE DOE"}, nil, nil)
+	if err != nil {
+		t.Fatalf("GeneratePrep: %v", err)
+	}
+	for _, want := range []string{
+		"# Interview prep: Copywriter at Acme Corp\n",
+		"## Technical questions\n\n- Walk us through a B2B campaign you wrote. *(B2B copywriting)*\n",
+		"## STAR outlines\n\n### Newsletter growth\n",
+		"## Questions to ask\n\n- How is success measured in the first 90 days?\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("prep.md missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "Red flags") {
+		t.Error("empty section rendered")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
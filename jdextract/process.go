@@ -6,9 +6,13 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
 )
 
+// batchConcurrency caps how many URLs a batch fetches and processes at once.
+// LLM calls are additionally governed by the backend's shared Limiter.
 const batchConcurrency = 10
 
 // BatchResult holds the outcome of a single URL in a batch run.
@@ -21,7 +25,7 @@ type BatchResult struct {
 // ProcessBatch fetches and processes each URL concurrently (capped at batchConcurrency).
 // Results are streamed to the returned channel as they complete; the channel is closed
 // when all URLs are done. A failed URL does not affect the others.
-func (a *App) ProcessBatch(ctx context.Context, urls []string) <-chan BatchResult {
+func (a *App) ProcessBatch(ctx context.Context, urls []string, opts ProcessOptions) <-chan BatchResult {
 	ch := make(chan BatchResult, len(urls))
 	sem := make(chan struct{}, batchConcurrency)
 	var wg sync.WaitGroup
@@ -38,7 +42,7 @@ func (a *App) ProcessBatch(ctx context.Context, urls []string) <-chan BatchResul
 				ch <- BatchResult{URL: url, Err: fmt.Errorf("fetch: %w", err)}
 				return
 			}
-			dir, err := a.Process(ctx, raw)
+			dir, err := a.ProcessWithOptions(ctx, raw, opts, func(_ ProgressEvent) {})
 			ch <- BatchResult{URL: url, Dir: dir, Err: err}
 		}(url)
 	}
@@ -51,13 +55,38 @@ func (a *App) ProcessBatch(ctx context.Context, urls []string) <-chan BatchResul
 	return ch
 }
 
+// ProcessOptions are per-run overrides of the configured generation behaviour.
+type ProcessOptions struct {
+	Pipeline bool   // multi-pass GeneratePipeline instead of a single GenerateAll call; ignored for a profile
+	Template string // base template name, "auto", or empty for the profile or default (see SelectTemplates)
+
+	// PromptProfile names the prompt profile; empty lets the experiment
+	// mode choose (see AssignPromptProfile).
+	PromptProfile string
+
+	// Language overrides the detected posting language (an ISO 639-1 code
+	// such as "de"); Config.Languages decides the output language.
+	Language string
+
+	// Tone overrides Config.CoverTone with a cover letter tone preset.
+	Tone string
+}
+
+// DefaultProcessOptions returns the options implied by the current config.
+func (a *App) DefaultProcessOptions() ProcessOptions {
+	return ProcessOptions{Pipeline: a.Config.Pipeline}
+}
+
 // Process runs the full generation pipeline for a single job description and
 // returns the path to the output directory. rawText may come from any source
 // (URL fetch, local file, or stdin) — routing is the caller's responsibility.
 //
-// Pipeline: Parse → load templates → GenerateAll (LLM) → create directory → write files.
-// The LLM call is the only expensive step; no filesystem writes happen before it
-// succeeds, so a failed generation leaves no partial state on disk.
+// Pipeline: Parse → detect language → select templates → GenerateFromProfile,
+// GeneratePipeline, or GenerateAll (LLM) → length constraints → verification →
+// create directory → write files, including the ATS check.
+// The LLM call is the only expensive step; no job files are written before it
+// succeeds, so a failed generation leaves no partial job on disk. Only its
+// transcripts, when enabled, are kept under data/llm/ for debugging.
 func (a *App) Process(ctx context.Context, rawText string) (string, error) {
 	return a.ProcessWithProgress(ctx, rawText, func(_ ProgressEvent) {})
 }
@@ -65,63 +94,219 @@ func (a *App) Process(ctx context.Context, rawText string) (string, error) {
 // ProcessWithProgress is like Process but calls onProgress at each pipeline stage.
 // During LLM generation, it also emits StageContent events with incremental text.
 func (a *App) ProcessWithProgress(ctx context.Context, rawText string, onProgress func(ProgressEvent)) (string, error) {
+	return a.ProcessWithOptions(ctx, rawText, a.DefaultProcessOptions(), onProgress)
+}
+
+// ProcessWithOptions is like ProcessWithProgress with explicit options. In
+// pipeline mode the intermediate pass outputs are saved under pipeline/.
+func (a *App) ProcessWithOptions(ctx context.Context, rawText string, opts ProcessOptions, onProgress func(ProgressEvent)) (string, error) {
 	onProgress(ProgressEvent{Stage: StageParsing, Message: "Parsing job description\u2026"})
 	nodes := Parse(rawText)
 
-	baseResume, err := fetchResume(a)
+	lang, err := a.planLanguage(opts.Language, nodeText(nodes))
 	if err != nil {
 		return "", err
 	}
+	template := opts.Template
+	if template == "" && lang.Template != "" {
+		template = lang.Template
+	}
+	base, err := SelectTemplates(a, template, nodes)
+	if err != nil {
+		return "", err
+	}
+	if base.Name != lang.Template {
+		lang.Template = "" // an explicit template is translated like the default
+	}
 
-	var baseCover *string
-	if c, err := fetchCover(a); err == nil {
-		baseCover = &c
+	promptProfile, promptConfig, err := a.AssignPromptProfile(opts.PromptProfile)
+	if err != nil {
+		return "", err
+	}
+	// The language and cover lines carry config and cover block text, so they
+	// are added after the prompt templates are rendered, never parsed as one.
+	var instructions []string
+	if line := lang.instruction(); line != "" {
+		instructions = append(instructions, line)
+	}
+	var coverTone string
+	var coverBlocks []string
+	if base.Cover != nil {
+		var line string
+		line, coverTone, coverBlocks, err = a.planCover(opts.Tone, nodes)
+		if err != nil {
+			return "", err
+		}
+		if line != "" {
+			instructions = append(instructions, line)
+		}
 	}
 
-	b := a.Backend()
+	extra := strings.Join(instructions, "\n")
+	b := a.BackendFor(TaskTailor, onProgress)
 
-	onProgress(ProgressEvent{Stage: StageGenerating, Message: "Generating tailored resume\u2026"})
 	onDelta := func(delta string) {
 		onProgress(ProgressEvent{Stage: StageContent, Delta: delta})
 	}
-	company, role, resume, cover, score, tokens, err := GenerateAll(
-		ctx,
-		b.Invoker,
-		b.StreamInvoker,
-		b.APIKey,
-		b.Model,
-		&a.Client,
-		nodes,
-		baseResume,
-		baseCover,
-		a.PromptConfig,
-		onDelta,
-	)
+	onReasoning := func(delta string) {
+		onProgress(ProgressEvent{Stage: StageReasoning, Delta: delta})
+	}
+	var gen *Generation
+	var sel *ProfileSelection
+	switch {
+	case base.Profile != nil:
+		// The pipeline rewrites the resume freely, which would undo the
+		// profile's fixed titles, employers, and dates.
+		onProgress(ProgressEvent{Stage: StageGenerating, Message: "Selecting profile entries\u2026"})
+		gen, sel, err = GenerateFromProfile(
+			ctx,
+			b.Invoker,
+			b.StreamInvoker,
+			b.APIKey,
+			b.Params,
+			&a.Client,
+			nodes,
+			base.Profile,
+			base.Cover,
+			promptConfig,
+			extra,
+			onDelta,
+			onReasoning,
+		)
+	case opts.Pipeline:
+		gen, err = GeneratePipeline(
+			ctx,
+			b.Invoker,
+			b.StreamInvoker,
+			b.APIKey,
+			b.Params,
+			&a.Client,
+			nodes,
+			base.Resume,
+			base.Cover,
+			promptConfig,
+			extra,
+			onProgress,
+		)
+	default:
+		onProgress(ProgressEvent{Stage: StageGenerating, Message: "Generating tailored resume\u2026"})
+		gen, err = GenerateAll(
+			ctx,
+			b.Invoker,
+			b.StreamInvoker,
+			b.APIKey,
+			b.Params,
+			&a.Client,
+			nodes,
+			base.Resume,
+			base.Cover,
+			promptConfig,
+			extra,
+			onDelta,
+			onReasoning,
+		)
+	}
 	if err != nil {
+		// No job directory exists yet; keep the failed transcript under data/llm/.
+		flushTranscripts(b.Transcript, a.Paths.Data)
 		return "", fmt.Errorf("generate: %w", err)
 	}
 
+	if opts.Pipeline && base.Profile != nil {
+		gen.Repairs = append(gen.Repairs, "pipeline: skipped, the resume is assembled from the profile")
+	}
+	if lang.Job != LanguageEnglish {
+		gen.Repairs = append(gen.Repairs, fmt.Sprintf("language: posting in %s, written in %s", LanguageName(lang.Job), LanguageName(lang.Output)))
+	}
+	constraints, shortenLog := a.enforceConstraints(ctx, rawText, gen, base.Profile, sel, onProgress)
+	verification, verifyLog := a.verify(ctx, base.Resume, gen, lang.translated(), onProgress)
+
 	onProgress(ProgressEvent{Stage: StageSaving, Message: "Saving files\u2026"})
 	slug := slugify(nodes)
 	slug, err = a.Jobs.MkDir(slug)
 	if err != nil {
+		flushTranscripts(b.Transcript, a.Paths.Data)
+		flushTranscripts(shortenLog, a.Paths.Data)
+		flushTranscripts(verifyLog, a.Paths.Data)
 		return "", fmt.Errorf("create directory: %w", err)
 	}
 	dir := filepath.Join(a.Paths.Jobs, slug)
+	flushTranscripts(b.Transcript, dir)
+	flushTranscripts(shortenLog, dir)
+	flushTranscripts(verifyLog, dir)
+
+	if err := os.WriteFile(filepath.Join(dir, jdFile), []byte(rawText), 0644); err != nil {
+		return "", fmt.Errorf("write job description: %w", err)
+	}
 
-	if err := os.WriteFile(filepath.Join(dir, "resume.txt"), []byte(resume), 0644); err != nil {
+	if err := os.WriteFile(filepath.Join(dir, "resume.txt"), []byte(gen.Resume), 0644); err != nil {
 		return "", fmt.Errorf("write resume: %w", err)
 	}
 
-	if cover != nil {
-		if err := os.WriteFile(filepath.Join(dir, "cover.txt"), []byte(*cover), 0644); err != nil {
+	if gen.Cover != nil {
+		if err := os.WriteFile(filepath.Join(dir, "cover.txt"), []byte(*gen.Cover), 0644); err != nil {
 			return "", fmt.Errorf("write cover: %w", err)
 		}
 	}
 
+	if a.Config.SaveReasoning && gen.Reasoning != "" {
+		if err := os.WriteFile(filepath.Join(dir, "reasoning.txt"), []byte(gen.Reasoning), 0644); err != nil {
+			return "", fmt.Errorf("write reasoning: %w", err)
+		}
+	}
+
+	if gen.Analysis != nil {
+		gen.Analysis.Score = gen.Score
+		if err := SaveJSON(filepath.Join(dir, analysisFile), gen.Analysis, 0644); err != nil {
+			return "", fmt.Errorf("write analysis: %w", err)
+		}
+	}
+
+	if err := SaveJSON(filepath.Join(dir, verificationFile), verification, 0644); err != nil {
+		return "", fmt.Errorf("write verification: %w", err)
+	}
+
+	if constraints != nil {
+		if err := SaveJSON(filepath.Join(dir, constraintsFile), constraints, 0644); err != nil {
+			return "", fmt.Errorf("write constraints: %w", err)
+		}
+	}
+
+	ats := CheckATS(nodes, gen.Resume)
+	if err := SaveJSON(filepath.Join(dir, atsFile), ats, 0644); err != nil {
+		return "", fmt.Errorf("write ats check: %w", err)
+	}
+
+	if len(gen.Passes) > 0 {
+		if err := writePasses(dir, gen.Passes); err != nil {
+			return "", err
+		}
+	}
+
 	date := currentDate()
 
-	meta := ApplicationMeta{Company: company, Role: role, Score: score, Tokens: tokens, Date: date}
+	meta := ApplicationMeta{
+		Company:    gen.Company,
+		Role:       gen.Role,
+		Score:      gen.Score,
+		Tokens:     gen.Tokens,
+		Date:       date,
+		Provenance: gen.Repairs,
+		Template:   base.Name,
+		Warnings:   verification.Warnings(),
+
+		PromptProfile: promptProfile,
+		Language:      lang.Output,
+		ATSCoverage:   &ats.Coverage,
+	}
+	if gen.Cover != nil {
+		meta.CoverBlocks, meta.CoverTone = coverBlocks, coverTone
+	}
+	if sel != nil {
+		for _, e := range sel.Entries {
+			meta.ProfileEntries = append(meta.ProfileEntries, e.ID)
+		}
+	}
 	metaBytes, err := json.Marshal(meta)
 	if err != nil {
 		return "", fmt.Errorf("marshal meta: %w", err)
@@ -132,3 +317,50 @@ func (a *App) ProcessWithProgress(ctx context.Context, rawText string, onProgres
 
 	return dir, nil
 }
+
+// verify runs the fabrication check on gen.Resume, followed by the LLM
+// second opinion when Config.VerifyLLM is set. A translated resume is only
+// checked for numbers, percentages, and dates. Remaining findings are emitted
+// as a StageWarning event. A failed review keeps the deterministic findings
+// and is noted in gen.Repairs. The review's transcripts, if any, are returned
+// for the caller to flush into the job directory.
+func (a *App) verify(ctx context.Context, baseResume string, gen *Generation, translated bool, onProgress func(ProgressEvent)) (*Verification, *TranscriptRecorder) {
+	onProgress(ProgressEvent{Stage: StageVerifying, Message: "Checking claims against base resume\u2026"})
+	v := &Verification{Findings: VerifyClaims(baseResume, gen.Resume, gen.Company, gen.Role)}
+	if translated {
+		// Translated wording cannot be matched literally; keep the figures.
+		v.Findings = slices.DeleteFunc(v.Findings, func(f Finding) bool {
+			return f.Kind == FindingName || f.Kind == FindingTerm
+		})
+	}
+	var rec *TranscriptRecorder
+	if a.Config.VerifyLLM && len(v.Findings) > 0 {
+		b := a.BackendFor(TaskVerify, onProgress)
+		rec = b.Transcript
+		reviewed, err := ReviewFindings(ctx, b.Invoker, b.APIKey, b.Params, &a.Client, baseResume, gen.Resume, v.Findings)
+		if err != nil {
+			gen.Repairs = append(gen.Repairs, "verify: llm review failed: "+err.Error())
+		} else {
+			v.Findings, v.Reviewed = reviewed, true
+		}
+	}
+	if w := v.Warnings(); len(w) > 0 {
+		onProgress(ProgressEvent{Stage: StageWarning, Message: fmt.Sprintf("%d claim(s) not found in base resume", len(w)), Warnings: w})
+	}
+	return v, rec
+}
+
+// writePasses saves each pipeline pass output as pipeline/<n>-<name>.txt.
+func writePasses(dir string, passes []Pass) error {
+	out := filepath.Join(dir, pipelineDir)
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("create pipeline directory: %w", err)
+	}
+	for i, p := range passes {
+		name := fmt.Sprintf("%d-%s.txt", i+1, p.Name)
+		if err := os.WriteFile(filepath.Join(out, name), []byte(p.Output), 0644); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	return nil
+}
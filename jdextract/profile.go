@@ -0,0 +1,414 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// profileFile is the optional structured experience bank in config/.
+const profileFile = "profile.json"
+
+// Profile is a structured resume: roles with fixed titles, employers, and
+// dates, each with accomplishment entries the model may select and rephrase
+// but never invent.
+type Profile struct {
+	Name      string             `json:"name"`
+	Contact   []string           `json:"contact,omitempty"` // lines under the name
+	Summary   string             `json:"summary,omitempty"` // used when the model writes none
+	Roles     []ProfileRole      `json:"roles"`
+	Education []ProfileEducation `json:"education,omitempty"`
+	Skills    []SkillGroup       `json:"skills,omitempty"`
+}
+
+// ProfileRole is a position. Start and End are printed as written; an empty
+// End prints "Present".
+type ProfileRole struct {
+	Title    string         `json:"title"`
+	Employer string         `json:"employer"`
+	Location string         `json:"location,omitempty"`
+	Start    string         `json:"start"`
+	End      string         `json:"end,omitempty"`
+	Entries  []ProfileEntry `json:"entries"`
+}
+
+// ProfileEntry is one accomplishment. ID must be unique across the profile.
+type ProfileEntry struct {
+	ID      string   `json:"id"`
+	Text    string   `json:"text"`
+	Tags    []string `json:"tags,omitempty"`
+	Metrics []string `json:"metrics,omitempty"` // figures the rephrasing must keep, e.g. "40%"
+	Skills  []string `json:"skills,omitempty"`
+}
+
+// ProfileEducation is a degree or certificate.
+type ProfileEducation struct {
+	Degree  string   `json:"degree"`
+	School  string   `json:"school"`
+	Year    string   `json:"year,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+// SkillGroup is a labelled skills line, e.g. "Tools: Figma, Jira".
+type SkillGroup struct {
+	Label string   `json:"label"`
+	Items []string `json:"items"`
+}
+
+// LoadProfile reads config/profile.json. A missing profile returns nil and no
+// error.
+func LoadProfile(a *App) (*Profile, error) {
+	p, err := LoadJSON[Profile](filepath.Join(a.Paths.Config, profileFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read %s: %w", profileFile, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", profileFile, err)
+	}
+	return p, nil
+}
+
+// Validate checks that the profile has a name and roles, and that every entry
+// has text and a unique single-line ID.
+func (p *Profile) Validate() error {
+	if p.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Roles) == 0 {
+		return fmt.Errorf("at least one role is required")
+	}
+	seen := map[string]bool{}
+	for _, r := range p.Roles {
+		if r.Title == "" || r.Employer == "" {
+			return fmt.Errorf("every role needs a title and employer")
+		}
+		for _, e := range r.Entries {
+			if e.ID == "" || strings.ContainsAny(e.ID, "|\n") {
+				return fmt.Errorf("invalid entry id %q in %s at %s", e.ID, r.Title, r.Employer)
+			}
+			if seen[e.ID] {
+				return fmt.Errorf("duplicate entry id %q", e.ID)
+			}
+			if strings.TrimSpace(e.Text) == "" {
+				return fmt.Errorf("entry %q has no text", e.ID)
+			}
+			seen[e.ID] = true
+		}
+	}
+	return nil
+}
+
+// entry returns the entry with id and the index of its role.
+func (p *Profile) entry(id string) (*ProfileEntry, int) {
+	for i := range p.Roles {
+		for j := range p.Roles[i].Entries {
+			if p.Roles[i].Entries[j].ID == id {
+				return &p.Roles[i].Entries[j], i
+			}
+		}
+	}
+	return nil, -1
+}
+
+// ProfileSelection is the model's choice from a profile.
+type ProfileSelection struct {
+	Summary string
+	Entries []SelectedEntry // in the model's order of relevance
+	Skills  []string        // nil keeps every skill
+}
+
+// SelectedEntry is a chosen entry, lightly rephrased.
+type SelectedEntry struct {
+	ID   string
+	Text string
+}
+
+// Text renders the whole profile as a plain-text resume: every entry as
+// written. It is the base resume for verification, revision, and prep.
+func (p *Profile) Text() string {
+	sel := ProfileSelection{Summary: p.Summary}
+	for _, r := range p.Roles {
+		for _, e := range r.Entries {
+			sel.Entries = append(sel.Entries, SelectedEntry{ID: e.ID, Text: e.Text})
+		}
+	}
+	return AssembleResume(p, sel)
+}
+
+// AssembleResume lays out a resume from the profile and a selection in a
+// fixed format. Names, titles, employers, dates, and education come only
+// from the profile. Roles appear in profile order with their selected
+// entries in selection order; a role with none selected keeps its first
+// entry as written so that the work history has no gaps.
+func AssembleResume(p *Profile, sel ProfileSelection) string {
+	byRole := make([][]string, len(p.Roles))
+	for _, s := range sel.Entries {
+		if _, i := p.entry(s.ID); i >= 0 {
+			byRole[i] = append(byRole[i], s.Text)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(p.Name + "\n")
+	for _, c := range p.Contact {
+		sb.WriteString(c + "\n")
+	}
+	if summary := strings.TrimSpace(sel.Summary); summary != "" {
+		sb.WriteString("\nPROFESSIONAL SUMMARY\n" + summary + "\n")
+	}
+
+	sb.WriteString("\nEXPERIENCE\n")
+	for i, r := range p.Roles {
+		fields := []string{r.Title, r.Employer}
+		if r.Location != "" {
+			fields = append(fields, r.Location)
+		}
+		end := r.End
+		if end == "" {
+			end = "Present"
+		}
+		fields = append(fields, r.Start+" - "+end)
+		sb.WriteString("\n" + strings.Join(fields, " | ") + "\n")
+		bullets := byRole[i]
+		if len(bullets) == 0 && len(r.Entries) > 0 {
+			bullets = []string{r.Entries[0].Text}
+		}
+		for _, b := range bullets {
+			sb.WriteString("• " + b + "\n")
+		}
+	}
+
+	if len(p.Education) > 0 {
+		sb.WriteString("\nEDUCATION\n")
+		for _, e := range p.Education {
+			fields := []string{e.Degree, e.School}
+			if e.Year != "" {
+				fields = append(fields, e.Year)
+			}
+			sb.WriteString(strings.Join(fields, " | ") + "\n")
+			for _, d := range e.Details {
+				sb.WriteString("• " + d + "\n")
+			}
+		}
+	}
+
+	var skills []string
+	for _, g := range p.Skills {
+		items := g.Items
+		if sel.Skills != nil {
+			items = nil
+			for _, it := range g.Items {
+				if containsFold(sel.Skills, it) {
+					items = append(items, it)
+				}
+			}
+		}
+		if len(items) > 0 {
+			skills = append(skills, "• "+g.Label+": "+strings.Join(items, ", "))
+		}
+	}
+	if len(skills) > 0 {
+		sb.WriteString("\nSKILLS\n" + strings.Join(skills, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), strings.TrimSpace(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// profileInput is the default user message for GenerateFromProfile: the job
+// description, then every entry with its ID, tags, metrics, and skills under
+// its role, then the skills and base cover letter.
+func profileInput(d PromptData, p *Profile, withCover bool) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "JOB DESCRIPTION:\n%s\n\nEXPERIENCE BANK:\n", d.Job.JSON)
+	for _, r := range p.Roles {
+		fmt.Fprintf(&sb, "\n%s at %s (%s - %s)\n", r.Title, r.Employer, r.Start, r.End)
+		for _, e := range r.Entries {
+			fmt.Fprintf(&sb, "%s | %s", e.ID, Sanitize(e.Text))
+			for _, f := range []struct {
+				label string
+				v     []string
+			}{{"tags", e.Tags}, {"metrics", e.Metrics}, {"skills", e.Skills}} {
+				if len(f.v) > 0 {
+					fmt.Fprintf(&sb, " [%s: %s]", f.label, strings.Join(f.v, ", "))
+				}
+			}
+			sb.WriteString("\n")
+		}
+	}
+	if len(p.Skills) > 0 {
+		sb.WriteString("\nSKILLS:\n")
+		for _, g := range p.Skills {
+			fmt.Fprintf(&sb, "%s: %s\n", g.Label, strings.Join(g.Items, ", "))
+		}
+	}
+	if p.Summary != "" {
+		fmt.Fprintf(&sb, "\nCURRENT SUMMARY:\n%s\n", Sanitize(p.Summary))
+	}
+	if withCover {
+		fmt.Fprintf(&sb, "\nBASE COVER LETTER:\n%s", d.Cover)
+	}
+	return sb.String()
+}
+
+const profileInstructions = `The candidate's experience is given as an EXPERIENCE BANK of entries, each with an ID. Do not write the resume yourself: it is assembled from your selection, and titles, employers, and dates are filled in from the bank. Select the entries most relevant to this job and rephrase each one lightly to mirror the job's language. Keep every number, metric, product, and technology exactly as the entry states it and add no new facts. The cover letter, if requested, must also stay within the bank.`
+
+const profileResponseFormat = `Respond using exactly these XML tags, in this order:
+<company>company name</company>
+<role>role title</role>
+<score>integer 1-10</score>
+` + analysisFormat + `
+<summary>
+two or three sentence professional summary drawn from the bank
+</summary>
+<entries>
+one selected entry per line, most relevant first: entry-id | rephrased text
+</entries>
+<skills>comma-separated skills from the bank that matter for this job</skills>
+<cover>
+tailored cover letter (include ONLY if a base cover letter was provided)
+</cover>`
+
+var (
+	profileGenerationTags = []string{"company", "role", "score", "subscores", "requirements", "summary", "entries", "skills", "cover"}
+	summaryTagRe          = regexp.MustCompile(`(?s)<summary>(.*?)</summary>`)
+	entriesTagRe          = regexp.MustCompile(`(?s)<entries>(.*?)</entries>`)
+	skillsTagRe           = regexp.MustCompile(`(?s)<skills>(.*?)</skills>`)
+)
+
+// parseSelection reads the <summary>, <entries>, and <skills> tags. Unknown
+// and repeated entry IDs are dropped and reported; an entry without text
+// keeps its original wording.
+func parseSelection(p *Profile, content string) (ProfileSelection, []string) {
+	var sel ProfileSelection
+	var notes []string
+	sel.Summary = extractTag(summaryTagRe, content)
+	if sel.Summary == "" {
+		sel.Summary = p.Summary
+	}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(extractTag(entriesTagRe, content), "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•"))
+		if line == "" {
+			continue
+		}
+		id, text, _ := strings.Cut(line, "|")
+		id, text = strings.TrimSpace(id), strings.TrimSpace(text)
+		e, _ := p.entry(id)
+		switch {
+		case e == nil:
+			notes = append(notes, fmt.Sprintf("profile: dropped unknown entry %q", id))
+			continue
+		case seen[id]:
+			continue
+		case text == "":
+			text = e.Text
+		}
+		seen[id] = true
+		sel.Entries = append(sel.Entries, SelectedEntry{ID: id, Text: text})
+	}
+	if raw := extractTag(skillsTagRe, content); raw != "" {
+		sel.Skills = []string{}
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				sel.Skills = append(sel.Skills, s)
+			}
+		}
+	}
+	return sel, notes
+}
+
+// GenerateFromProfile is GenerateAll for a structured profile: the model
+// picks and rephrases entries instead of writing the resume, and the resume
+// is assembled by AssembleResume. Company, role, score, analysis, and the
+// cover letter are generated as in GenerateAll. A response that selects no
+// entries gets one repair turn. instructions is appended as in GenerateAll.
+// It returns the generation and the selection.
+func GenerateFromProfile(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	nodes []JobDescriptionNode,
+	profile *Profile,
+	baseCover *string,
+	promptConfig PromptConfig,
+	instructions string,
+	onDelta func(string),
+	onReasoning func(string),
+) (*Generation, *ProfileSelection, error) {
+	data, err := tailorPromptData(nodes, profile.Text(), baseCover)
+	if err != nil {
+		return nil, nil, err
+	}
+	data.Default = profileInput(data, profile, baseCover != nil)
+	prompt, err := promptConfig.render(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("render prompt: %w", err)
+	}
+	prompt.addTasks(instructions)
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: prompt.SystemPrompt + "\n\n" + prompt.TaskList + "\n\n" + profileInstructions + "\n\n" + profileResponseFormat},
+		{Role: "user", Content: prompt.User},
+	}
+	content, reasoning, tokensUsed, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, onReasoning)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	gen := &Generation{Tokens: tokensUsed, Reasoning: reasoning}
+	content, gen.Repairs = repairTagged(content, profileGenerationTags)
+	gen.extract(content, baseCover != nil)
+	sel, notes := parseSelection(profile, content)
+
+	// The resume is assembled below, so only company and role can be missing.
+	var missing []string
+	for _, m := range gen.missing() {
+		if m != "resume" {
+			missing = append(missing, m)
+		}
+	}
+	if len(sel.Entries) == 0 {
+		missing = append(missing, "entries")
+	}
+	if len(missing) > 0 && invoker != nil {
+		reply, tokens, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, missing, profileGenerationTags)
+		if err != nil {
+			return nil, nil, err
+		}
+		gen.Tokens += tokens
+		gen.Repairs = append(gen.Repairs, fmt.Sprintf("requested missing tags: %s", strings.Join(missing, ", ")))
+		gen.extract(reply, baseCover != nil)
+		if len(sel.Entries) == 0 {
+			sel, notes = parseSelection(profile, reply)
+		}
+	}
+	gen.Repairs = append(gen.Repairs, notes...)
+
+	if len(sel.Entries) == 0 {
+		return nil, nil, fmt.Errorf("llm response selected no profile entries")
+	}
+	gen.Resume = AssembleResume(profile, sel)
+	if missing := gen.missing(); len(missing) > 0 {
+		return nil, nil, fmt.Errorf("llm response missing required fields (company=%q role=%q)", gen.Company, gen.Role)
+	}
+	return gen, &sel, nil
+}
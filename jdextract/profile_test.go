@@ -0,0 +1,205 @@
+package jdextract
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const sampleProfile = `{
+  "name": "JANE DOE",
+  "contact": ["jane@example.com | (555) 123-4567"],
+  "summary": "Copywriter with eight years of B2B experience.",
+  "roles": [
+    {
+      "title": "Senior Copywriter", "employer": "Acme Corp", "start": "2020",
+      "entries": [
+        {"id": "acme-newsletter", "text": "Grew newsletter subscribers by 40% in one year", "tags": ["email"], "metrics": ["40%"]},
+        {"id": "acme-launch", "text": "Wrote launch copy for three products", "skills": ["Product marketing"]}
+      ]
+    },
+    {
+      "title": "Copywriter", "employer": "Beta Agency", "location": "Berlin", "start": "2016", "end": "2020",
+      "entries": [
+        {"id": "beta-seo", "text": "Rewrote 200 landing pages for search", "tags": ["seo"]},
+        {"id": "beta-pitch", "text": "Contributed to five winning pitches"}
+      ]
+    }
+  ],
+  "education": [{"degree": "BA English", "school": "State University", "year": "2015"}],
+  "skills": [{"label": "Writing", "items": ["SEO", "Email", "Long-form"]}, {"label": "Tools", "items": ["Figma", "HubSpot"]}]
+}`
+
+func writeProfile(t *testing.T, a *App) *Profile {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(a.Paths.Config, profileFile), []byte(sampleProfile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := LoadProfile(a)
+	if err != nil || p == nil {
+		t.Fatalf("LoadProfile = %v, %v", p, err)
+	}
+	return p
+}
+
+func TestAssembleResume(t *testing.T) {
+	p := writeProfile(t, newTestApp(t))
+	got := AssembleResume(p, ProfileSelection{
+		Summary: "B2B copywriter focused on email.",
+		Entries: []SelectedEntry{
+			{ID: "acme-launch", Text: "Wrote launch copy for three SaaS products"},
+			{ID: "acme-newsletter", Text: "Grew email subscribers by 40% in one year"},
+		},
+		Skills: []string{"email", "HubSpot"},
+	})
+	want := `JANE DOE
+jane@example.com | (555) 123-4567
+
+PROFESSIONAL SUMMARY
+B2B copywriter focused on email.
+
+EXPERIENCE
+
+Senior Copywriter | Acme Corp | 2020 - Present
+• Wrote launch copy for three SaaS products
+• Grew email subscribers by 40% in one year
+
+Copywriter | Beta Agency | Berlin | 2016 - 2020
+• Rewrote 200 landing pages for search
+
+EDUCATION
+BA English | State University | 2015
+
+SKILLS
+• Writing: Email
+• Tools: HubSpot
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if text := p.Text(); !strings.Contains(text, "• Contributed to five winning pitches") || !strings.Contains(text, "• Tools: Figma, HubSpot") {
+		t.Errorf("Text() should include every entry and skill:\n%s", text)
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	p := writeProfile(t, newTestApp(t))
+	p.Roles[1].Entries[0].ID = "acme-launch"
+	if err := p.Validate(); err == nil {
+		t.Error("duplicate id: want error")
+	}
+	p.Roles[1].Entries[0].ID = "a|b"
+	if err := p.Validate(); err == nil {
+		t.Error("id with separator: want error")
+	}
+}
+
+func TestGenerateFromProfile(t *testing.T) {
+	a := newTestApp(t)
+	p := writeProfile(t, a)
+	reply := `<company>Acme Corp</company>
+<role>Email Copywriter</role>
+<score>8</score>
+<entries>
+acme-newsletter | Grew email subscribers by 40% in twelve months
+- invented-entry | Led a team of 50
+beta-seo |
+acme-newsletter | duplicate
+</entries>
+<skills>Email, SEO, Rust</skills>`
+	var calls int
+	gen, sel, err := GenerateFromProfile(context.Background(), fakeInvoker(reply, &calls), nil, "", TaskParams{}, nil,
+		Parse(sampleJD), p, nil, a.PromptConfig, "", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if len(sel.Entries) != 2 || sel.Entries[1].Text != "Rewrote 200 landing pages for search" {
+		t.Errorf("entries = %+v", sel.Entries)
+	}
+	if sel.Summary != p.Summary {
+		t.Errorf("summary = %q, want the profile summary", sel.Summary)
+	}
+	for _, want := range []string{
+		"Senior Copywriter | Acme Corp | 2020 - Present\n• Grew email subscribers by 40% in twelve months\n",
+		"Copywriter | Beta Agency | Berlin | 2016 - 2020\n• Rewrote 200 landing pages for search\n",
+		"• Writing: SEO, Email\n",
+	} {
+		if !strings.Contains(gen.Resume, want) {
+			t.Errorf("resume missing %q:\n%s", want, gen.Resume)
+		}
+	}
+	if strings.Contains(gen.Resume, "Led a team") || strings.Contains(gen.Resume, "Rust") {
+		t.Errorf("resume contains content outside the profile:\n%s", gen.Resume)
+	}
+	if strings.Join(gen.Repairs, "\n") != `profile: dropped unknown entry "invented-entry"` {
+		t.Errorf("repairs = %q", gen.Repairs)
+	}
+
+	// No entries at all: one repair turn, then an error.
+	calls = 0
+	if _, _, err := GenerateFromProfile(context.Background(), fakeInvoker("<company>A</company><role>B</role>", &calls), nil, "", TaskParams{}, nil,
+		Parse(sampleJD), p, nil, a.PromptConfig, "", nil, nil); err == nil || calls != 2 {
+		t.Errorf("err = %v, calls = %d; want error after a repair turn", err, calls)
+	}
+}
+
+func TestSelectTemplatesProfile(t *testing.T) {
+	a := newTestApp(t)
+	nodes := Parse(sampleJD)
+	got, err := SelectTemplates(a, "", nodes)
+	if err != nil || got.Name != TemplateDefault {
+		t.Fatalf("without profile: %+v, %v", got, err)
+	}
+	writeProfile(t, a)
+	got, err = SelectTemplates(a, "", nodes)
+	if err != nil || got.Name != TemplateProfile || got.Profile == nil || got.Cover == nil {
+		t.Fatalf("with profile: %+v, %v", got, err)
+	}
+	if !strings.HasPrefix(got.Resume, "JANE DOE\n") {
+		t.Errorf("resume = %q", got.Resume)
+	}
+	if got, err := SelectTemplates(a, TemplateDefault, nodes); err != nil || got.Profile != nil {
+		t.Errorf("explicit default: %+v, %v", got, err)
+	}
+}
+
+func TestProcessProfileIgnoresPipeline(t *testing.T) {
+	a := newTestApp(t)
+	p := writeProfile(t, a)
+	cover, err := fetchCover(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply := "<company>Acme Corp</company>\n<role>Email Copywriter</role>\n<score>8</score>\n<entries>\nacme-newsletter | Grew email subscribers by 40% in twelve months\n</entries>\n<cover>\nDear Hiring Manager,\n</cover>"
+	var calls int
+	if _, _, err := GenerateFromProfile(context.Background(), recordInvoker(a.RecordingsDir(), fakeInvoker(reply, &calls)), nil, "", a.TaskParams(TaskTailor), nil,
+		Parse(sampleJD), p, &cover, a.PromptConfig, "", nil, nil); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+
+	opts := a.DefaultProcessOptions()
+	opts.Pipeline = true
+	dir, err := a.ProcessWithOptions(context.Background(), sampleJD, opts, func(ProgressEvent) {})
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	meta, err := a.Jobs.ReadMeta(filepath.Base(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Template != TemplateProfile || strings.Join(meta.ProfileEntries, ",") != "acme-newsletter" {
+		t.Errorf("meta = %+v", meta)
+	}
+	if !slices.Contains(meta.Provenance, "pipeline: skipped, the resume is assembled from the profile") {
+		t.Errorf("provenance = %q", meta.Provenance)
+	}
+	if _, err := os.Stat(filepath.Join(dir, pipelineDir)); !os.IsNotExist(err) {
+		t.Errorf("pipeline passes written for a profile job: %v", err)
+	}
+}
@@ -6,8 +6,17 @@ type ProgressStage string
 const (
 	StageFetching   ProgressStage = "fetching"
 	StageParsing    ProgressStage = "parsing"
+	StageQueued     ProgressStage = "queued"
 	StageGenerating ProgressStage = "generating"
 	StageContent    ProgressStage = "content"
+	StageReasoning  ProgressStage = "reasoning"
+	StageAnalyzing  ProgressStage = "analyzing"
+	StageTailoring  ProgressStage = "tailoring"
+	StageCritiquing ProgressStage = "critiquing"
+	StageRevising   ProgressStage = "revising"
+	StageShortening ProgressStage = "shortening"
+	StageVerifying  ProgressStage = "verifying"
+	StageWarning    ProgressStage = "warning"
 	StageSaving     ProgressStage = "saving"
 	StageComplete   ProgressStage = "complete"
 	StageError      ProgressStage = "error"
@@ -15,9 +24,19 @@ const (
 
 // ProgressEvent is emitted at each stage boundary during processing.
 // For StageContent events, Delta holds the incremental LLM output text.
+// For StageReasoning events, Delta holds incremental reasoning-model thinking,
+// which is not part of the answer and may be folded away by the UI.
+// In pipeline mode (see GeneratePipeline) each pass streams its deltas under
+// its own stage (StageAnalyzing, StageTailoring, StageCritiquing,
+// StageRevising) instead of StageContent.
+// For StageQueued events, Queue holds the 1-based position in the LLM queue.
+// For StageWarning events, Warnings lists claims in the tailored resume that
+// the fabrication check (see VerifyClaims) could not find in the base resume.
 type ProgressEvent struct {
-	Stage   ProgressStage `json:"stage"`
-	Message string        `json:"message,omitempty"`
-	Dir     string        `json:"dir,omitempty"`
-	Delta   string        `json:"delta,omitempty"`
+	Stage    ProgressStage `json:"stage"`
+	Message  string        `json:"message,omitempty"`
+	Dir      string        `json:"dir,omitempty"`
+	Delta    string        `json:"delta,omitempty"`
+	Queue    int           `json:"queue,omitempty"`
+	Warnings []string      `json:"warnings,omitempty"`
 }
@@ -0,0 +1,232 @@
+package jdextract
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// PromptData is the data available to prompt templates. Every field of
+// PromptConfig and NetworkingPromptConfig is parsed as a Go text/template and
+// executed against it, so plain-text prompts render unchanged.
+//
+// Which fields are set depends on the prompt:
+//
+//	.Job      tailoring prompts: the job description (see PromptJob)
+//	.Resume   tailoring prompts: the sanitized base resume
+//	.Cover    tailoring prompts: the sanitized base cover letter, or ""
+//	.Contact  networking prompts: the contact (see ContactMeta)
+//	.Today    always: today's date as YYYY-MM-DD
+//	.Profile  always: the "profile" text of the same prompt file
+//	.Default  always: the user message sent when no "user" template is set
+type PromptData struct {
+	Job     PromptJob
+	Resume  string
+	Cover   string
+	Contact *ContactMeta
+	Today   string
+	Profile string
+	Default string
+}
+
+// PromptJob is a job description as seen by prompt templates. Company and
+// Role are known only when previewing an existing job.
+type PromptJob struct {
+	Company  string
+	Role     string
+	Sections []JobSection
+	JSON     string // the classified nodes as sent by the default layout
+	Text     string // the node contents, one per line
+}
+
+// JobSection is a heading and the lines under it. Lines before the first
+// heading form a section with an empty Heading.
+type JobSection struct {
+	Heading string
+	Lines   []string
+}
+
+var promptFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+// newPromptJob builds the template view of nodes.
+func newPromptJob(nodes []JobDescriptionNode) (PromptJob, error) {
+	b, err := json.Marshal(nodes)
+	if err != nil {
+		return PromptJob{}, fmt.Errorf("json encode: %w", err)
+	}
+	return PromptJob{Sections: jobSections(nodes), JSON: string(b), Text: nodeText(nodes)}, nil
+}
+
+// jobSections groups nodes under their headings.
+func jobSections(nodes []JobDescriptionNode) []JobSection {
+	var out []JobSection
+	for _, n := range nodes {
+		switch n.NodeType {
+		case NodeHeading, NodeSectionHeader, NodeJobTitle:
+			out = append(out, JobSection{Heading: strings.TrimSpace(strings.TrimLeft(n.Content, "# "))})
+		default:
+			if len(out) == 0 {
+				out = append(out, JobSection{})
+			}
+			out[len(out)-1].Lines = append(out[len(out)-1].Lines, n.Content)
+		}
+	}
+	return out
+}
+
+// tailorPromptData assembles the data for the tailoring prompts.
+func tailorPromptData(nodes []JobDescriptionNode, baseResume string, baseCover *string) (PromptData, error) {
+	job, err := newPromptJob(nodes)
+	if err != nil {
+		return PromptData{}, err
+	}
+	d := PromptData{Job: job, Resume: Sanitize(baseResume), Today: currentDate()}
+	if baseCover != nil {
+		d.Cover = Sanitize(*baseCover)
+	}
+	d.Default = generationInput(d, baseCover != nil)
+	return d, nil
+}
+
+// renderPrompt executes text as a template named name against data.
+func renderPrompt(name, text string, data PromptData) (string, error) {
+	t, err := template.New(name).Funcs(promptFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderedPrompt is a prompt config with every template executed.
+type renderedPrompt struct {
+	SystemPrompt string
+	TaskList     string
+	User         string
+}
+
+// renderPrompts executes the prompt templates. An empty user template
+// yields data.Default.
+func renderPrompts(systemPrompt, taskList, user string, data PromptData) (renderedPrompt, error) {
+	var r renderedPrompt
+	var err error
+	if r.SystemPrompt, err = renderPrompt("system_prompt", systemPrompt, data); err != nil {
+		return r, err
+	}
+	if r.TaskList, err = renderPrompt("task_list", taskList, data); err != nil {
+		return r, err
+	}
+	r.User = data.Default
+	if strings.TrimSpace(user) != "" {
+		if r.User, err = renderPrompt("user", user, data); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// addTasks appends text, which is not a template, to the rendered task list.
+func (r *renderedPrompt) addTasks(text string) {
+	if text != "" {
+		r.TaskList += "\n" + text
+	}
+}
+
+func (p PromptConfig) render(data PromptData) (renderedPrompt, error) {
+	data.Profile = p.Profile
+	return renderPrompts(p.SystemPrompt, p.TaskList, p.User, data)
+}
+
+func (p NetworkingPromptConfig) render(data PromptData) (renderedPrompt, error) {
+	data.Profile = p.Profile
+	return renderPrompts(p.SystemPrompt, p.TaskList, p.User, data)
+}
+
+// Sample data shaped like what each prompt family receives, so that Validate
+// catches syntax errors, references to fields that do not exist, and fields
+// that are unset in practice: tailoring prompts never get a Contact, and
+// networking prompts never get a job, resume, or cover letter.
+var (
+	sampleTailorData = PromptData{
+		Job: PromptJob{
+			Company:  "Example Co",
+			Role:     "Example Role",
+			Sections: []JobSection{{Heading: "About", Lines: []string{"Example line"}}},
+			JSON:     "[]",
+			Text:     "Example line",
+		},
+		Resume:  "Example resume",
+		Cover:   "Example cover letter",
+		Today:   "2006-01-02",
+		Default: "Example message",
+	}
+	sampleNetworkingData = PromptData{
+		Contact: &ContactMeta{Name: "Example Contact"},
+		Today:   "2006-01-02",
+		Default: "Example message",
+	}
+)
+
+// Validate parses and test-renders every prompt template.
+func (p PromptConfig) Validate() error {
+	_, err := p.render(sampleTailorData)
+	return err
+}
+
+// Validate parses and test-renders every prompt template.
+func (p NetworkingPromptConfig) Validate() error {
+	_, err := p.render(sampleNetworkingData)
+	return err
+}
+
+// PromptPreview is the final tailoring prompt for a job, as GenerateAll
+// would send it.
+type PromptPreview struct {
+	System string `json:"system"`
+	User   string `json:"user"`
+}
+
+// PreviewPrompt renders pc for an existing job from its stored jd.txt and
+// base template, without calling the LLM.
+func PreviewPrompt(a *App, id string, pc PromptConfig) (*PromptPreview, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	meta, err := a.Jobs.ReadMeta(id)
+	if err != nil {
+		return nil, err
+	}
+	jd, err := os.ReadFile(filepath.Join(a.Paths.Jobs, id, jdFile))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("job has no stored job description")
+		}
+		return nil, err
+	}
+	base, err := LoadTemplates(a, meta.Template)
+	if err != nil {
+		return nil, err
+	}
+	data, err := tailorPromptData(Parse(string(jd)), base.Resume, base.Cover)
+	if err != nil {
+		return nil, err
+	}
+	data.Job.Company, data.Job.Role = meta.Company, meta.Role
+	r, err := pc.render(data)
+	if err != nil {
+		return nil, err
+	}
+	return &PromptPreview{System: r.SystemPrompt + "\n\n" + r.TaskList + "\n\n" + responseFormat, User: r.User}, nil
+}
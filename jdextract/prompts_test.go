@@ -0,0 +1,95 @@
+package jdextract
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestJobSections(t *testing.T) {
+	nodes := []JobDescriptionNode{
+		{Content: "We make ads.", NodeType: NodeBody},
+		{Content: "## Requirements", NodeType: NodeSectionHeader},
+		{Content: "- 5 years of copywriting", NodeType: NodeBullet},
+		{Content: "- B2B experience", NodeType: NodeBullet},
+	}
+	got := jobSections(nodes)
+	if len(got) != 2 || got[0].Heading != "" || got[1].Heading != "Requirements" || len(got[1].Lines) != 2 {
+		t.Errorf("sections = %+v", got)
+	}
+}
+
+func TestPromptConfigValidate(t *testing.T) {
+	valid := PromptConfig{
+		SystemPrompt: "Today is {{.Today}}. {{.Profile}}",
+		User:         "{{range .Job.Sections}}{{upper .Heading}}\n{{join .Lines \"\\n\"}}\n{{end}}{{.Resume}}",
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+	for _, pc := range []PromptConfig{
+		{SystemPrompt: "{{.Today"},
+		{TaskList: "{{.Job.Salary}}"},
+		{User: "{{.Nope}}"},
+		{TaskList: "{{.Contact.Name}}"}, // tailoring prompts get no contact
+	} {
+		if err := pc.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", pc)
+		}
+	}
+	if err := (NetworkingPromptConfig{User: "{{.Contact.Name}} {{.Default}}"}).Validate(); err != nil {
+		t.Errorf("networking config: %v", err)
+	}
+}
+
+func TestGenerateAllUserTemplate(t *testing.T) {
+	var requests []deepseekRequest
+	pc := PromptConfig{
+		SystemPrompt: "Writer for {{.Profile}}.",
+		User:         "RESUME FIRST:\n{{.Resume}}\n\nJOB:\n{{.Job.Text}}",
+		Profile:      "Jane",
+	}
+	_, err := GenerateAll(context.Background(), nil, scriptedStream(t, []string{fakeGeneration}, &requests), "", TaskParams{Model: "m"}, nil,
+		Parse(sampleJD), "JANE DOE jane@example.com", nil, pc, "Paragraph: {{.Resume}} and {{", func(string) {}, nil)
+	if err != nil {
+		t.Fatalf("GenerateAll: %v", err)
+	}
+	system, user := requests[0].Messages[0].Content, requests[0].Messages[1].Content
+	if !strings.HasPrefix(system, "Writer for Jane.") || !strings.Contains(system, "<resume>") {
+		t.Errorf("system prompt = %q", system)
+	}
+	// Instructions carry cover block text and are never parsed as a template.
+	if !strings.Contains(system, "\nParagraph: {{.Resume}} and {{\n") {
+		t.Errorf("system prompt lacks verbatim instructions: %q", system)
+	}
+	if !strings.HasPrefix(user, "RESUME FIRST:\nJANE DOE [email redacted]\n\nJOB:\n") || strings.Contains(user, "JOB DESCRIPTION:") {
+		t.Errorf("user message = %q", user)
+	}
+}
+
+func TestPreviewPrompt(t *testing.T) {
+	a := newTestApp(t)
+	slug, err := a.Jobs.MkDir("acme-copywriter")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(a.Paths.Jobs, slug)
+	if err := SaveJSON(filepath.Join(dir, "meta.json"), ApplicationMeta{Company: "Acme Corp", Role: "Copywriter"}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PreviewPrompt(a, slug, PromptConfig{}); err == nil {
+		t.Error("preview without jd.txt should fail")
+	}
+	if err := os.WriteFile(filepath.Join(dir, jdFile), []byte(sampleJD), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := PreviewPrompt(a, slug, PromptConfig{User: "{{.Job.Company}} / {{.Job.Role}}\n{{.Default}}"})
+	if err != nil {
+		t.Fatalf("PreviewPrompt: %v", err)
+	}
+	if !strings.HasPrefix(p.User, "Acme Corp / Copywriter\nJOB DESCRIPTION:") || !strings.Contains(p.System, "<company>") {
+		t.Errorf("preview = %+v", p)
+	}
+}
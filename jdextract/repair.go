@@ -0,0 +1,164 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	// codeFenceRe matches a markdown code fence line such as ``` or ```xml.
+	codeFenceRe = regexp.MustCompile("(?m)^[ \t]*```[\\w-]*[ \t]*\r?\n?")
+
+	// scoreValueRe finds the first number in a score, tolerating "7/10",
+	// "Score: 7" and "8.5".
+	scoreValueRe = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+	// tagPatterns caches the patterns for tag names only known at run time,
+	// keyed by pattern source.
+	tagPatterns sync.Map
+)
+
+// tagRe returns the cached extractTag pattern for tag, the run-time form of
+// resumeTagRe and friends.
+func tagRe(tag string) *regexp.Regexp {
+	return cachedPattern(`(?s)<` + regexp.QuoteMeta(tag) + `>(.*?)</` + regexp.QuoteMeta(tag) + `>`)
+}
+
+// tagCaseRe returns the cached pattern matching tag's opening or closing tag
+// in any case.
+func tagCaseRe(tag string) *regexp.Regexp {
+	return cachedPattern(`(?i)<(/?)` + regexp.QuoteMeta(tag) + `>`)
+}
+
+func cachedPattern(src string) *regexp.Regexp {
+	if re, ok := tagPatterns.Load(src); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, _ := tagPatterns.LoadOrStore(src, regexp.MustCompile(src))
+	return re.(*regexp.Regexp)
+}
+
+// repairTagged normalizes common formatting slips in tagged LLM output so the
+// strict extractTag regexps can read it. tags lists the expected tag names in
+// response order. It returns the repaired content and a note per repair made.
+//
+// Tolerated deviations: markdown code fences around the response, tag names
+// in the wrong case (<Resume>), and opening tags that were never closed —
+// those are closed before the next expected opening tag, or at end of text.
+func repairTagged(content string, tags []string) (string, []string) {
+	var notes []string
+
+	if codeFenceRe.MatchString(content) {
+		content = codeFenceRe.ReplaceAllString(content, "")
+		notes = append(notes, "stripped markdown code fences")
+	}
+
+	for _, tag := range tags {
+		re := tagCaseRe(tag)
+		fixed := false
+		content = re.ReplaceAllStringFunc(content, func(m string) string {
+			want := "<" + tag + ">"
+			if strings.HasPrefix(m, "</") {
+				want = "</" + tag + ">"
+			}
+			if m != want {
+				fixed = true
+			}
+			return want
+		})
+		if fixed {
+			notes = append(notes, fmt.Sprintf("normalized <%s> tag case", tag))
+		}
+	}
+
+	for i, tag := range tags {
+		open, close := "<"+tag+">", "</"+tag+">"
+		start := strings.Index(content, open)
+		if start < 0 || strings.Contains(content[start:], close) {
+			continue
+		}
+		end := len(content)
+		for _, next := range tags[i+1:] {
+			if j := strings.Index(content[start:], "<"+next+">"); j >= 0 && start+j < end {
+				end = start + j
+			}
+		}
+		content = strings.TrimRight(content[:end], " \t\r\n") + "\n" + close + "\n" + content[end:]
+		notes = append(notes, fmt.Sprintf("closed unterminated <%s> tag", tag))
+	}
+
+	return content, notes
+}
+
+// parseScore reads a 1–10 score from the inner text of <score>. repaired is
+// true when the text was anything other than bare digits, e.g. "7/10".
+func parseScore(s string) (score int, repaired bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, false
+	}
+	m := scoreValueRe.FindString(s)
+	if m == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(m, 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(math.Round(f)), true
+}
+
+// missingTagsPrompt asks the model to re-emit only the listed tags.
+func missingTagsPrompt(missing []string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Your previous response was missing required tags. Re-emit ONLY the following tags, with their full content, and nothing else:\n")
+	for _, t := range missing {
+		fmt.Fprintf(&sb, "<%s>...</%s>\n", t, t)
+	}
+	return sb.String()
+}
+
+// requestMissingTags sends a short follow-up turn continuing conversation,
+// asking the model to re-emit just the missing tags rather than redo the
+// whole generation. It returns the reply content (already repaired) and the
+// tokens the turn consumed.
+func requestMissingTags(
+	ctx context.Context,
+	invoker LLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	conversation []deepseekMessage,
+	previous string,
+	missing []string,
+	tags []string,
+) (string, int, error) {
+	messages := append(append([]deepseekMessage{}, conversation...),
+		deepseekMessage{Role: "assistant", Content: previous},
+		deepseekMessage{Role: "user", Content: missingTagsPrompt(missing)},
+	)
+	bodyBytes, err := json.Marshal(params.request(messages, false))
+	if err != nil {
+		return "", 0, fmt.Errorf("marshal repair request: %w", err)
+	}
+	raw, err := invoker(ctx, apiKey, c, 0, json.RawMessage(bodyBytes))
+	if err != nil {
+		return "", 0, fmt.Errorf("repair turn: %w", err)
+	}
+	reply, _, tokens, err := decodeCompletion(raw)
+	if err != nil {
+		return "", 0, fmt.Errorf("repair turn: %w", err)
+	}
+	content, _ := repairTagged(reply, tags)
+	return content, tokens, nil
+}
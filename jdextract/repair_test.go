@@ -0,0 +1,90 @@
+package jdextract
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepairTagged(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantTag   string // tag to extract after repair
+		wantValue string
+		wantNotes int
+	}{
+		{
+			name:      "clean output untouched",
+			input:     "<company>Acme</company>",
+			wantTag:   "company",
+			wantValue: "Acme",
+			wantNotes: 0,
+		},
+		{
+			name:      "code fences stripped",
+			input:     "```xml\n<company>Acme</company>\n```",
+			wantTag:   "company",
+			wantValue: "Acme",
+			wantNotes: 1,
+		},
+		{
+			name:      "tag case normalized",
+			input:     "<ROLE>Writer</Role>",
+			wantTag:   "role",
+			wantValue: "Writer",
+			wantNotes: 1,
+		},
+		{
+			name:      "unclosed final tag closed at end",
+			input:     "<company>Acme</company>\n<resume>\nJANE DOE\nWriter\n",
+			wantTag:   "resume",
+			wantValue: "JANE DOE\nWriter",
+			wantNotes: 1,
+		},
+		{
+			name:      "unclosed tag closed before next tag",
+			input:     "<resume>\nJANE DOE\n<cover>\nDear team\n</cover>",
+			wantTag:   "resume",
+			wantValue: "JANE DOE",
+			wantNotes: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes := repairTagged(tt.input, generationTags)
+			open, close := "<"+tt.wantTag+">", "</"+tt.wantTag+">"
+			i, j := strings.Index(got, open), strings.Index(got, close)
+			if i < 0 || j < i {
+				t.Fatalf("tag %s not extractable from %q", tt.wantTag, got)
+			}
+			if v := strings.TrimSpace(got[i+len(open) : j]); v != tt.wantValue {
+				t.Errorf("value = %q, want %q", v, tt.wantValue)
+			}
+			if len(notes) != tt.wantNotes {
+				t.Errorf("notes = %v, want %d", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func TestParseScore(t *testing.T) {
+	tests := []struct {
+		input    string
+		want     int
+		repaired bool
+	}{
+		{"7", 7, false},
+		{" 9 ", 9, false},
+		{"7/10", 7, true},
+		{"Score: 6", 6, true},
+		{"8.5", 9, true},
+		{"n/a", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, repaired := parseScore(tt.input)
+		if got != tt.want || repaired != tt.repaired {
+			t.Errorf("parseScore(%q) = %d, %v; want %d, %v", tt.input, got, repaired, tt.want, tt.repaired)
+		}
+	}
+}
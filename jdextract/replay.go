@@ -0,0 +1,259 @@
+package jdextract
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LLM modes select between live calls, recording live calls, and serving
+// recorded calls offline. The mode comes from Config.LLMMode and may be
+// overridden with the JDEXTRACT_LLM_MODE environment variable.
+const (
+	LLMModeLive   = ""
+	LLMModeRecord = "record"
+	LLMModeReplay = "replay"
+)
+
+var validLLMModes = []string{LLMModeLive, LLMModeRecord, LLMModeReplay}
+
+// Recording is one request/response pair stored as <hash>.json in the
+// recordings directory. A request may be recorded both as a plain call
+// (Response) and as a stream (Content); replay serves either form from either.
+type Recording struct {
+	Request   json.RawMessage `json:"request"`
+	Response  string          `json:"response,omitempty"`  // raw non-streaming response body
+	Content   string          `json:"content,omitempty"`   // accumulated streaming content
+	Reasoning string          `json:"reasoning,omitempty"` // accumulated streaming reasoning
+	Tokens    int             `json:"tokens,omitempty"`    // streaming usage; Response carries its own
+}
+
+// recordingKey hashes a request body with its "stream" and "stream_options"
+// fields removed, so the streaming and non-streaming forms of a request share
+// one recording.
+func recordingKey(body json.RawMessage) (string, error) {
+	var m map[string]any
+	if err := json.Unmarshal(body, &m); err != nil {
+		return "", fmt.Errorf("recording key: %w", err)
+	}
+	delete(m, "stream")
+	delete(m, "stream_options")
+	canon, err := json.Marshal(m) // map keys marshal in sorted order
+	if err != nil {
+		return "", fmt.Errorf("recording key: %w", err)
+	}
+	sum := sha256.Sum256(canon)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// saveRecording merges update into the recording for body, creating it if needed.
+func saveRecording(dir string, body json.RawMessage, update func(*Recording)) error {
+	key, err := recordingKey(body)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create recordings directory: %w", err)
+	}
+	path := filepath.Join(dir, key+".json")
+	rec := &Recording{}
+	if existing, err := LoadJSON[Recording](path); err == nil {
+		rec = existing
+	}
+	rec.Request = body
+	update(rec)
+	return SaveJSON(path, rec, 0644)
+}
+
+// loadRecording returns the recording for body, failing loudly on a miss so
+// an offline run never silently falls through to an empty response.
+func loadRecording(dir string, body json.RawMessage) (*Recording, error) {
+	key, err := recordingKey(body)
+	if err != nil {
+		return nil, err
+	}
+	rec, err := LoadJSON[Recording](filepath.Join(dir, key+".json"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("replay: no recording for request %s in %s", key[:12], dir)
+		}
+		return nil, fmt.Errorf("replay: read recording %s: %w", key[:12], err)
+	}
+	return rec, nil
+}
+
+// recordInvoker wraps inv so each successful response is saved to dir.
+func recordInvoker(dir string, inv LLMInvoker) LLMInvoker {
+	return func(ctx context.Context, apiKey string, c *http.Client, backoff int, body json.RawMessage) (string, error) {
+		raw, err := inv(ctx, apiKey, c, backoff, body)
+		if err != nil {
+			return raw, err
+		}
+		if err := saveRecording(dir, body, func(r *Recording) { r.Response = raw }); err != nil {
+			return raw, fmt.Errorf("record: %w", err)
+		}
+		return raw, nil
+	}
+}
+
+// recordStreamInvoker wraps inv so each completed stream is saved to dir.
+func recordStreamInvoker(dir string, inv StreamingLLMInvoker) StreamingLLMInvoker {
+	return func(ctx context.Context, apiKey string, c *http.Client, body json.RawMessage, onDelta, onReasoning func(string)) (string, int, error) {
+		var reasoning strings.Builder
+		content, tokens, err := inv(ctx, apiKey, c, body, onDelta, func(d string) {
+			reasoning.WriteString(d)
+			if onReasoning != nil {
+				onReasoning(d)
+			}
+		})
+		if err != nil {
+			return content, tokens, err
+		}
+		if err := saveRecording(dir, body, func(r *Recording) {
+			r.Content = content
+			r.Reasoning = reasoning.String()
+			r.Tokens = tokens
+		}); err != nil {
+			return content, tokens, fmt.Errorf("record: %w", err)
+		}
+		return content, tokens, nil
+	}
+}
+
+// replayInvoker serves non-streaming calls from dir. A request that was only
+// recorded as a stream is answered with a synthesized completion body.
+func replayInvoker(dir string) LLMInvoker {
+	return func(ctx context.Context, _ string, _ *http.Client, _ int, body json.RawMessage) (string, error) {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		rec, err := loadRecording(dir, body)
+		if err != nil {
+			return "", err
+		}
+		if rec.Response != "" {
+			return rec.Response, nil
+		}
+		return completionBody(rec.Content, rec.Reasoning)
+	}
+}
+
+// completionBody synthesizes a minimal chat completion response carrying
+// content and, if non-empty, reasoning_content.
+func completionBody(content, reasoning string) (string, error) {
+	msg := map[string]string{"role": "assistant", "content": content}
+	if reasoning != "" {
+		msg["reasoning_content"] = reasoning
+	}
+	out, err := json.Marshal(map[string]any{
+		"choices": []any{map[string]any{"message": msg}},
+	})
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// replayStreamInvoker serves streaming calls from dir, emitting the recorded
+// reasoning (if any) through onReasoning and then the content through onDelta,
+// both in token-sized chunks, and returns the recorded usage. A request that
+// was only recorded without streaming is replayed from its response.
+func replayStreamInvoker(dir string) StreamingLLMInvoker {
+	return func(ctx context.Context, _ string, _ *http.Client, body json.RawMessage, onDelta, onReasoning func(string)) (string, int, error) {
+		rec, err := loadRecording(dir, body)
+		if err != nil {
+			return "", 0, err
+		}
+		content, reasoning, tokens := rec.Content, rec.Reasoning, rec.Tokens
+		if content == "" && rec.Response != "" {
+			content, reasoning, tokens, err = decodeCompletion(rec.Response)
+			if err != nil {
+				return "", 0, fmt.Errorf("replay: %w", err)
+			}
+		}
+		if onReasoning != nil {
+			for _, d := range streamDeltas(reasoning) {
+				if err := ctx.Err(); err != nil {
+					return "", 0, err
+				}
+				onReasoning(d)
+			}
+		}
+		for _, d := range streamDeltas(content) {
+			if err := ctx.Err(); err != nil {
+				return "", 0, err
+			}
+			onDelta(d)
+		}
+		return content, tokens, nil
+	}
+}
+
+// deltaRe approximates provider tokenization: leading whitespace plus a word
+// of up to six characters, or a single punctuation/markup rune.
+var deltaRe = regexp.MustCompile(`\s*(?:[\p{L}\p{N}_]{1,6}|[^\s\p{L}\p{N}_])|\s+$`)
+
+// streamDeltas splits s into chunks resembling the deltas of a live stream.
+// Concatenating the result always reproduces s exactly.
+func streamDeltas(s string) []string {
+	return deltaRe.FindAllString(s, -1)
+}
+
+// LLMMode returns the effective LLM mode, preferring JDEXTRACT_LLM_MODE over
+// config. "live" is accepted as an alias for the default live mode. An
+// unknown mode is an error rather than live, so a typo never turns an
+// offline run into paid API calls.
+func (a *App) LLMMode() (string, error) {
+	m, source := a.Config.LLMMode, "llm_mode"
+	if env, ok := os.LookupEnv("JDEXTRACT_LLM_MODE"); ok {
+		m, source = env, "JDEXTRACT_LLM_MODE"
+	}
+	mode, ok := normalizeLLMMode(m)
+	if !ok {
+		return "", fmt.Errorf("unknown %s %q: must be live, %s, or %s", source, m, LLMModeRecord, LLMModeReplay)
+	}
+	return mode, nil
+}
+
+// normalizeLLMMode maps the "live" alias to LLMModeLive and reports whether m
+// is a known mode.
+func normalizeLLMMode(m string) (string, bool) {
+	if m == "live" {
+		return LLMModeLive, true
+	}
+	return m, slices.Contains(validLLMModes, m)
+}
+
+// failInvoker and failStreamInvoker return err for every call.
+func failInvoker(err error) LLMInvoker {
+	return func(context.Context, string, *http.Client, int, json.RawMessage) (string, error) {
+		return "", err
+	}
+}
+
+func failStreamInvoker(err error) StreamingLLMInvoker {
+	return func(context.Context, string, *http.Client, json.RawMessage, func(string), func(string)) (string, int, error) {
+		return "", 0, err
+	}
+}
+
+// RecordingsDir returns where record/replay fixtures live, preferring
+// JDEXTRACT_RECORDINGS, then Config.RecordingsDir, then data/recordings.
+func (a *App) RecordingsDir() string {
+	if d := os.Getenv("JDEXTRACT_RECORDINGS"); d != "" {
+		return d
+	}
+	if a.Config.RecordingsDir != "" {
+		return a.Config.RecordingsDir
+	}
+	return filepath.Join(a.Paths.Data, "recordings")
+}
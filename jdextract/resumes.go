@@ -0,0 +1,216 @@
+package jdextract
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Template names with special meaning. Named variants live in
+// config/templates/resumes/<name>.txt with an optional matching cover letter
+// in config/templates/covers/<name>.txt.
+const (
+	TemplateDefault = "default" // config/templates/resume.txt and cover.txt
+	TemplateAuto    = "auto"    // best keyword overlap with the job description
+	TemplateProfile = "profile" // config/profile.json, assembled by AssembleResume
+)
+
+const (
+	resumeVariantsDir = "resumes"
+	coverVariantsDir  = "covers"
+)
+
+// BaseTemplates is a resolved base resume and optional cover letter.
+type BaseTemplates struct {
+	Name   string
+	Resume string
+	Cover  *string // nil when no cover letter template exists
+
+	// Profile is set for TemplateProfile; Resume is then Profile.Text().
+	Profile *Profile
+}
+
+// ListResumeTemplates returns the names of the named resume variants, sorted.
+// The default template is not included.
+func ListResumeTemplates(a *App) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(a.Paths.Templates, resumeVariantsDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+	names := []string{}
+	for _, e := range entries {
+		if n, ok := strings.CutSuffix(e.Name(), ".txt"); ok && !e.IsDir() && validTemplateName(n) {
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func validTemplateName(name string) bool {
+	return ValidID(name) && !strings.HasPrefix(name, ".")
+}
+
+// LoadTemplates reads the named base templates. An empty name or "default"
+// reads resume.txt and cover.txt; "profile" renders config/profile.json. A
+// variant or profile without its own cover letter falls back to cover.txt.
+func LoadTemplates(a *App, name string) (*BaseTemplates, error) {
+	if name == TemplateProfile {
+		p, err := LoadProfile(a)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, fmt.Errorf("no %s in the config directory", profileFile)
+		}
+		t := &BaseTemplates{Name: TemplateProfile, Resume: p.Text(), Profile: p}
+		if c, err := fetchCover(a); err == nil {
+			t.Cover = &c
+		}
+		return t, nil
+	}
+	if name == "" || name == TemplateDefault {
+		resume, err := fetchResume(a)
+		if err != nil {
+			return nil, err
+		}
+		t := &BaseTemplates{Name: TemplateDefault, Resume: resume}
+		if c, err := fetchCover(a); err == nil {
+			t.Cover = &c
+		}
+		return t, nil
+	}
+	if !validTemplateName(name) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	resume, err := os.ReadFile(filepath.Join(a.Paths.Templates, resumeVariantsDir, name+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("unknown template %q", name)
+		}
+		return nil, fmt.Errorf("read resume template %q: %w", name, err)
+	}
+	t := &BaseTemplates{Name: name, Resume: string(resume)}
+	if c, err := os.ReadFile(filepath.Join(a.Paths.Templates, coverVariantsDir, name+".txt")); err == nil {
+		cover := string(c)
+		t.Cover = &cover
+	} else if c, err := fetchCover(a); err == nil {
+		t.Cover = &c
+	}
+	return t, nil
+}
+
+// SelectTemplates resolves name for a job. An empty name selects the
+// structured profile when config/profile.json exists and the default
+// template otherwise. For "auto" it loads the default template, the profile,
+// and every variant and picks the one whose keywords are most similar to
+// those of nodes (see keywordSimilarity); ties go to the default, then the
+// profile, then the alphabetically first name.
+func SelectTemplates(a *App, name string, nodes []JobDescriptionNode) (*BaseTemplates, error) {
+	if name == "" {
+		p, err := LoadProfile(a)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			name = TemplateProfile
+		}
+	}
+	if name != TemplateAuto {
+		return LoadTemplates(a, name)
+	}
+	names, err := ListResumeTemplates(a)
+	if err != nil {
+		return nil, fmt.Errorf("list templates: %w", err)
+	}
+	candidates := []string{TemplateDefault}
+	if p, err := LoadProfile(a); err != nil {
+		return nil, err
+	} else if p != nil {
+		candidates = append(candidates, TemplateProfile)
+	}
+	jd := keywords(nodeText(nodes))
+
+	var best *BaseTemplates
+	bestScore := -1.0
+	for _, n := range append(candidates, names...) {
+		t, err := LoadTemplates(a, n)
+		if err != nil {
+			if n == TemplateDefault {
+				continue // a library of variants alone is enough for auto
+			}
+			return nil, err
+		}
+		if score := keywordSimilarity(jd, keywords(t.Resume)); score > bestScore {
+			best, bestScore = t, score
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("no resume templates found")
+	}
+	return best, nil
+}
+
+func nodeText(nodes []JobDescriptionNode) string {
+	var sb strings.Builder
+	for _, n := range nodes {
+		sb.WriteString(n.Content)
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// keywordRe matches candidate keywords, keeping tech spellings such as
+// "c++", "c#", "node.js", and "ci/cd" intact.
+var keywordRe = regexp.MustCompile(`[\p{L}\p{N}][\p{L}\p{N}+#./-]*[\p{L}\p{N}+#]|[\p{L}\p{N}]`)
+
+// stopWords are common English words that carry no signal for matching.
+var stopWords = map[string]bool{
+	"a": true, "about": true, "all": true, "also": true, "an": true, "and": true, "any": true,
+	"are": true, "as": true, "at": true, "be": true, "been": true, "but": true, "by": true,
+	"can": true, "do": true, "for": true, "from": true, "has": true, "have": true, "how": true,
+	"in": true, "into": true, "is": true, "it": true, "its": true, "more": true, "must": true,
+	"not": true, "of": true, "on": true, "or": true, "our": true, "such": true, "that": true,
+	"the": true, "their": true, "this": true, "to": true, "we": true, "what": true, "who": true,
+	"will": true, "with": true, "work": true, "you": true, "your": true, "years": true,
+}
+
+// keywords returns the distinct lowercase keywords of s, excluding stop words
+// and single characters.
+func keywords(s string) map[string]bool {
+	out := make(map[string]bool)
+	for _, w := range keywordRe.FindAllString(strings.ToLower(s), -1) {
+		if len(w) > 1 && !stopWords[w] {
+			out[w] = true
+		}
+	}
+	return out
+}
+
+// keywordOverlap counts the keywords present in both sets.
+func keywordOverlap(a, b map[string]bool) int {
+	n := 0
+	for k := range a {
+		if b[k] {
+			n++
+		}
+	}
+	return n
+}
+
+// keywordSimilarity is the Jaccard similarity of two keyword sets, so a
+// longer text does not score higher just for containing more words.
+func keywordSimilarity(a, b map[string]bool) float64 {
+	union := len(a) + len(b) - keywordOverlap(a, b)
+	if union == 0 {
+		return 0
+	}
+	return float64(keywordOverlap(a, b)) / float64(union)
+}
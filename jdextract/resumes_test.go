@@ -0,0 +1,58 @@
+package jdextract
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSelectTemplatesAuto(t *testing.T) {
+	a := newTestApp(t)
+	write := func(rel, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(a.Paths.Templates, rel), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("resumes/backend.txt", "JANE DOE\nGo, PostgreSQL, gRPC, Kubernetes microservices")
+	write("resumes/data.txt", "JANE DOE\nPython, Spark, Airflow, dbt, data pipelines and warehousing")
+	write("covers/data.txt", "Dear data team,")
+
+	names, err := ListResumeTemplates(a)
+	if err != nil || len(names) != 2 || names[0] != "backend" {
+		t.Fatalf("ListResumeTemplates = %v, %v", names, err)
+	}
+
+	nodes := Parse("# Data Engineer\n\nWe need Python, Spark and Airflow experience building data pipelines with dbt.")
+	got, err := SelectTemplates(a, TemplateAuto, nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "data" || got.Cover == nil || *got.Cover != "Dear data team," {
+		t.Errorf("auto picked %q (cover %v), want data with its own cover", got.Name, got.Cover)
+	}
+
+	got, err = SelectTemplates(a, "backend", nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cover == nil || *got.Cover != defaultCoverTemplate {
+		t.Errorf("backend variant should fall back to cover.txt")
+	}
+
+	if _, err := SelectTemplates(a, "../config", nodes); err == nil {
+		t.Error("expected error for path-like template name")
+	}
+	if _, err := SelectTemplates(a, "missing", nodes); err == nil {
+		t.Error("expected error for unknown template")
+	}
+
+	// A catch-all variant shares more raw keywords with every posting, but
+	// the focused variant is the closer match.
+	write("resumes/everything.txt", "JANE DOE\nBackend engineer: Go, PostgreSQL, gRPC, Kubernetes services\n"+
+		"Python, Spark, Airflow, dbt, data pipelines and warehousing\nReact, TypeScript, Figma, Swift, Kotlin, Terraform, AWS, GCP, Azure")
+	nodes = Parse("# Backend Engineer\n\nBuild Go and gRPC services on PostgreSQL and Kubernetes.")
+	if got, err := SelectTemplates(a, TemplateAuto, nodes); err != nil || got.Name != "backend" {
+		t.Errorf("auto picked %v (%v), want backend", got, err)
+	}
+}
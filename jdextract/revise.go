@@ -0,0 +1,242 @@
+package jdextract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TaskRevise is the Config.Tasks key for targeted document revisions.
+const TaskRevise = "revise"
+
+// jdFile holds the raw job description text a job was generated from.
+const jdFile = "jd.txt"
+
+// revisionsDir is the job subdirectory holding superseded document versions.
+const revisionsDir = "revisions"
+
+// revisionTimeFormat names revision files so lexical order is chronological.
+const revisionTimeFormat = "20060102T150405.000000000Z"
+
+// Revision targets and the job files they name.
+const (
+	TargetResume = "resume"
+	TargetCover  = "cover"
+)
+
+var targetFiles = map[string]string{
+	TargetResume: "resume.txt",
+	TargetCover:  "cover.txt",
+}
+
+const reviseDocumentPrompt = `You are revising one document of a job application according to the candidate's instruction. Apply the instruction and keep everything else unchanged. Use only facts present in the current document or the base resume; never invent employers, titles, dates, metrics, or skills.
+
+Respond with the complete revised document inside <%[1]s></%[1]s> tags and nothing else.`
+
+// ReviseInput is the material for a targeted revision of one job document.
+type ReviseInput struct {
+	Target         string // TargetResume or TargetCover
+	Instructions   string
+	Current        string // the document as it is now
+	BaseResume     string // source of truth for facts; may be empty
+	JobDescription string // raw JD text; may be empty for jobs predating jd.txt
+}
+
+// ReviseDocument asks the LLM to apply in.Instructions to in.Current and
+// returns the revised document. Streaming and reasoning behave as in
+// GenerateAll; a response missing the target tag gets one repair turn.
+func ReviseDocument(
+	ctx context.Context,
+	invoker LLMInvoker,
+	streamInvoker StreamingLLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	systemPrompt string,
+	in ReviseInput,
+	onDelta func(string),
+	onReasoning func(string),
+) (string, error) {
+	if _, ok := targetFiles[in.Target]; !ok {
+		return "", fmt.Errorf("invalid target %q: must be resume or cover", in.Target)
+	}
+
+	var sb strings.Builder
+	if in.JobDescription != "" {
+		fmt.Fprintf(&sb, "JOB DESCRIPTION:\n%s\n\n", Sanitize(in.JobDescription))
+	}
+	if in.BaseResume != "" {
+		fmt.Fprintf(&sb, "BASE RESUME:\n%s\n\n", Sanitize(in.BaseResume))
+	}
+	fmt.Fprintf(&sb, "CURRENT %s:\n%s\n\nINSTRUCTION:\n%s", strings.ToUpper(in.Target), Sanitize(in.Current), in.Instructions)
+
+	messages := []deepseekMessage{
+		{Role: "system", Content: strings.TrimSpace(systemPrompt + "\n\n" + fmt.Sprintf(reviseDocumentPrompt, in.Target))},
+		{Role: "user", Content: sb.String()},
+	}
+	content, _, _, err := complete(ctx, invoker, streamInvoker, apiKey, params, c, messages, onDelta, onReasoning)
+	if err != nil {
+		return "", err
+	}
+
+	tags := []string{in.Target}
+	re := tagRe(in.Target)
+	content, _ = repairTagged(content, tags)
+	out := extractTag(re, content)
+	if out == "" && invoker != nil {
+		reply, _, err := requestMissingTags(ctx, invoker, apiKey, params, c, messages, content, tags, tags)
+		if err != nil {
+			return "", err
+		}
+		out = extractTag(re, reply)
+	}
+	if out == "" {
+		return "", fmt.Errorf("llm response missing <%s>", in.Target)
+	}
+	return out, nil
+}
+
+// ReviseJob revises a job's resume or cover letter per instructions, using
+// the stored JD and the job's base template, and saves the result as a new
+// revision. Progress, content deltas, and reasoning go to onProgress.
+func (a *App) ReviseJob(ctx context.Context, id, target, instructions string, onProgress func(ProgressEvent)) error {
+	file, ok := targetFiles[target]
+	if !ok {
+		return fmt.Errorf("invalid target %q: must be resume or cover", target)
+	}
+	if strings.TrimSpace(instructions) == "" {
+		return fmt.Errorf("instructions are required")
+	}
+	if !ValidID(id) {
+		return fmt.Errorf("invalid job id %q", id)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	current, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return fmt.Errorf("read %s: %w", file, err)
+	}
+	in := ReviseInput{Target: target, Instructions: instructions, Current: string(current)}
+	if jd, err := os.ReadFile(filepath.Join(dir, jdFile)); err == nil {
+		in.JobDescription = string(jd)
+	}
+	if meta, err := a.Jobs.ReadMeta(id); err == nil {
+		if base, err := LoadTemplates(a, meta.Template); err == nil {
+			in.BaseResume = base.Resume
+		}
+	}
+
+	data, err := tailorPromptData(Parse(in.JobDescription), in.BaseResume, nil)
+	if err != nil {
+		return err
+	}
+	prompt, err := a.PromptConfig.render(data)
+	if err != nil {
+		return fmt.Errorf("render prompt: %w", err)
+	}
+
+	b := a.BackendFor(TaskRevise, onProgress)
+	onProgress(ProgressEvent{Stage: StageGenerating, Message: fmt.Sprintf("Revising %s\u2026", target)})
+	revised, err := ReviseDocument(ctx, b.Invoker, b.StreamInvoker, b.APIKey, b.Params, &a.Client, prompt.SystemPrompt, in,
+		func(d string) { onProgress(ProgressEvent{Stage: StageContent, Delta: d}) },
+		func(d string) { onProgress(ProgressEvent{Stage: StageReasoning, Delta: d}) },
+	)
+	flushTranscripts(b.Transcript, dir)
+	if err != nil {
+		return fmt.Errorf("revise: %w", err)
+	}
+
+	onProgress(ProgressEvent{Stage: StageSaving, Message: "Saving revision\u2026"})
+	_, err = SaveRevision(a, id, target, revised)
+	return err
+}
+
+// SaveRevision replaces a job's resume or cover letter with content, first
+// moving the current version to revisions/<target>-<timestamp>.txt. A new
+// resume is re-checked for ATS readiness. It returns the name of the archived
+// revision, or "" if there was no previous file.
+func SaveRevision(a *App, id, target, content string) (string, error) {
+	file, ok := targetFiles[target]
+	if !ok {
+		return "", fmt.Errorf("invalid target %q: must be resume or cover", target)
+	}
+	if !ValidID(id) {
+		return "", fmt.Errorf("invalid job id %q", id)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id)
+	path := filepath.Join(dir, file)
+
+	var name string
+	if prev, err := os.ReadFile(path); err == nil {
+		if err := os.MkdirAll(filepath.Join(dir, revisionsDir), 0755); err != nil {
+			return "", fmt.Errorf("create revisions directory: %w", err)
+		}
+		name = target + "-" + time.Now().UTC().Format(revisionTimeFormat) + ".txt"
+		if err := os.WriteFile(filepath.Join(dir, revisionsDir, name), prev, 0644); err != nil {
+			return "", fmt.Errorf("archive %s: %w", file, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("read %s: %w", file, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", file, err)
+	}
+	if target == TargetResume {
+		a.refreshATS(id)
+	}
+	return name, nil
+}
+
+// RevisionSummary describes one archived document version.
+type RevisionSummary struct {
+	Name   string `json:"name"`
+	Target string `json:"target"`
+	Time   string `json:"time"` // RFC3339 time the version was superseded
+}
+
+// ListRevisions returns a job's archived versions, newest first.
+func ListRevisions(a *App, id string) ([]RevisionSummary, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	entries, err := os.ReadDir(filepath.Join(a.Paths.Jobs, id, revisionsDir))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []RevisionSummary{}, nil
+		}
+		return nil, err
+	}
+	out := []RevisionSummary{}
+	for _, e := range entries {
+		stem, ok := strings.CutSuffix(e.Name(), ".txt")
+		target, stamp, found := strings.Cut(stem, "-")
+		if !ok || !found || e.IsDir() {
+			continue
+		}
+		t, err := time.Parse(revisionTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		out = append(out, RevisionSummary{Name: e.Name(), Target: target, Time: t.Format(time.RFC3339)})
+	}
+	stamp := func(name string) string { _, s, _ := strings.Cut(name, "-"); return s }
+	sort.Slice(out, func(i, j int) bool { return stamp(out[i].Name) > stamp(out[j].Name) })
+	return out, nil
+}
+
+// GetRevision reads one archived version by file name.
+func GetRevision(a *App, id, name string) (string, error) {
+	if !ValidID(id) || !ValidID(name) || !strings.HasSuffix(name, ".txt") {
+		return "", fmt.Errorf("invalid revision %q", name)
+	}
+	b, err := os.ReadFile(filepath.Join(a.Paths.Jobs, id, revisionsDir, name))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,73 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReviseDocument(t *testing.T) {
+	var sent deepseekRequest
+	invoker := func(_ context.Context, _ string, _ *http.Client, _ int, body json.RawMessage) (string, error) {
+		if err := json.Unmarshal(body, &sent); err != nil {
+			t.Fatal(err)
+		}
+		return completionBody("<Cover>\nShorter letter.", "")
+	}
+	out, err := ReviseDocument(context.Background(), invoker, nil, "", TaskParams{Model: "m"}, nil, "", ReviseInput{
+		Target:         TargetCover,
+		Instructions:   "make it shorter",
+		Current:        "A very long letter.",
+		JobDescription: "Senior Copywriter at Acme",
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("ReviseDocument: %v", err)
+	}
+	if out != "Shorter letter." {
+		t.Errorf("revised = %q", out)
+	}
+	user := sent.Messages[1].Content
+	for _, want := range []string{"Senior Copywriter at Acme", "CURRENT COVER:\nA very long letter.", "make it shorter"} {
+		if !strings.Contains(user, want) {
+			t.Errorf("prompt missing %q", want)
+		}
+	}
+
+	if _, err := ReviseDocument(context.Background(), invoker, nil, "", TaskParams{}, nil, "", ReviseInput{Target: "meta"}, nil, nil); err == nil {
+		t.Error("expected error for invalid target")
+	}
+}
+
+func TestSaveRevision(t *testing.T) {
+	a := newTestApp(t)
+	id := "2026-10-18-acme"
+	if err := os.MkdirAll(filepath.Join(a.Paths.Jobs, id), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(a.Paths.Jobs, id, "resume.txt"), []byte("v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []string{"v2", "v3"} {
+		if _, err := SaveRevision(a, id, TargetResume, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cur, _ := os.ReadFile(filepath.Join(a.Paths.Jobs, id, "resume.txt"))
+	if string(cur) != "v3" {
+		t.Errorf("resume.txt = %q, want v3", cur)
+	}
+	revs, err := ListRevisions(a, id)
+	if err != nil || len(revs) != 2 {
+		t.Fatalf("ListRevisions = %v, %v", revs, err)
+	}
+	if got, _ := GetRevision(a, id, revs[0].Name); got != "v2" || revs[0].Target != TargetResume {
+		t.Errorf("newest revision = %q (%s), want v2", got, revs[0].Target)
+	}
+	if _, err := GetRevision(a, id, "../meta.json"); err == nil {
+		t.Error("expected error for path-like revision name")
+	}
+}
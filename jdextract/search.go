@@ -31,6 +31,9 @@ func matchesQuery(q string, fields ...string) bool {
 //   - score_max:  inclusive upper bound on Score
 //   - date_from:  YYYY-MM-DD — include jobs on or after this date
 //   - date_to:    YYYY-MM-DD — include jobs on or before this date
+//
+// Requirement filters (met, partial, missing) read analysis.json and are
+// applied separately by applyAnalysisFilters.
 func applyJobFilters(jobs []ApplicationMeta, r *http.Request) []ApplicationMeta {
 	q := r.URL.Query().Get("q")
 	status := r.URL.Query().Get("status")
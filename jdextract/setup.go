@@ -28,11 +28,18 @@ func (a *App) createExampleTemplates() error {
 }
 
 // Setup creates the portable directory structure (data/, config/, data/jobs/,
-// config/templates/) and writes example resume.txt and cover.txt templates if
+// config/templates/ with its resumes/ and covers/ variant folders and
+// cover_blocks/) and writes example resume.txt and cover.txt templates if
 // they do not already exist. It is safe to call Setup on an existing installation;
 // it will not overwrite files the user has already customised.
 func (a *App) Setup() error {
-	for _, dir := range []string{a.Paths.Data, a.Paths.Config, a.Paths.Jobs, a.Paths.Templates, a.Paths.Contacts} {
+	dirs := []string{
+		a.Paths.Data, a.Paths.Config, a.Paths.Jobs, a.Paths.Templates, a.Paths.Contacts,
+		filepath.Join(a.Paths.Templates, resumeVariantsDir),
+		filepath.Join(a.Paths.Templates, coverVariantsDir),
+		filepath.Join(a.Paths.Templates, coverBlocksDir),
+	}
+	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			return fmt.Errorf("cannot create %s: %w", dir, err)
 		}
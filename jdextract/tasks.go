@@ -0,0 +1,100 @@
+package jdextract
+
+import (
+	"fmt"
+	"slices"
+)
+
+// LLM task names. Each task may override the backend, model, and sampling
+// parameters through Config.Tasks; unknown names are accepted so new tasks
+// can be configured before the UI knows about them.
+const (
+	TaskTailor    = "tailor"
+	TaskFollowup  = "followup"
+	TaskSummarize = "summarize"
+)
+
+// TaskParams selects the backend, model, and sampling parameters for one LLM
+// task. Zero values defer to the provider default: an empty Backend or Model
+// falls back to Config.Backend and that backend's configured model, and nil
+// Temperature/TopP or zero MaxTokens are omitted from the request.
+type TaskParams struct {
+	Backend     string   `json:"backend,omitempty"` // "deepseek" or "kimi"
+	Model       string   `json:"model,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+}
+
+// request builds a chat completion request for messages using p.
+func (p TaskParams) request(messages []deepseekMessage, stream bool) deepseekRequest {
+	var opts *streamOptions
+	if stream {
+		opts = &streamOptions{IncludeUsage: true}
+	}
+	return deepseekRequest{
+		Model:         p.Model,
+		Messages:      messages,
+		Stream:        stream,
+		StreamOptions: opts,
+		Temperature:   p.Temperature,
+		TopP:          p.TopP,
+		MaxTokens:     p.MaxTokens,
+		Stop:          p.Stop,
+	}
+}
+
+// validate checks p against the provider limits. A model is checked against
+// its task backend when one is set, otherwise against every known model since
+// the default backend may change later.
+func (p TaskParams) validate() error {
+	if p.Backend != "" && !slices.Contains(validBackends, p.Backend) {
+		return fmt.Errorf("backend must be deepseek or kimi")
+	}
+	if p.Model != "" {
+		models := append(slices.Clone(validDeepSeekModels), validKimiModels...)
+		switch p.Backend {
+		case "deepseek":
+			models = validDeepSeekModels
+		case "kimi":
+			models = validKimiModels
+		}
+		if !slices.Contains(models, p.Model) {
+			return fmt.Errorf("unknown model %q", p.Model)
+		}
+	}
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		return fmt.Errorf("top_p must be greater than 0 and at most 1")
+	}
+	if p.MaxTokens < 0 {
+		return fmt.Errorf("max_tokens must be zero or positive")
+	}
+	if len(p.Stop) > 4 {
+		return fmt.Errorf("at most 4 stop sequences are allowed")
+	}
+	return nil
+}
+
+// TaskParams returns the resolved parameters for task: its Config.Tasks entry
+// with Backend and Model filled in from the global config where unset.
+func (a *App) TaskParams(task string) TaskParams {
+	p := a.Config.Tasks[task]
+	if p.Backend == "" {
+		p.Backend = a.Config.Backend
+	}
+	if p.Backend == "" {
+		p.Backend = "deepseek"
+	}
+	if p.Model == "" {
+		if p.Backend == "kimi" {
+			p.Model = a.Config.KimiModel
+		} else {
+			p.Model = a.Config.DeepSeekModel
+		}
+	}
+	return p
+}
@@ -0,0 +1,73 @@
+package jdextract
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTaskParamsResolve(t *testing.T) {
+	temp := 0.2
+	a := &App{Config: Config{
+		Backend:       "deepseek",
+		DeepSeekModel: "deepseek-reasoner",
+		KimiModel:     "moonshotai/Kimi-K2.5",
+		Tasks: map[string]TaskParams{
+			TaskSummarize: {Backend: "kimi", Temperature: &temp, MaxTokens: 128},
+			TaskFollowup:  {Model: "deepseek-chat"},
+		},
+	}}
+
+	cases := []struct {
+		task, backend, model string
+	}{
+		{TaskTailor, "deepseek", "deepseek-reasoner"},
+		{TaskSummarize, "kimi", "moonshotai/Kimi-K2.5"},
+		{TaskFollowup, "deepseek", "deepseek-chat"},
+	}
+	for _, tc := range cases {
+		p := a.TaskParams(tc.task)
+		if p.Backend != tc.backend || p.Model != tc.model {
+			t.Errorf("%s: got %s/%s, want %s/%s", tc.task, p.Backend, p.Model, tc.backend, tc.model)
+		}
+		if b := a.BackendFor(tc.task, nil); b.Params.Model != tc.model {
+			t.Errorf("%s: backend model %q, want %q", tc.task, b.Params.Model, tc.model)
+		}
+	}
+
+	body, err := json.Marshal(a.TaskParams(TaskSummarize).request(nil, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := string(body)
+	if !strings.Contains(s, `"temperature":0.2`) || !strings.Contains(s, `"max_tokens":128`) {
+		t.Errorf("request missing sampling parameters: %s", s)
+	}
+	if strings.Contains(s, "top_p") || strings.Contains(s, "stop") {
+		t.Errorf("request should omit unset parameters: %s", s)
+	}
+}
+
+func TestTaskParamsValidate(t *testing.T) {
+	hot, zero := 2.5, 0.0
+	cases := []struct {
+		name string
+		p    TaskParams
+		ok   bool
+	}{
+		{"empty", TaskParams{}, true},
+		{"known model", TaskParams{Model: "deepseek-chat"}, true},
+		{"model on wrong backend", TaskParams{Backend: "kimi", Model: "deepseek-chat"}, false},
+		{"unknown backend", TaskParams{Backend: "openai"}, false},
+		{"temperature too high", TaskParams{Temperature: &hot}, false},
+		{"zero temperature", TaskParams{Temperature: &zero}, true},
+		{"zero top_p", TaskParams{TopP: &zero}, false},
+		{"negative max_tokens", TaskParams{MaxTokens: -1}, false},
+		{"too many stops", TaskParams{Stop: []string{"a", "b", "c", "d", "e"}}, false},
+	}
+	for _, tc := range cases {
+		if err := tc.p.validate(); (err == nil) != tc.ok {
+			t.Errorf("%s: validate() = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package jdextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// maxImportSize bounds an imported template file.
+const maxImportSize = 10 << 20
+
+// sectionNames are headings normalized to ALL CAPS wherever they appear on a
+// line of their own, with or without a trailing colon.
+var sectionNames = map[string]bool{
+	"summary": true, "professional summary": true, "profile": true, "objective": true,
+	"experience": true, "work experience": true, "professional experience": true,
+	"employment history": true, "relevant experience": true,
+	"education": true, "skills": true, "technical skills": true, "core competencies": true,
+	"projects": true, "certifications": true, "licenses and certifications": true,
+	"awards": true, "publications": true, "volunteer experience": true, "volunteering": true,
+	"languages": true, "interests": true, "references": true,
+}
+
+var (
+	importBulletRe   = regexp.MustCompile(`^(?:[-*+•·▪◦‣–o]|\d+[.)])\s+`)
+	mdHeadingRe      = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*$`)
+	mdRuleRe         = regexp.MustCompile(`^(?:-{3,}|\*{3,}|_{3,}|={3,})$`)
+	mdLinkRe         = regexp.MustCompile(`\[([^\]]*)\]\(([^)]*)\)`)
+	mdEmphasisRe     = regexp.MustCompile(`\*\*|__|` + "`")
+	importBlankRunRe = regexp.MustCompile(`\n{3,}`)
+)
+
+// importLine is a line of an imported document before normalization.
+type importLine struct {
+	Text    string
+	Heading int // heading level, 0 for body text
+	Bullet  bool
+}
+
+// ImportTemplate converts an uploaded resume or cover letter to the plain-text
+// template format. filename selects the reader: .docx (also detected by its
+// zip signature), .md/.markdown, or plain text for anything else. Bullets
+// become "• " lines, and section headings become ALL CAPS lines preceded by
+// a blank line.
+func ImportTemplate(filename string, data []byte) (string, error) {
+	if len(data) > maxImportSize {
+		return "", fmt.Errorf("file too large: %d bytes (max %d)", len(data), maxImportSize)
+	}
+	var lines []importLine
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(filename)); {
+	case ext == ".docx" || bytes.HasPrefix(data, []byte("PK\x03\x04")):
+		lines, err = docxLines(data)
+	case ext == ".doc" || ext == ".pdf" || ext == ".odt" || ext == ".rtf":
+		return "", fmt.Errorf("unsupported file type %s: save it as .docx, Markdown, or plain text", ext)
+	case ext == ".md" || ext == ".markdown":
+		lines = markdownLines(string(data))
+	default:
+		lines = textLines(string(data))
+	}
+	if err != nil {
+		return "", err
+	}
+	return normalizeTemplate(lines), nil
+}
+
+// normalizeTemplate renders lines as a template. The first non-empty line is
+// the name, so a heading there stays as written. Of the remaining headings,
+// the shallowest level marks sections; deeper levels (often role titles) are
+// kept as plain lines.
+func normalizeTemplate(lines []importLine) string {
+	section := 0
+	first := true
+	for i, l := range lines {
+		if strings.TrimSpace(l.Text) == "" {
+			continue
+		}
+		if first {
+			lines[i].Heading = 0
+			first = false
+			continue
+		}
+		if l.Heading > 0 && (section == 0 || l.Heading < section) {
+			section = l.Heading
+		}
+	}
+
+	var sb strings.Builder
+	for _, l := range lines {
+		text := strings.Join(strings.Fields(l.Text), " ")
+		switch {
+		case text == "":
+			sb.WriteString("\n")
+		case l.Bullet:
+			sb.WriteString("• " + text + "\n")
+		case l.Heading > 0 && l.Heading == section, l.Heading == 0 && isSectionLine(text):
+			sb.WriteString("\n" + strings.ToUpper(strings.TrimSuffix(text, ":")) + "\n")
+		default:
+			sb.WriteString(text + "\n")
+		}
+	}
+	out := importBlankRunRe.ReplaceAllString(sb.String(), "\n\n")
+	return strings.TrimSpace(out) + "\n"
+}
+
+// isSectionLine reports whether a body line is a section heading: a known
+// section name, or a short ALL CAPS line that is not a name or contact line.
+func isSectionLine(text string) bool {
+	if sectionNames[strings.ToLower(strings.TrimSuffix(text, ":"))] {
+		return true
+	}
+	return isUpperLine(text) && len(text) <= 40 && !strings.ContainsAny(text, "|@0123456789")
+}
+
+// textLines reads plain text, recognizing common bullet markers.
+func textLines(s string) []importLine {
+	var out []importLine
+	for _, raw := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := strings.TrimSpace(raw)
+		if loc := importBulletRe.FindStringIndex(line); loc != nil {
+			out = append(out, importLine{Text: line[loc[1]:], Bullet: true})
+			continue
+		}
+		out = append(out, importLine{Text: line})
+	}
+	return out
+}
+
+// markdownLines reads Markdown headings and lists, dropping emphasis,
+// rules, and block quote markers. Links become "text (url)", or just the
+// URL when the text is the URL.
+func markdownLines(s string) []importLine {
+	var out []importLine
+	for _, l := range textLines(s) {
+		text := strings.TrimSpace(strings.TrimLeft(l.Text, ">"))
+		if mdRuleRe.MatchString(text) {
+			continue
+		}
+		text = mdLinkRe.ReplaceAllStringFunc(text, func(m string) string {
+			sub := mdLinkRe.FindStringSubmatch(m)
+			if sub[1] == "" || sub[1] == sub[2] || "mailto:"+sub[1] == sub[2] {
+				return strings.TrimPrefix(sub[2], "mailto:")
+			}
+			return sub[1] + " (" + sub[2] + ")"
+		})
+		text = mdEmphasisRe.ReplaceAllString(text, "")
+		if m := mdHeadingRe.FindStringSubmatch(text); m != nil && !l.Bullet {
+			out = append(out, importLine{Text: m[2], Heading: len(m[1])})
+			continue
+		}
+		out = append(out, importLine{Text: text, Bullet: l.Bullet})
+	}
+	return out
+}
+
+// docxLines reads the paragraphs of a .docx body. Heading and Title styles
+// give heading levels, list paragraphs become bullets, tabs between text
+// (typically before right-aligned dates) become " | ", and line breaks
+// outside lists split paragraphs.
+func docxLines(data []byte) ([]importLine, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("read docx: %w", err)
+	}
+	var doc *zip.File
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			doc = f
+		}
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("read docx: no word/document.xml")
+	}
+	rc, err := doc.Open()
+	if err != nil {
+		return nil, fmt.Errorf("read docx: %w", err)
+	}
+	defer rc.Close()
+
+	var out []importLine
+	var cur importLine
+	var text strings.Builder
+	inRun, inText := false, false
+	flush := func() {
+		s := strings.Trim(text.String(), "\t ")
+		s = strings.ReplaceAll(s, "\t", " | ")
+		for strings.Contains(s, "|  | ") {
+			s = strings.ReplaceAll(s, "|  | ", "| ")
+		}
+		cur.Text = s
+		out = append(out, cur)
+		cur = importLine{Heading: cur.Heading, Bullet: cur.Bullet}
+		text.Reset()
+	}
+
+	d := xml.NewDecoder(io.LimitReader(rc, maxImportSize*10))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read docx: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				cur = importLine{}
+				text.Reset()
+			case "pStyle":
+				cur.Heading, cur.Bullet = docxStyleRole(xmlAttr(t, "val"))
+			case "numPr":
+				if cur.Heading == 0 {
+					cur.Bullet = true
+				}
+			case "r":
+				inRun = true
+			case "t":
+				inText = true
+			case "tab":
+				// Tab stops in paragraph properties are also <w:tab>.
+				if inRun {
+					text.WriteString("\t")
+				}
+			case "br", "cr":
+				// A soft break continues a bullet but separates lines
+				// elsewhere, e.g. in a contact block.
+				if !inRun {
+					break
+				}
+				if cur.Bullet {
+					text.WriteString(" ")
+				} else {
+					flush()
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "r":
+				inRun = false
+			case "t":
+				inText = false
+			case "p":
+				flush()
+			}
+		case xml.CharData:
+			if inText {
+				text.Write(t)
+			}
+		}
+	}
+	return out, nil
+}
+
+// docxStyleRole maps a paragraph style ID to a heading level or bullet.
+func docxStyleRole(style string) (heading int, bullet bool) {
+	s := strings.ToLower(style)
+	switch {
+	case s == "title":
+		return 1, false
+	case strings.HasPrefix(s, "heading"):
+		var n int
+		if _, err := fmt.Sscanf(s[len("heading"):], "%d", &n); err == nil && n > 0 {
+			return n + 1, false
+		}
+		return 2, false
+	case strings.HasPrefix(s, "listbullet"), strings.HasPrefix(s, "listparagraph"):
+		return 0, true
+	}
+	return 0, false
+}
+
+func xmlAttr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// TemplatePath is the file a template import writes: resume.txt or cover.txt
+// for the default template, or the named variant's file.
+func TemplatePath(a *App, cover bool, name string) (string, error) {
+	if name == "" || name == TemplateDefault {
+		if cover {
+			return filepath.Join(a.Paths.Templates, "cover.txt"), nil
+		}
+		return filepath.Join(a.Paths.Templates, "resume.txt"), nil
+	}
+	if !validTemplateName(name) {
+		return "", fmt.Errorf("invalid template name %q", name)
+	}
+	dir := resumeVariantsDir
+	if cover {
+		dir = coverVariantsDir
+	}
+	return filepath.Join(a.Paths.Templates, dir, name+".txt"), nil
+}
+
+// SaveTemplate writes text to path, creating the variant directory if needed.
+func SaveTemplate(path, text string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(text), 0644)
+}
+
+// TemplateImport is an imported template and the file it replaces, for
+// review before saving.
+type TemplateImport struct {
+	Text    string `json:"text"`
+	Current string `json:"current"` // "" if the template does not exist yet
+	Path    string `json:"path"`    // relative to config/templates/
+	Saved   bool   `json:"saved"`
+}
+
+// PreviewTemplateImport converts an uploaded file (see ImportTemplate) and
+// pairs it with the template it would replace: the resume or, with cover,
+// the cover letter of the default template or the named variant.
+func PreviewTemplateImport(a *App, filename string, data []byte, cover bool, name string) (*TemplateImport, error) {
+	path, err := TemplatePath(a, cover, name)
+	if err != nil {
+		return nil, err
+	}
+	text, err := ImportTemplate(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	rel, _ := filepath.Rel(a.Paths.Templates, path)
+	imp := &TemplateImport{Text: text, Path: filepath.ToSlash(rel)}
+	if b, err := os.ReadFile(path); err == nil {
+		imp.Current = string(b)
+	}
+	return imp, nil
+}
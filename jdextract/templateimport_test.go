@@ -0,0 +1,152 @@
+package jdextract
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestImportTemplateText(t *testing.T) {
+	in := "Jane Doe\r\njane@example.com\r\n\r\nExperience:\r\nSenior Copywriter | Acme Corp\r\n- Grew subscribers 40%\r\n* Wrote launch copy\r\n1. Ran workshops\r\n\r\n\r\n\r\nskills\r\n▪ SEO\r\n"
+	got, err := ImportTemplate("resume.txt", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Jane Doe
+jane@example.com
+
+EXPERIENCE
+Senior Copywriter | Acme Corp
+• Grew subscribers 40%
+• Wrote launch copy
+• Ran workshops
+
+SKILLS
+• SEO
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportTemplateMarkdown(t *testing.T) {
+	in := `# Jane Doe
+[jane@example.com](mailto:jane@example.com) | [LinkedIn](https://linkedin.com/in/jane)
+
+---
+
+## Experience
+### Senior Copywriter | Acme Corp
+- **Grew** subscribers by 40%
+
+## Skills
+- ` + "`SEO`" + `, content strategy
+`
+	got, err := ImportTemplate("resume.md", []byte(in))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `Jane Doe
+jane@example.com | LinkedIn (https://linkedin.com/in/jane)
+
+EXPERIENCE
+Senior Copywriter | Acme Corp
+• Grew subscribers by 40%
+
+SKILLS
+• SEO, content strategy
+`
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportTemplateDOCX(t *testing.T) {
+	// A document written by the DOCX export reads back as the same template.
+	var buf bytes.Buffer
+	if err := writeDOCX(&buf, parseDocument(sampleResume), nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ImportTemplate("resume.docx", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `JANE DOE
+jane@example.com | (555) 123-4567
+
+PROFESSIONAL SUMMARY
+Copywriter with eight years of experience writing B2B campaigns for software companies and agencies.
+
+EXPERIENCE
+Senior Copywriter | Acme Corp | 2020 - Present
+• Grew newsletter subscribers by 40% in one year
+• Wrote launch copy for three products
+Thanks,
+Jane
+`
+	if got != want {
+		t.Errorf("round trip got:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Word-style markup: tab stops, tabs before dates, soft breaks, and
+	// numbered list paragraphs under a Heading2 section style.
+	doc := `<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Jane Doe</w:t></w:r></w:p>
+<w:p><w:r><w:t>jane@example.com</w:t><w:br/><w:t>Berlin</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Experience</w:t></w:r></w:p>
+<w:p><w:pPr><w:tabs><w:tab w:val="right" w:pos="9000"/></w:tabs></w:pPr><w:r><w:t>Acme Corp</w:t><w:tab/><w:t>2020 – Present</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="3"/></w:numPr></w:pPr><w:r><w:t xml:space="preserve">Grew </w:t></w:r><w:r><w:t>subscribers</w:t><w:br/><w:t>by 40%</w:t></w:r></w:p>
+</w:body></w:document>`
+	var raw bytes.Buffer
+	zw := zip.NewWriter(&raw)
+	writeZipFile(zw, "word/document.xml", []byte(doc))
+	zw.Close()
+	got, err = ImportTemplate("upload", raw.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = `Jane Doe
+jane@example.com
+Berlin
+
+EXPERIENCE
+Acme Corp | 2020 – Present
+• Grew subscribers by 40%
+`
+	if got != want {
+		t.Errorf("word markup got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if _, err := ImportTemplate("resume.pdf", []byte("%PDF-1.4")); err == nil {
+		t.Error("pdf: want unsupported error")
+	}
+}
+
+func TestPreviewTemplateImport(t *testing.T) {
+	a := newTestApp(t)
+	if err := os.WriteFile(filepath.Join(a.Paths.Templates, "resume.txt"), []byte("OLD\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	imp, err := PreviewTemplateImport(a, "resume.txt", []byte("NEW"), false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp.Current != "OLD\n" || imp.Text != "NEW\n" || imp.Path != "resume.txt" {
+		t.Errorf("default = %+v", imp)
+	}
+	imp, err = PreviewTemplateImport(a, "cover.md", []byte("NEW"), true, "backend")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imp.Current != "" || imp.Path != "covers/backend.txt" {
+		t.Errorf("variant = %+v", imp)
+	}
+	if err := SaveTemplate(filepath.Join(a.Paths.Templates, imp.Path), imp.Text); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PreviewTemplateImport(a, "x.txt", nil, false, "../evil"); err == nil {
+		t.Error("invalid name: want error")
+	}
+}
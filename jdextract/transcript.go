@@ -0,0 +1,254 @@
+package jdextract
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// transcriptDir is the per-job (or per-contact) subdirectory holding transcripts.
+const transcriptDir = "llm"
+
+// transcriptTimeFormat names transcript files so lexical order is chronological.
+const transcriptTimeFormat = "20060102T150405.000000000Z"
+
+// TranscriptConfig controls opt-in LLM transcript logging. When Enabled, every
+// LLM call made on behalf of a job or contact is written to llm/<timestamp>.json
+// inside its directory. RetentionDays and MaxFiles bound how many transcripts
+// are kept per directory; zero disables the respective limit.
+type TranscriptConfig struct {
+	Enabled       bool `json:"enabled"`
+	RetentionDays int  `json:"retention_days,omitempty"`
+	MaxFiles      int  `json:"max_files,omitempty"`
+}
+
+// Transcript is a single recorded LLM call.
+type Transcript struct {
+	Time      string          `json:"time"` // RFC3339 start time
+	Backend   string          `json:"backend"`
+	Model     string          `json:"model"`
+	Stream    bool            `json:"stream"`
+	Request   json.RawMessage `json:"request"`             // exact request body sent
+	Response  string          `json:"response,omitempty"`  // raw response body, or accumulated stream content
+	Reasoning string          `json:"reasoning,omitempty"` // streamed reasoning deltas, if any
+	LatencyMS int64           `json:"latency_ms"`
+	Tokens    int             `json:"tokens,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// TranscriptSummary is the listing form of a Transcript, without bodies.
+type TranscriptSummary struct {
+	Name      string `json:"name"`
+	Time      string `json:"time"`
+	Model     string `json:"model"`
+	Stream    bool   `json:"stream"`
+	LatencyMS int64  `json:"latency_ms"`
+	Tokens    int    `json:"tokens,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// authHeaderRe matches credentials in the two Authorization schemes we send,
+// in case a provider echoes request headers back in an error body.
+var authHeaderRe = regexp.MustCompile(`(?i)(Bearer|Api-Key)\s+[A-Za-z0-9._\-]+`)
+
+// TranscriptRecorder buffers transcripts in memory until the owning directory
+// is known. A nil *TranscriptRecorder is valid and records nothing, so callers
+// can use it unconditionally.
+type TranscriptRecorder struct {
+	cfg     TranscriptConfig
+	backend string
+	model   string
+	secrets []string
+
+	mu      sync.Mutex
+	entries []Transcript
+}
+
+// redact strips API keys and Authorization values from s.
+func (r *TranscriptRecorder) redact(s string) string {
+	for _, k := range r.secrets {
+		if k != "" {
+			s = strings.ReplaceAll(s, k, "[redacted]")
+		}
+	}
+	return authHeaderRe.ReplaceAllString(s, "$1 [redacted]")
+}
+
+func (r *TranscriptRecorder) record(start time.Time, stream bool, body json.RawMessage, response, reasoning string, tokens int, err error) {
+	t := Transcript{
+		Time:      start.UTC().Format(time.RFC3339Nano),
+		Backend:   r.backend,
+		Model:     r.model,
+		Stream:    stream,
+		Request:   json.RawMessage(r.redact(string(body))),
+		Response:  r.redact(response),
+		Reasoning: reasoning,
+		LatencyMS: time.Since(start).Milliseconds(),
+		Tokens:    tokens,
+	}
+	if err != nil {
+		t.Error = r.redact(err.Error())
+	}
+	r.mu.Lock()
+	r.entries = append(r.entries, t)
+	r.mu.Unlock()
+}
+
+// wrap returns inv with every call recorded.
+func (r *TranscriptRecorder) wrap(inv LLMInvoker) LLMInvoker {
+	return func(ctx context.Context, apiKey string, c *http.Client, backoff int, body json.RawMessage) (string, error) {
+		start := time.Now()
+		raw, err := inv(ctx, apiKey, c, backoff, body)
+		r.record(start, false, body, raw, "", responseTokens(raw), err)
+		return raw, err
+	}
+}
+
+// wrapStream returns inv with every call recorded. The accumulated content is
+// stored as the response since the raw SSE framing carries no extra signal;
+// reasoning deltas are accumulated separately.
+func (r *TranscriptRecorder) wrapStream(inv StreamingLLMInvoker) StreamingLLMInvoker {
+	return func(ctx context.Context, apiKey string, c *http.Client, body json.RawMessage, onDelta, onReasoning func(string)) (string, int, error) {
+		start := time.Now()
+		var reasoning strings.Builder
+		content, tokens, err := inv(ctx, apiKey, c, body, onDelta, func(d string) {
+			reasoning.WriteString(d)
+			if onReasoning != nil {
+				onReasoning(d)
+			}
+		})
+		r.record(start, true, body, content, reasoning.String(), tokens, err)
+		return content, tokens, err
+	}
+}
+
+// Flush writes buffered transcripts to dir/llm/ and applies retention. The
+// buffer is cleared so a recorder can be flushed more than once.
+func (r *TranscriptRecorder) Flush(dir string) error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	entries := r.entries
+	r.entries = nil
+	r.mu.Unlock()
+	if len(entries) == 0 {
+		return nil
+	}
+
+	out := filepath.Join(dir, transcriptDir)
+	if err := os.MkdirAll(out, 0755); err != nil {
+		return fmt.Errorf("create transcript directory: %w", err)
+	}
+	for _, t := range entries {
+		start, _ := time.Parse(time.RFC3339Nano, t.Time)
+		name := start.UTC().Format(transcriptTimeFormat) + ".json"
+		if err := SaveJSON(filepath.Join(out, name), t, 0600); err != nil {
+			return fmt.Errorf("write transcript: %w", err)
+		}
+	}
+	return pruneTranscripts(out, r.cfg)
+}
+
+// flushTranscripts writes rec's transcripts to dir, downgrading failures to a
+// stderr warning — transcripts are diagnostic and must never fail the caller.
+func flushTranscripts(rec *TranscriptRecorder, dir string) {
+	if err := rec.Flush(dir); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: transcripts for %s: %v\n", dir, err)
+	}
+}
+
+// pruneTranscripts removes transcripts older than cfg.RetentionDays and, beyond
+// that, all but the newest cfg.MaxFiles.
+func pruneTranscripts(dir string, cfg TranscriptConfig) error {
+	names, err := transcriptNames(dir)
+	if err != nil {
+		return err
+	}
+	var keep []string
+	cutoff := time.Now().AddDate(0, 0, -cfg.RetentionDays)
+	for _, n := range names {
+		t, err := time.Parse(transcriptTimeFormat, strings.TrimSuffix(n, ".json"))
+		if cfg.RetentionDays > 0 && err == nil && t.Before(cutoff) {
+			if err := os.Remove(filepath.Join(dir, n)); err != nil {
+				return err
+			}
+			continue
+		}
+		keep = append(keep, n)
+	}
+	if cfg.MaxFiles > 0 && len(keep) > cfg.MaxFiles {
+		for _, n := range keep[:len(keep)-cfg.MaxFiles] {
+			if err := os.Remove(filepath.Join(dir, n)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// transcriptNames returns the transcript file names in dir, oldest first.
+func transcriptNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ListTranscripts returns summaries of the transcripts stored under a job
+// directory, newest first. A job without transcripts yields an empty slice.
+func ListTranscripts(a *App, id string) ([]TranscriptSummary, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	dir := filepath.Join(a.Paths.Jobs, id, transcriptDir)
+	names, err := transcriptNames(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []TranscriptSummary{}, nil
+		}
+		return nil, err
+	}
+	out := make([]TranscriptSummary, 0, len(names))
+	for i := len(names) - 1; i >= 0; i-- {
+		t, err := LoadJSON[Transcript](filepath.Join(dir, names[i]))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping transcript %s: %v\n", names[i], err)
+			continue
+		}
+		out = append(out, TranscriptSummary{
+			Name:      names[i],
+			Time:      t.Time,
+			Model:     t.Model,
+			Stream:    t.Stream,
+			LatencyMS: t.LatencyMS,
+			Tokens:    t.Tokens,
+			Error:     t.Error,
+		})
+	}
+	return out, nil
+}
+
+// GetTranscript reads a single transcript by file name from a job directory.
+func GetTranscript(a *App, id, name string) (*Transcript, error) {
+	if !ValidID(id) || !ValidID(name) || !strings.HasSuffix(name, ".json") {
+		return nil, fmt.Errorf("invalid transcript %q", name)
+	}
+	return LoadJSON[Transcript](filepath.Join(a.Paths.Jobs, id, transcriptDir, name))
+}
@@ -0,0 +1,59 @@
+package jdextract
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTranscriptRedact(t *testing.T) {
+	r := &TranscriptRecorder{secrets: []string{"sk-live-123", ""}}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`key sk-live-123 leaked`, `key [redacted] leaked`},
+		{`Authorization: Bearer abc.def-ghi`, `Authorization: Bearer [redacted]`},
+		{`Api-Key zzz999`, `Api-Key [redacted]`},
+		{`nothing secret here`, `nothing secret here`},
+	}
+	for _, tt := range tests {
+		if got := r.redact(tt.input); got != tt.want {
+			t.Errorf("redact(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestPruneTranscripts(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+	stamps := []time.Time{
+		now.AddDate(0, 0, -30),
+		now.Add(-3 * time.Hour),
+		now.Add(-2 * time.Hour),
+		now.Add(-1 * time.Hour),
+	}
+	for _, ts := range stamps {
+		name := ts.Format(transcriptTimeFormat) + ".json"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneTranscripts(dir, TranscriptConfig{RetentionDays: 7, MaxFiles: 2}); err != nil {
+		t.Fatalf("prune: %v", err)
+	}
+	names, err := transcriptNames(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		stamps[2].Format(transcriptTimeFormat) + ".json",
+		stamps[3].Format(transcriptTimeFormat) + ".json",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("kept %v, want %v", names, want)
+	}
+}
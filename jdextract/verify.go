@@ -0,0 +1,366 @@
+package jdextract
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// TaskVerify is the Config.Tasks key for the optional LLM review of findings.
+const TaskVerify = "verify"
+
+// verificationFile is the per-job fabrication check written by Process.
+const verificationFile = "verification.json"
+
+// Finding kinds.
+const (
+	FindingNumber     = "number"
+	FindingPercentage = "percentage"
+	FindingDate       = "date"
+	FindingName       = "name" // multi-word proper noun: a company or job title
+	FindingTerm       = "term" // single proper noun or technology, e.g. "Kafka", "gRPC"
+)
+
+// LLM review verdicts.
+const (
+	VerdictSupported   = "supported"
+	VerdictUnsupported = "unsupported"
+)
+
+// Finding is a claim in the tailored resume that could not be found in the
+// base resume.
+type Finding struct {
+	Kind    string `json:"kind"`
+	Claim   string `json:"claim"`
+	Line    string `json:"line"`              // the tailored resume line it appears on
+	Verdict string `json:"verdict,omitempty"` // set by the optional LLM review
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Warning renders f as a one-line message for ProgressEvent and meta.json.
+func (f Finding) Warning() string {
+	return fmt.Sprintf("%s %q not found in base resume", f.Kind, f.Claim)
+}
+
+// Verification is the fabrication check stored as verification.json.
+type Verification struct {
+	Findings []Finding `json:"findings"`
+	Reviewed bool      `json:"reviewed"` // whether the LLM second opinion ran
+}
+
+// Warnings returns the messages for findings the LLM review did not clear.
+func (v *Verification) Warnings() []string {
+	var out []string
+	for _, f := range v.Findings {
+		if f.Verdict != VerdictSupported {
+			out = append(out, f.Warning())
+		}
+	}
+	return out
+}
+
+var (
+	percentRe   = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?)\s?(?:%|percent\b)`)
+	monthYearRe = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?,?\s+((?:19|20)\d{2})\b`)
+	numMonthRe  = regexp.MustCompile(`\b(0?[1-9]|1[0-2])/((?:19|20)\d{2})\b`)
+	yearRe      = regexp.MustCompile(`\b(?:19|20)\d{2}\b`)
+	numberRe    = regexp.MustCompile(`\$?\b\d+(?:[.,]\d+)*(?:\s?[kKmMbB]\b)?\+?`)
+)
+
+var monthAbbrevs = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// phraseBreak matches text between two tokens that ends a sentence or a
+// field, so the next capitalized word is not evidence of a proper noun.
+var phraseBreak = regexp.MustCompile(`[.!?:;|•·\-–—(]`)
+
+// VerifyClaims compares a tailored resume against its base resume and
+// returns every number, percentage, date, proper-noun phrase, and technology
+// term that the base does not contain. Matching is case-insensitive; claims
+// contained in one of allow (typically the target company and role) are
+// ignored. Each claim is reported once.
+//
+// The check is deliberately literal: a rephrased but truthful claim, such as
+// a new capitalized word at the start of a bullet, can be flagged. The
+// optional ReviewFindings pass exists to clear those.
+func VerifyClaims(base, tailored string, allow ...string) []Finding {
+	ref := newClaimIndex(base)
+	var allowed []string
+	for _, s := range allow {
+		if s = normalizeSpace(s); s != "" {
+			allowed = append(allowed, s)
+		}
+	}
+
+	var out []Finding
+	seen := make(map[string]bool)
+	add := func(kind, claim, key, line string) {
+		for _, s := range allowed {
+			if strings.Contains(s, normalizeSpace(claim)) {
+				return
+			}
+		}
+		if seen[kind+"\x00"+key] {
+			return
+		}
+		seen[kind+"\x00"+key] = true
+		out = append(out, Finding{Kind: kind, Claim: claim, Line: line})
+	}
+
+	for _, raw := range strings.Split(tailored, "\n") {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+		rest := line
+		for _, d := range findDates(rest) {
+			if !ref.dates[d.key] {
+				add(FindingDate, d.text, d.key, line)
+			}
+			rest = strings.Replace(rest, d.text, " ", 1)
+		}
+		for _, m := range percentRe.FindAllStringSubmatch(rest, -1) {
+			key := m[1] + "%"
+			if !ref.percents[key] {
+				add(FindingPercentage, m[0], key, line)
+			}
+		}
+		rest = percentRe.ReplaceAllString(rest, " ")
+		for _, m := range numberRe.FindAllString(rest, -1) {
+			key := normalizeNumber(m)
+			if !ref.numbers[key] {
+				add(FindingNumber, strings.TrimSpace(m), key, line)
+			}
+		}
+		if isUpperLine(line) {
+			continue // headings and the candidate's name
+		}
+		for _, phrase := range properPhrases(line) {
+			if len(phrase) > 1 {
+				claim := strings.Join(phrase, " ")
+				if !strings.Contains(ref.text, normalizeSpace(claim)) {
+					add(FindingName, claim, normalizeSpace(claim), line)
+				}
+				continue
+			}
+			w := strings.ToLower(phrase[0])
+			if !ref.words[w] && !strings.Contains(ref.text, w) {
+				add(FindingTerm, phrase[0], w, line)
+			}
+		}
+	}
+	return out
+}
+
+// claimIndex holds the normalized claims of a base resume.
+type claimIndex struct {
+	text     string // lowercase, whitespace-collapsed
+	words    map[string]bool
+	numbers  map[string]bool
+	percents map[string]bool
+	dates    map[string]bool // "2021" and "mar 2021" forms
+}
+
+func newClaimIndex(base string) claimIndex {
+	idx := claimIndex{
+		text:     normalizeSpace(base),
+		words:    keywords(base),
+		numbers:  make(map[string]bool),
+		percents: make(map[string]bool),
+		dates:    make(map[string]bool),
+	}
+	for _, m := range percentRe.FindAllStringSubmatch(base, -1) {
+		idx.percents[m[1]+"%"] = true
+	}
+	for _, m := range numberRe.FindAllString(base, -1) {
+		idx.numbers[normalizeNumber(m)] = true
+	}
+	for _, d := range findDates(base) {
+		idx.dates[d.key] = true
+	}
+	for _, y := range yearRe.FindAllString(base, -1) {
+		idx.dates[y] = true
+	}
+	return idx
+}
+
+type dateClaim struct {
+	text string // as written
+	key  string // normalized: "mar 2021" or "2021"
+}
+
+// findDates returns month-year dates ("Mar 2021", "03/2021") and any remaining
+// bare years, in that order.
+func findDates(s string) []dateClaim {
+	var out []dateClaim
+	for _, m := range monthYearRe.FindAllStringSubmatch(s, -1) {
+		out = append(out, dateClaim{m[0], strings.ToLower(m[1]) + " " + m[2]})
+	}
+	s = monthYearRe.ReplaceAllString(s, " ")
+	for _, m := range numMonthRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.Atoi(m[1])
+		out = append(out, dateClaim{m[0], monthAbbrevs[n-1] + " " + m[2]})
+	}
+	s = numMonthRe.ReplaceAllString(s, " ")
+	for _, y := range yearRe.FindAllString(s, -1) {
+		out = append(out, dateClaim{y, y})
+	}
+	return out
+}
+
+// normalizeNumber drops currency symbols, thousands separators, and trailing
+// "+" so "$1,200+" and "1200" compare equal.
+func normalizeNumber(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "$")
+	s = strings.TrimSuffix(s, "+")
+	s = strings.ReplaceAll(s, ",", "")
+	return strings.ReplaceAll(s, " ", "")
+}
+
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// isUpperLine reports whether every letter in s is uppercase.
+func isUpperLine(s string) bool {
+	letters := false
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters = true
+		}
+	}
+	return letters
+}
+
+// properPhrases splits line into runs of proper-noun tokens separated only by
+// spaces. A token qualifies if it looks like a technology (an inner capital,
+// a digit, or one of "+#.", as in "gRPC", "EC2", "C++", "Node.js") or if it is
+// capitalized and does not open a sentence or field, where capitals carry no
+// signal.
+func properPhrases(line string) [][]string {
+	var out [][]string
+	var run []string
+	flush := func() {
+		if len(run) > 0 {
+			out = append(out, run)
+			run = nil
+		}
+	}
+	prev := 0
+	for i, loc := range keywordRe.FindAllStringIndex(line, -1) {
+		tok := line[loc[0]:loc[1]]
+		gap := line[prev:loc[0]]
+		prev = loc[1]
+		start := i == 0 || phraseBreak.MatchString(gap)
+		if strings.TrimSpace(gap) != "" {
+			flush()
+		}
+		switch {
+		case techLike(tok):
+			run = append(run, tok)
+		case capitalized(tok) && !start && !stopWords[strings.ToLower(tok)]:
+			run = append(run, tok)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return out
+}
+
+func capitalized(tok string) bool {
+	r := []rune(tok)
+	return len(r) > 1 && unicode.IsUpper(r[0])
+}
+
+func techLike(tok string) bool {
+	r := []rune(tok)
+	if len(r) < 2 || !unicode.IsLetter(r[0]) {
+		return false
+	}
+	for _, c := range r[1:] {
+		if unicode.IsUpper(c) || unicode.IsDigit(c) || strings.ContainsRune("+#", c) {
+			return true
+		}
+	}
+	return strings.Contains(tok, ".") && !strings.HasSuffix(tok, ".")
+}
+
+const reviewFindingsPrompt = `A tailored resume was checked against the candidate's base resume and the claims below could not be found there literally. For each one, decide whether the base resume supports it (a rewording, a derived figure, or a restatement of a fact it contains) or whether it is unsupported (new employers, titles, dates, metrics, or skills).
+
+Respond using exactly this XML tag, one line per claim, in the given order:
+<verdicts>
+1 | supported or unsupported | short reason
+</verdicts>`
+
+var verdictsTagRe = regexp.MustCompile(`(?s)<verdicts>(.*?)</verdicts>`)
+
+// ReviewFindings asks the LLM for a second opinion on each finding and
+// returns a copy with Verdict and Reason filled in. Findings the response
+// does not cover keep an empty verdict.
+func ReviewFindings(
+	ctx context.Context,
+	invoker LLMInvoker,
+	apiKey string,
+	params TaskParams,
+	c *http.Client,
+	base, tailored string,
+	findings []Finding,
+) ([]Finding, error) {
+	if len(findings) == 0 {
+		return findings, nil
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "BASE RESUME:\n%s\n\nTAILORED RESUME:\n%s\n\nCLAIMS:\n", Sanitize(base), Sanitize(tailored))
+	for i, f := range findings {
+		fmt.Fprintf(&sb, "%d. %s %q in: %s\n", i+1, f.Kind, f.Claim, f.Line)
+	}
+	content, _, _, err := complete(ctx, invoker, nil, apiKey, params, c, []deepseekMessage{
+		{Role: "system", Content: reviewFindingsPrompt},
+		{Role: "user", Content: sb.String()},
+	}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	content, _ = repairTagged(content, []string{"verdicts"})
+
+	out := append([]Finding(nil), findings...)
+	for _, line := range strings.Split(extractTag(verdictsTagRe, content), "\n") {
+		parts := strings.SplitN(strings.TrimSpace(line), "|", 3)
+		if len(parts) < 2 {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimRight(strings.TrimSpace(parts[0]), "."))
+		if err != nil || n < 1 || n > len(out) {
+			continue
+		}
+		switch v := strings.ToLower(strings.TrimSpace(parts[1])); {
+		case strings.HasPrefix(v, "unsupported"), strings.HasPrefix(v, "not"):
+			out[n-1].Verdict = VerdictUnsupported
+		case strings.HasPrefix(v, "supported"):
+			out[n-1].Verdict = VerdictSupported
+		default:
+			continue
+		}
+		if len(parts) == 3 {
+			out[n-1].Reason = strings.TrimSpace(parts[2])
+		}
+	}
+	return out, nil
+}
+
+// GetVerification reads a job's verification.json.
+func GetVerification(a *App, id string) (*Verification, error) {
+	if !ValidID(id) {
+		return nil, fmt.Errorf("invalid job id %q", id)
+	}
+	return LoadJSON[Verification](filepath.Join(a.Paths.Jobs, id, verificationFile))
+}
@@ -0,0 +1,104 @@
+package jdextract
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const verifyBase = `JANE DOE
+jane@example.com | (555) 123-4567
+
+EXPERIENCE
+Content Lead | Brightside Media | Mar 2019 - Present
+• Grew newsletter subscribers by 40% to 12,000 readers
+• Managed a team of 4 writers using Asana
+
+Copywriter | Northwind Agency | 2016 - 2019
+• Wrote B2B campaigns for SaaS clients
+`
+
+func TestVerifyClaims(t *testing.T) {
+	tailored := `JANE DOE
+jane@example.com | (555) 123-4567
+
+EXPERIENCE
+Content Lead | Brightside Media | March 2019 - Present
+• Grew newsletter subscribers by 40% to 12000 readers, driving $2M in pipeline
+• Managed a team of 6 writers using Asana and HubSpot
+• Partnered with Acme Corp on launch content
+
+Senior Copywriter | Northwind Agency | Jan 2015 - 2019
+• Wrote B2B campaigns for SaaS clients in Google Analytics
+`
+	got := VerifyClaims(verifyBase, tailored, "Acme Corp", "Senior Copywriter")
+	var claims []string
+	for _, f := range got {
+		claims = append(claims, f.Kind+":"+f.Claim)
+	}
+	want := []string{
+		"number:$2M",
+		"number:6",
+		"term:HubSpot",
+		"date:Jan 2015",
+		"name:Google Analytics",
+	}
+	if !slices.Equal(claims, want) {
+		t.Errorf("findings = %v, want %v", claims, want)
+	}
+	for _, f := range got {
+		if f.Claim == "6" && !strings.Contains(f.Line, "team of 6") {
+			t.Errorf("finding line = %q", f.Line)
+		}
+	}
+}
+
+func TestVerifyClaimsUnchanged(t *testing.T) {
+	if got := VerifyClaims(verifyBase, verifyBase); len(got) != 0 {
+		t.Errorf("base against itself: %+v", got)
+	}
+}
+
+func TestReviewFindings(t *testing.T) {
+	findings := []Finding{
+		{Kind: FindingNumber, Claim: "6"},
+		{Kind: FindingTerm, Claim: "HubSpot"},
+	}
+	var calls int
+	reply := "<verdicts>\n1 | unsupported | base says 4 writers\n2. | supported | listed under tools\n</verdicts>"
+	got, err := ReviewFindings(context.Background(), fakeInvoker(reply, &calls), "", TaskParams{Model: "m"}, nil, verifyBase, "", findings)
+	if err != nil {
+		t.Fatalf("ReviewFindings: %v", err)
+	}
+	if got[0].Verdict != VerdictUnsupported || got[0].Reason != "base says 4 writers" || got[1].Verdict != VerdictSupported {
+		t.Errorf("reviewed = %+v", got)
+	}
+	v := Verification{Findings: got, Reviewed: true}
+	if w := v.Warnings(); len(w) != 1 || !strings.Contains(w[0], `"6"`) {
+		t.Errorf("warnings = %v", w)
+	}
+	if findings[0].Verdict != "" {
+		t.Error("ReviewFindings modified its input")
+	}
+}
+
+func TestVerifyReturnsReviewTranscript(t *testing.T) {
+	a := newTestApp(t)
+	a.Config.VerifyLLM = true
+	a.Config.Transcripts.Enabled = true
+	gen := &Generation{Resume: strings.Replace(verifyBase, "40%", "75%", 1)}
+	// No recording exists, so the review fails but is still transcribed.
+	v, rec := a.verify(context.Background(), verifyBase, gen, false, func(ProgressEvent) {})
+	if v.Reviewed || len(gen.Repairs) != 1 {
+		t.Errorf("verification = %+v, repairs = %v", v, gen.Repairs)
+	}
+	dir := t.TempDir()
+	if err := rec.Flush(dir); err != nil {
+		t.Fatal(err)
+	}
+	if names, err := transcriptNames(filepath.Join(dir, transcriptDir)); err != nil || len(names) != 1 {
+		t.Errorf("transcripts = %v, %v", names, err)
+	}
+}
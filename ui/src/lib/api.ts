@@ -1,4 +1,4 @@
-import type { Config, PromptConfig, Templates, Job, JobFiles, BatchResult, ProcessResult, ProgressEvent, Contact, Conversation, Message, FollowupResult, NetworkingPromptConfig, SearchResult } from './types';
+import type { Config, PromptConfig, PromptPreview, Templates, Job, JobFiles, BatchResult, ProcessOptions, ProcessResult, ProgressEvent, Contact, Conversation, Message, FollowupResult, NetworkingPromptConfig, SearchResult, TranscriptSummary, FitAnalysis, ConstraintReport, ATSReport, ExperimentReport, CoverBlockOutcome, RevisionSummary, ChatMessage, Answer, BankMatch, JobMessageKind, JobMessageResult, ExportFormat, TemplateImport } from './types';
 
 const BASE = '/api';
 
@@ -25,8 +25,23 @@ export const api = {
   saveConfig: (data: Partial<Config>) => request<null>('PATCH', '/config', data),
   getPromptConfig: () => request<PromptConfig>('GET', '/config/prompt'),
   savePromptConfig: (data: Partial<PromptConfig>) => request<null>('PATCH', '/config/prompt', data),
+  previewPrompt: (job: string, overrides: Partial<PromptConfig> = {}) =>
+    request<PromptPreview>('POST', '/config/prompt/preview', { job, ...overrides }),
   getTemplates: () => request<Templates>('GET', '/templates'),
+  getResumeTemplates: () => request<string[]>('GET', '/templates/resumes'),
+  getPromptProfiles: () => request<string[]>('GET', '/config/prompts'),
+  getExperiments: () => request<ExperimentReport>('GET', '/experiments'),
+  getCoverBlocks: () => request<CoverBlockOutcome[]>('GET', '/cover-blocks'),
   saveTemplates: (data: Partial<Templates>) => request<null>('PATCH', '/templates', data),
+  // Converts an uploaded .docx/.md/.txt to template text without saving it.
+  importTemplate: async (file: File, target: 'resume' | 'cover'): Promise<TemplateImport> => {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('target', target);
+    const res = await fetch(`${BASE}/templates/import`, { method: 'POST', body: form });
+    if (!res.ok) throw new Error((await res.text()) || res.statusText);
+    return res.json();
+  },
   getJobs: () => request<Job[]>('GET', '/jobs'),
   updateJobStatus: (id: string, status: string) => request<null>('PATCH', `/jobs/${id}`, { status }),
   updateJobMeta: (id: string, data: { company?: string; role?: string; date?: string }) =>
@@ -34,13 +49,54 @@ export const api = {
   deleteJob: (id: string) => request<null>('DELETE', `/jobs/${id}`),
   getJobFiles: (id: string) => request<JobFiles>('GET', `/jobs/${id}/files`),
   saveJobFiles: (id: string, data: Partial<JobFiles>) => request<null>('PATCH', `/jobs/${id}/files`, data),
+  reviseJob: (id: string, target: 'resume' | 'cover', instructions: string, onProgress: (event: ProgressEvent) => void) =>
+    consumeSSE(`${BASE}/jobs/${id}/revise`, { target, instructions }, onProgress),
+  getRevisions: (id: string) => request<RevisionSummary[]>('GET', `/jobs/${id}/revisions`),
+  getChat: (id: string) => request<ChatMessage[]>('GET', `/jobs/${id}/chat`),
+  chatJob: (id: string, message: string, onProgress: (event: ProgressEvent) => void) =>
+    consumeSSE(`${BASE}/jobs/${id}/chat`, { message }, onProgress),
+  clearChat: (id: string) => request<null>('DELETE', `/jobs/${id}/chat`),
+  applyChat: (id: string, index: number, target: 'resume' | 'cover') =>
+    request<null>('POST', `/jobs/${id}/chat/${index}/apply`, { target }),
+  prepJob: (id: string, onProgress: (event: ProgressEvent) => void) =>
+    consumeSSE(`${BASE}/jobs/${id}/prep`, {}, onProgress),
+  getPrep: (id: string) => request<{ content: string }>('GET', `/jobs/${id}/prep`),
+  getAnswers: (id: string) => request<Answer[]>('GET', `/jobs/${id}/answers`),
+  answerQuestions: (id: string, questions: string[]) =>
+    request<Answer[]>('POST', `/jobs/${id}/answers`, { questions }),
+  updateAnswer: (id: string, index: number, data: { answer?: string; approved?: boolean }) =>
+    request<Answer>('PATCH', `/jobs/${id}/answers/${index}`, data),
+  searchAnswerBank: (q: string) => request<BankMatch[]>('GET', `/answers?q=${encodeURIComponent(q)}`),
+  jobMessage: (id: string, body: { kind: JobMessageKind; contact?: string; notes?: string }) =>
+    request<JobMessageResult>('POST', `/jobs/${id}/messages`, body),
+  sendJobMessage: (id: string, body: { contact: string; content: string; channel: string; next_followup_date?: string }) =>
+    request<Contact>('POST', `/jobs/${id}/messages/send`, body),
+  exportUrl: (id: string, doc: 'resume' | 'cover', format: ExportFormat) =>
+    `${BASE}/jobs/${id}/export?format=${format}&doc=${doc}`,
+  // Downloads an export and returns its page count (PDF only, else 0).
+  exportDocument: async (id: string, doc: 'resume' | 'cover', format: ExportFormat): Promise<number> => {
+    const res = await fetch(api.exportUrl(id, doc, format));
+    if (!res.ok) throw new Error((await res.text()) || res.statusText);
+    const link = document.createElement('a');
+    link.href = URL.createObjectURL(await res.blob());
+    link.download = `${id}-${doc}.${format}`;
+    link.click();
+    URL.revokeObjectURL(link.href);
+    return Number(res.headers.get('X-Page-Count') ?? 0);
+  },
+  getAnalysis: (id: string) => request<FitAnalysis>('GET', `/jobs/${id}/analysis`),
+  getConstraints: (id: string) => request<ConstraintReport>('GET', `/jobs/${id}/constraints`),
+  getATSCheck: (id: string) => request<ATSReport>('GET', `/jobs/${id}/ats-check`),
+  runATSCheck: (id: string) => request<ATSReport>('POST', `/jobs/${id}/ats-check`),
+  getTranscripts: (id: string) => request<TranscriptSummary[]>('GET', `/jobs/${id}/transcripts`),
   process: (url: string) => request<ProcessResult>('POST', '/process', { url }),
-  processBatch: (urls: string[]) => request<BatchResult[]>('POST', '/process/batch', { urls }),
+  processBatch: (urls: string[], opts: ProcessOptions = {}) =>
+    request<BatchResult[]>('POST', '/process/batch', { urls, ...opts }),
   processLocal: (content: string) => request<ProcessResult>('POST', '/process/local', { content }),
-  processStream: (url: string, onProgress: (event: ProgressEvent) => void) =>
-    consumeSSE(`${BASE}/process/stream`, { url }, onProgress),
-  processLocalStream: (content: string, onProgress: (event: ProgressEvent) => void) =>
-    consumeSSE(`${BASE}/process/local/stream`, { content }, onProgress),
+  processStream: (url: string, onProgress: (event: ProgressEvent) => void, opts: ProcessOptions = {}) =>
+    consumeSSE(`${BASE}/process/stream`, { url, ...opts }, onProgress),
+  processLocalStream: (content: string, onProgress: (event: ProgressEvent) => void, opts: ProcessOptions = {}) =>
+    consumeSSE(`${BASE}/process/local/stream`, { content, ...opts }, onProgress),
 
   // Contacts
   getContacts: () => request<Contact[]>('GET', '/contacts'),
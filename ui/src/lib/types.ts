@@ -1,3 +1,140 @@
+export interface RateLimitConfig {
+  max_in_flight?: number;
+  requests_per_minute?: number;
+  tokens_per_minute?: number;
+}
+
+export interface TranscriptConfig {
+  enabled: boolean;
+  retention_days?: number;
+  max_files?: number;
+}
+
+export type RequirementStatus = 'met' | 'partial' | 'missing';
+
+export interface Requirement {
+  requirement: string;
+  status: RequirementStatus;
+  evidence?: string;
+}
+
+export interface FitAnalysis {
+  score: number;
+  sub_scores: {
+    skills: number;
+    experience: number;
+    domain: number;
+    location: number;
+    compensation: number;
+  };
+  requirements: Requirement[];
+}
+
+/** Length limits for tailored resumes; zero or unset is not checked. */
+export interface ResumeConstraints {
+  max_lines?: number;
+  max_bullet_chars?: number;
+  max_bullets_per_role?: number;
+  target_pages?: number;
+}
+
+export interface Violation {
+  rule: 'max_lines' | 'max_bullet_chars' | 'max_bullets_per_role' | 'target_pages';
+  limit: number;
+  actual: number;
+  /** The bullet or role line, for per-item rules. */
+  text?: string;
+}
+
+/** A job's constraints.json: the check before and after shortening. */
+export interface ConstraintReport {
+  constraints: ResumeConstraints;
+  initial: Violation[] | null;
+  violations: Violation[] | null;
+  passes: number;
+  /** Profile entry IDs dropped to fit, for resumes assembled from the profile. */
+  dropped?: string[];
+  tokens?: number;
+  lines: number;
+  pages?: number;
+  error?: string;
+}
+
+export interface ATSKeyword {
+  term: string;
+  preferred?: boolean;
+  found: boolean;
+}
+
+export interface ATSIssue {
+  kind: 'missing_section' | 'character' | 'table' | 'contact' | 'date_format';
+  message: string;
+  line?: string;
+}
+
+/** A job's ats.json: the deterministic ATS readiness check of the resume. */
+export interface ATSReport {
+  coverage: number;
+  required_coverage: number;
+  preferred_coverage: number;
+  keywords: ATSKeyword[];
+  issues: ATSIssue[];
+}
+
+export interface ChatMessage {
+  role: 'user' | 'assistant';
+  content: string;
+  time: string;
+  blocks?: ('resume' | 'cover')[];
+  applied?: ('resume' | 'cover')[];
+}
+
+export interface RevisionSummary {
+  name: string;
+  target: 'resume' | 'cover';
+  time: string;
+}
+
+export interface Answer {
+  question: string;
+  answer: string;
+  approved: boolean;
+  /** Bank question whose approved answer was the starting point. */
+  from_bank?: string;
+}
+
+export interface BankEntry {
+  question: string;
+  answer: string;
+  job: string;
+  company?: string;
+  role?: string;
+  date: string;
+}
+
+export interface BankMatch extends BankEntry {
+  score: number;
+}
+
+export interface TranscriptSummary {
+  name: string;
+  time: string;
+  model: string;
+  stream: boolean;
+  latency_ms: number;
+  tokens?: number;
+  error?: string;
+}
+
+export interface TaskParams {
+  backend?: 'deepseek' | 'kimi';
+  model?: string;
+  temperature?: number;
+  top_p?: number;
+  max_tokens?: number;
+  stop?: string[];
+}
+
 export interface Config {
   deepseek_api_key: string;
   deepseek_model: string;
@@ -5,11 +142,93 @@ export interface Config {
   kimi_model: string;
   backend: string;
   port: number;
+  deepseek_limits?: RateLimitConfig;
+  kimi_limits?: RateLimitConfig;
+  transcripts?: TranscriptConfig;
+  llm_mode?: '' | 'record' | 'replay';
+  recordings_dir?: string;
+  save_reasoning?: boolean;
+  tasks?: Record<string, TaskParams>;
+  pipeline?: boolean;
+  verify_llm?: boolean;
+  pdf_layout?: PDFLayout;
+  constraints?: ResumeConstraints;
+  experiment?: ExperimentConfig;
+  /** Output policy per posting language code, e.g. "de". */
+  languages?: Record<string, LanguageConfig>;
+  /** Default cover letter tone preset; empty keeps the base letter's tone. */
+  cover_tone?: CoverTone | '';
+}
+
+export const LANGUAGES: Record<string, string> = {
+  en: 'English',
+  de: 'German',
+  fr: 'French',
+  es: 'Spanish',
+  it: 'Italian',
+  nl: 'Dutch',
+  pt: 'Portuguese',
+};
+
+export const COVER_TONES = ['formal', 'startup-casual', 'concise'] as const;
+export type CoverTone = (typeof COVER_TONES)[number];
+
+/** Interview outcomes of the jobs whose cover letters used a block. */
+export interface CoverBlockOutcome {
+  id: string;
+  jobs: number;
+  interviewing: number;
+  rate: number;
+  /** Recorded in jobs but no longer in config/templates/cover_blocks/. */
+  removed?: boolean;
+}
+
+export interface LanguageConfig {
+  /** "english" (default) keeps English output; "translate" writes in the posting's language. */
+  mode?: '' | 'english' | 'translate';
+  /** Base template variant already written in this language. */
+  template?: string;
 }
 
+export interface ExperimentConfig {
+  mode?: '' | 'random' | 'round-robin';
+  /** Profiles to assign from; empty means "default" plus every named profile. */
+  profiles?: string[];
+  cost_per_million_tokens?: number;
+}
+
+export interface ProfileOutcome {
+  profile: string;
+  jobs: number;
+  interviewing: number;
+  rate: number;
+  /** Jobs per fit score; index 0 counts unscored jobs. */
+  scores: number[];
+  mean_score: number;
+  tokens: number;
+  token_jobs: number;
+  mean_tokens: number;
+  cost?: number;
+}
+
+export interface ExperimentReport {
+  mode: string;
+  profiles: ProfileOutcome[];
+  unassigned: number;
+}
+
+/** Each field is a Go text/template; see PromptData in prompts.go for variables. */
 export interface PromptConfig {
   system_prompt: string;
   task_list: string;
+  /** User message template; empty sends the default layout. */
+  user?: string;
+  profile?: string;
+}
+
+export interface PromptPreview {
+  system: string;
+  user: string;
 }
 
 export interface Templates {
@@ -25,6 +244,23 @@ export interface Job {
   status: string;
   tokens: number;
   date: string;
+  provenance?: string[];
+  template?: string;
+  /** Profile entry IDs selected when template is "profile". */
+  profile_entries?: string[];
+  /** Prompt profile used for generation; "default" is prompt.json. */
+  prompt_profile?: string;
+  /** Set once the status has been interviewing or offer. */
+  interviewed?: boolean;
+  /** ISO 639-1 code the documents were written in. */
+  language?: string;
+  /** Cover block IDs the cover letter was built from, and its tone preset. */
+  cover_blocks?: string[];
+  cover_tone?: CoverTone;
+  /** Keyword coverage percentage of the last ATS check. */
+  ats_coverage?: number;
+  /** Resume claims not found in the base template (see verification.json). */
+  warnings?: string[];
 }
 
 export interface JobFiles {
@@ -42,11 +278,26 @@ export interface ProcessResult {
   dir: string;
 }
 
+/** Per-request overrides for the process endpoints; unset fields use config. */
+export interface ProcessOptions {
+  pipeline?: boolean;
+  /** Variant name from config/templates/resumes/, "default", "profile", or "auto". */
+  template?: string;
+  /** Prompt profile name from config/prompts/ or "default"; unset lets the experiment choose. */
+  prompt_profile?: string;
+  /** Posting language code; unset detects it from the job description. */
+  language?: string;
+  /** Cover letter tone preset; unset uses cover_tone from config. */
+  tone?: CoverTone;
+}
+
 export interface ProgressEvent {
   stage: string;
   message?: string;
   dir?: string;
   delta?: string;
+  queue?: number;
+  warnings?: string[];
 }
 
 export type JobStatus = 'draft' | 'applied' | 'interviewing' | 'offer' | 'rejected';
@@ -94,9 +345,28 @@ export interface FollowupResult {
   suggested_next_date?: string;
 }
 
+export interface TemplateImport {
+  text: string;
+  current: string;
+  path: string;
+  saved: boolean;
+}
+
+export type ExportFormat = 'docx' | 'pdf';
+export type PDFLayout = '' | 'a4' | 'classic' | 'compact' | 'modern';
+
+export const JOB_MESSAGE_KINDS = ['thank-you', 'decline', 'accept', 'ask-for-feedback', 'withdraw'] as const;
+export type JobMessageKind = (typeof JOB_MESSAGE_KINDS)[number];
+
+export interface JobMessageResult extends FollowupResult {
+  contact?: string; // recipient contact dir; absent when addressed to the hiring team
+}
+
 export interface NetworkingPromptConfig {
   system_prompt: string;
   task_list: string;
+  user?: string;
+  profile?: string;
 }
 
 export type ContactStatus = 'new' | 'reached-out' | 'replied' | 'meeting-scheduled' | 'connected' | 'dormant';